@@ -0,0 +1,71 @@
+// OSOVM Compile CLI
+// oso compile|disasm - pre-compiles rituals to bytecode and prints programs
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ase-lang/osovm/pkg/vm"
+)
+
+const compileUsage = `Usage: oso compile <ritual.oso> [--ritual NAME] [--out FILE]
+       oso disasm <ritual.oso|program.osob> [--ritual NAME]
+
+compile writes the ritual's bytecode (default: <ritual>` + vm.ProgramExt + `) and prints its hash.
+disasm prints the bytecode of a ritual or an encoded program.`
+
+func runCompileCommand(command string, args []string) error {
+	fs := flag.NewFlagSet("oso "+command, flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), compileUsage) }
+	ritualFlag := fs.String("ritual", "", "ritual to compile (default: the file name)")
+	out := fs.String("out", "", "where compile writes the program")
+
+	// Flags may come before or after the ritual path
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%s", compileUsage)
+	}
+	path := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	machine := vm.NewVM()
+	if err := machine.LoadRitual(path); err != nil {
+		return fmt.Errorf("Error loading ritual:\n%w", err)
+	}
+	name := *ritualFlag
+	if name == "" {
+		name = vm.RitualName(path)
+	}
+	ritual, ok := machine.Rituals[name]
+	if !ok {
+		return fmt.Errorf("ritual '%s' not found", name)
+	}
+	program := ritual.Program
+
+	if command == "disasm" {
+		fmt.Print(vm.Disassemble(program))
+		return nil
+	}
+
+	if *out == "" {
+		*out = strings.TrimSuffix(path, ".oso") + vm.ProgramExt
+	}
+	encoded := program.Encode()
+	if err := os.WriteFile(*out, encoded, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("📦 Compiled %s → %s (%d instructions, %d bytes)\n", name, *out, len(program.Code), len(encoded))
+	fmt.Printf("🔑 Program hash: %s\n", program.Hash())
+	return nil
+}
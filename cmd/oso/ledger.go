@@ -0,0 +1,134 @@
+// OSOVM Ledger CLI
+// oso ledger deposit|balance|journal|holds|release|streams - funds accounts
+// and inspects the ledger eshu_router, sango_vault and oshun_river move
+// value through
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/ledger"
+)
+
+const defaultLedger = "ledger.json"
+
+const ledgerUsage = `Usage: oso ledger <command> [flags]
+
+Commands:
+  deposit  --account NAME --amount N [--memo TEXT]  credit an account from equity
+  balance  [--account NAME]                         show account balances and what is available
+  journal                                           show every journal entry
+  holds    [--account NAME]                         show open holds
+  release  --hold ID                                free a hold
+  streams  [--account NAME]                         show open streams and what they owe now
+
+All commands accept --ledger FILE (default: $OSO_LEDGER or ` + defaultLedger + `)`
+
+// ledgerPath returns the ledger path used when --ledger is not given
+func ledgerPath() string {
+	if path := os.Getenv("OSO_LEDGER"); path != "" {
+		return path
+	}
+	return defaultLedger
+}
+
+func runLedgerCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", ledgerUsage)
+	}
+
+	fs := flag.NewFlagSet("oso ledger "+args[0], flag.ContinueOnError)
+	path := fs.String("ledger", ledgerPath(), "ledger file")
+	account := fs.String("account", "", "account name")
+	amount := fs.Int64("amount", 0, "amount to deposit")
+	memo := fs.String("memo", "", "note recorded with the deposit")
+	hold := fs.Uint64("hold", 0, "hold to release")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	l, err := ledger.Open(*path)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "deposit":
+		if *account == "" {
+			return fmt.Errorf("deposit requires --account")
+		}
+		if *account == ledger.Equity {
+			return fmt.Errorf("cannot deposit into %s", ledger.Equity)
+		}
+		entry, err := l.Deposit(*account, *amount, *memo)
+		if err != nil {
+			return err
+		}
+		if err := l.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("💰 Deposited %d into %s (entry #%d)\n", *amount, *account, entry.Seq)
+		fmt.Printf("   balance: %d\n", l.Balance(*account))
+
+	case "balance":
+		accounts := l.Accounts()
+		if *account != "" {
+			accounts = []string{*account}
+		}
+		fmt.Printf("%-24s %12s %12s\n", "ACCOUNT", "BALANCE", "AVAILABLE")
+		for _, name := range accounts {
+			fmt.Printf("%-24s %12d %12d\n", name, l.Balance(name), l.Available(name))
+		}
+
+	case "journal":
+		for _, e := range l.Journal() {
+			ritual := e.Ritual
+			if ritual == "" {
+				ritual = "-"
+			}
+			fmt.Printf("#%-4d %-24s %s\n", e.Seq, ritual, time.Unix(e.Timestamp, 0).UTC().Format(time.RFC3339))
+			for _, p := range e.Postings {
+				fmt.Printf("      %-20s → %-20s %10d  %s\n", p.From, p.To, p.Amount, p.Memo)
+			}
+		}
+
+	case "holds":
+		for _, h := range l.Holds() {
+			if *account != "" && h.Account != *account {
+				continue
+			}
+			fmt.Printf("#%-4d %-24s %10d  %s\n", h.ID, h.Account, h.Amount, h.Memo)
+		}
+
+	case "release":
+		if *hold == 0 {
+			return fmt.Errorf("release requires --hold")
+		}
+		h, err := l.Release(*hold)
+		if err != nil {
+			return err
+		}
+		if err := l.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("🔓 Released hold #%d: %d in %s\n", h.ID, h.Amount, h.Account)
+
+	case "streams":
+		now := l.Now().Unix()
+		for _, s := range l.Streams() {
+			if *account != "" && s.From != *account && s.To != *account {
+				continue
+			}
+			fmt.Printf("#%-4d %-20s → %-20s %10d/%-10d due %-10d ends %s\n", s.ID, s.From, s.To, s.Paid, s.Amount,
+				s.Due(now), time.Unix(s.Start+s.Duration, 0).UTC().Format(time.RFC3339))
+		}
+
+	default:
+		return fmt.Errorf("unknown ledger command: %s\n%s", args[0], ledgerUsage)
+	}
+	return nil
+}
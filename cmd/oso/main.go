@@ -0,0 +1,75 @@
+// OSOVM CLI
+// oso run|compile|disasm|witness|receipt|ledger - command-line front end for the pkg/vm runtime
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ase-lang/osovm/pkg/vm"
+)
+
+func main() {
+	// Handle Termux-specific behavior where Args[1] is the full binary path
+	argsOffset := 1
+	if len(os.Args) > 1 && os.Args[1][0] == '/' {
+		argsOffset = 2
+	}
+
+	if len(os.Args) < argsOffset+2 {
+		fmt.Println("Usage: oso run <ritual.oso> [--proof FILE --witnesses FILE | --demo] [flags]")
+		fmt.Println("       oso compile <ritual.oso> [--out FILE]")
+		fmt.Println("       oso disasm <ritual.oso|program.osob>")
+		fmt.Println("       oso witness <keygen|enroll|rotate|revoke|list|attest> [flags]")
+		fmt.Println("       oso receipt <keygen|verify|show|head|prove|consistency|audit> [flags]")
+		fmt.Println("       oso ledger <deposit|balance|journal|holds|release|streams> [flags]")
+		os.Exit(1)
+	}
+
+	command := os.Args[argsOffset]
+
+	if command == "witness" {
+		if err := runWitnessCommand(os.Args[argsOffset+1:]); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if command == "receipt" {
+		if err := runReceiptCommand(os.Args[argsOffset+1:]); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if command == "ledger" {
+		if err := runLedgerCommand(os.Args[argsOffset+1:]); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := vm.CheckAttributeCanon(); err != nil {
+		fmt.Printf("❌ Attribute registry self-check failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	var err error
+	switch command {
+	case "run":
+		err = runRitualCommand(os.Args[argsOffset+1:])
+	case "compile", "disasm":
+		err = runCompileCommand(command, os.Args[argsOffset+1:])
+	default:
+		fmt.Printf("Unknown command: %s\n", command)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,233 @@
+// OSOVM Receipt CLI
+// oso receipt keygen|verify|show|head|prove|consistency|audit - operator keys,
+// the execution receipt log and its Merkle proofs
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/receipt"
+)
+
+const defaultReceiptLog = "receipts.jsonl"
+
+const receiptUsage = `Usage: oso receipt <command> [flags]
+
+Commands:
+  keygen       --out FILE                        create an operator signing key
+  verify       [--operator HEX | --key FILE]     check every hash, signature and link
+  show         [--seq N | --hash HEX]            print receipts as JSON
+  head         --key FILE [--size N]             print a signed tree head
+  prove        --key FILE (--seq N | --hash HEX) [--size N]
+                                                 print a receipt with its inclusion proof
+  consistency  --key FILE --old FILE [--size N]  prove the log extends an earlier tree head
+  audit        --bundle FILE [--operator HEX]    check a proof from prove or consistency
+                                                 without the log
+
+All commands except keygen and audit accept --log FILE (default: $OSO_RECEIPT_LOG or ` + defaultReceiptLog + `).
+--hash matches a receipt hash or an execution receipt.`
+
+// receiptLogPath returns the log path used when --log is not given
+func receiptLogPath() string {
+	if path := os.Getenv("OSO_RECEIPT_LOG"); path != "" {
+		return path
+	}
+	return defaultReceiptLog
+}
+
+func runReceiptCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", receiptUsage)
+	}
+
+	fs := flag.NewFlagSet("oso receipt "+args[0], flag.ContinueOnError)
+	logPath := fs.String("log", receiptLogPath(), "receipt log file")
+	out := fs.String("out", "", "where keygen writes the operator key")
+	keyPath := fs.String("key", "", "operator key file")
+	operator := fs.String("operator", "", "trusted operator public key (hex)")
+	seq := fs.Uint64("seq", 0, "receipt sequence number")
+	hash := fs.String("hash", "", "receipt hash or execution receipt")
+	size := fs.Uint64("size", 0, "tree size (default: the whole log)")
+	oldPath := fs.String("old", "", "earlier tree head JSON file")
+	bundlePath := fs.String("bundle", "", "proof JSON file")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if args[0] == "keygen" {
+		if *out == "" {
+			return fmt.Errorf("keygen requires --out")
+		}
+		op, err := receipt.GenerateOperator()
+		if err != nil {
+			return err
+		}
+		if err := op.Save(*out); err != nil {
+			return err
+		}
+		fmt.Printf("🔑 Operator key written to %s\n", *out)
+		fmt.Printf("   public key: %s\n", op.PublicKey)
+		return nil
+	}
+
+	if args[0] == "audit" {
+		if *bundlePath == "" {
+			return fmt.Errorf("audit requires --bundle")
+		}
+		return audit(*bundlePath, *operator)
+	}
+
+	if _, err := os.Stat(*logPath); err != nil {
+		return fmt.Errorf("failed to open receipt log: %w", err)
+	}
+	// Opening the log verifies every hash, signature and link
+	log, err := receipt.OpenFileLog(*logPath)
+	if err != nil {
+		return err
+	}
+	receipts := log.Receipts()
+
+	switch args[0] {
+	case "verify":
+		if *keyPath != "" {
+			op, err := receipt.LoadOperator(*keyPath)
+			if err != nil {
+				return err
+			}
+			*operator = op.PublicKey
+		}
+		if err := receipt.VerifyChain(receipts, *operator); err != nil {
+			return err
+		}
+		last, head := log.Head()
+		fmt.Printf("✅ %d receipt(s) verified, head #%d %s\n", len(receipts), last, head)
+		for _, r := range receipts {
+			fmt.Printf("   #%-4d %-24s %s  %s\n", r.Seq, r.Ritual,
+				time.Unix(r.Timestamp, 0).UTC().Format(time.RFC3339), r.Hash[:16]+"...")
+		}
+
+	case "show":
+		if *seq != 0 || *hash != "" {
+			r, err := findReceipt(log, *seq, *hash)
+			if err != nil {
+				return err
+			}
+			receipts = []*receipt.Receipt{r}
+		}
+		for _, r := range receipts {
+			if err := printJSON(r); err != nil {
+				return err
+			}
+		}
+
+	case "head", "prove", "consistency":
+		if *keyPath == "" {
+			return fmt.Errorf("%s requires --key to sign the tree head", args[0])
+		}
+		op, err := receipt.LoadOperator(*keyPath)
+		if err != nil {
+			return err
+		}
+		if *size == 0 {
+			*size, _ = log.Head()
+		}
+		head, err := receipt.SignTreeHead(log, *size, op, time.Now().Unix())
+		if err != nil {
+			return err
+		}
+		switch args[0] {
+		case "head":
+			return printJSON(head)
+		case "prove":
+			r, err := findReceipt(log, *seq, *hash)
+			if err != nil {
+				return err
+			}
+			proof, err := receipt.ProveInclusion(log, r.Seq, head)
+			if err != nil {
+				return err
+			}
+			return printJSON(proof)
+		default:
+			if *oldPath == "" {
+				return fmt.Errorf("consistency requires --old")
+			}
+			var old receipt.TreeHead
+			if err := decodeJSONFile(*oldPath, &old); err != nil {
+				return err
+			}
+			proof, err := receipt.ProveConsistency(log, &old, head)
+			if err != nil {
+				return err
+			}
+			return printJSON(proof)
+		}
+
+	default:
+		return fmt.Errorf("unknown receipt command: %s\n%s", args[0], receiptUsage)
+	}
+	return nil
+}
+
+// findReceipt looks a receipt up by sequence number or hash
+func findReceipt(log receipt.Log, seq uint64, hash string) (*receipt.Receipt, error) {
+	switch {
+	case seq != 0 && hash != "":
+		return nil, fmt.Errorf("--seq and --hash are mutually exclusive")
+	case seq != 0:
+		return log.Get(seq)
+	case hash != "":
+		return log.Find(hash)
+	}
+	return nil, fmt.Errorf("--seq or --hash is required")
+}
+
+// audit checks a proof bundle on its own: the signatures in it, and that
+// its Merkle path leads to the signed root
+func audit(path, operator string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+
+	if _, ok := fields["receipt"]; ok {
+		var proof receipt.Inclusion
+		if err := decodeStrict(data, &proof); err != nil {
+			return fmt.Errorf("invalid inclusion proof in %s: %w", path, err)
+		}
+		if err := proof.Verify(operator); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Receipt #%d (%s) is in tree head %d (root %s...)\n",
+			proof.Receipt.Seq, proof.Receipt.Ritual, proof.Head.Size, proof.Head.Root[:16])
+		return nil
+	}
+
+	var proof receipt.Consistency
+	if err := decodeStrict(data, &proof); err != nil {
+		return fmt.Errorf("invalid consistency proof in %s: %w", path, err)
+	}
+	if err := proof.Verify(operator); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Tree head %d extends tree head %d\n", proof.New.Size, proof.Old.Size)
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,393 @@
+// OSOVM Run CLI
+// oso run <ritual.oso> - feeds a proof and its witnesses into vm.Execute
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/cache"
+	"github.com/ase-lang/osovm/pkg/camera"
+	"github.com/ase-lang/osovm/pkg/ledger"
+	"github.com/ase-lang/osovm/pkg/receipt"
+	"github.com/ase-lang/osovm/pkg/replay"
+	"github.com/ase-lang/osovm/pkg/vm"
+	"github.com/ase-lang/osovm/pkg/witness"
+)
+
+const runUsage = `Usage: oso run <ritual.oso> [flags]
+
+Inputs (one of):
+  --proof FILE --witnesses FILE   proof object and witness array as JSON files
+  (stdin)                         {"proof": {...}, "witnesses": [...]} envelope
+  --demo                          mock proof (or QR scan) and in-process witnesses,
+                                  with in-memory receipts, receipt log, ledger and cache
+
+Flags:
+  --ritual NAME         ritual to execute (default: the file name)
+  --registry FILE       witness registry (default: $OSO_WITNESS_REGISTRY or ` + defaultWitnessRegistry + `)
+  --receipts FILE       replay store for accepted receipts (default: $OSO_RECEIPT_STORE)
+  --distinct-types      count at most one witness per device type
+  --distinct-networks   count at most one witness per network
+  --require-payload     reject proofs that carry no payload to recompute the receipt from
+  --gas-limit N         cap on gas per execution; @gas_limit may only lower it (default 1000000)
+  --events FORMAT       execution log on stdout: console (default), jsonl or none
+  --operator-key FILE   key that signs execution receipts (default: $OSO_OPERATOR_KEY; none: a throwaway key)
+  --receipt-log FILE    hash chain receipts are appended to (default: $OSO_RECEIPT_LOG; none: in memory)
+  --ledger FILE         accounts eshu_router moves value between (default: $OSO_LEDGER; none: in memory)
+  --routes FILE         JSON array of conditional tithe routing rules
+  --governance FILE     dissenting witnesses and vetoes obatala_guard weighs
+  --cache FILE          state yemoja_cache keeps between runs (default: $OSO_CACHE; none: in memory)
+  --herbs FILE          JSON array of herbs osanyin_herb prescribes (default: the built-in registry)
+  --oracle FILE         JSON object of answers orunmila_oracle looks queries up in`
+
+// Envelope - Execution inputs read from stdin
+type Envelope struct {
+	Proof     *vm.Proof    `json:"proof"`
+	Witnesses []vm.Witness `json:"witnesses"`
+}
+
+func runRitualCommand(args []string) error {
+	fs := flag.NewFlagSet("oso run", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), runUsage) }
+	proofPath := fs.String("proof", "", "proof JSON file")
+	witnessesPath := fs.String("witnesses", "", "witnesses JSON file")
+	demo := fs.Bool("demo", false, "run with a mock proof and witnesses")
+	ritualFlag := fs.String("ritual", "", "ritual to execute")
+	registryPath := fs.String("registry", witnessRegistryPath(), "witness registry file")
+	receiptsPath := fs.String("receipts", os.Getenv("OSO_RECEIPT_STORE"), "receipt replay store")
+	distinctTypes := fs.Bool("distinct-types", false, "one counted witness per device type")
+	distinctNetworks := fs.Bool("distinct-networks", false, "one counted witness per network")
+	requirePayload := fs.Bool("require-payload", false, "reject proofs without a payload")
+	gasLimit := fs.Int64("gas-limit", vm.DefaultGasLimit, "gas cap per execution")
+	events := fs.String("events", "console", "execution log format: console, jsonl or none")
+	operatorKey := fs.String("operator-key", os.Getenv("OSO_OPERATOR_KEY"), "operator signing key")
+	receiptLog := fs.String("receipt-log", os.Getenv("OSO_RECEIPT_LOG"), "execution receipt log")
+	ledgerPath := fs.String("ledger", os.Getenv("OSO_LEDGER"), "ledger file")
+	routesPath := fs.String("routes", "", "tithe routing rules JSON file")
+	governancePath := fs.String("governance", "", "governance state JSON file")
+	cachePath := fs.String("cache", os.Getenv("OSO_CACHE"), "cache file")
+	herbsPath := fs.String("herbs", "", "herb registry JSON file")
+	oraclePath := fs.String("oracle", "", "oracle answers JSON file")
+
+	// Flags may come before or after the ritual path
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%s", runUsage)
+	}
+	ritualPath := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	if *demo && (*proofPath != "" || *witnessesPath != "") {
+		return fmt.Errorf("--demo cannot be combined with --proof or --witnesses")
+	}
+	if *witnessesPath != "" && *proofPath == "" {
+		return fmt.Errorf("--witnesses requires --proof")
+	}
+	if *demo {
+		// Mock proofs must never reach a real ledger, cache or receipt
+		// chain: stores named on the command line are an error, and those
+		// from the environment are left alone
+		var stores []string
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "ledger", "receipts", "receipt-log", "cache":
+				stores = append(stores, "--"+f.Name)
+			}
+		})
+		if len(stores) > 0 {
+			return fmt.Errorf("--demo cannot be combined with %s: demo runs use in-memory stores", strings.Join(stores, ", "))
+		}
+		*receiptsPath, *receiptLog, *ledgerPath, *cachePath = "", "", "", ""
+	}
+	var jsonl *vm.JSONLinesSink
+	var sinks []vm.EventSink
+	switch *events {
+	case "console":
+		sinks = append(sinks, vm.NewConsoleSink(os.Stdout))
+	case "jsonl":
+		jsonl = vm.NewJSONLinesSink(os.Stdout)
+		sinks = append(sinks, jsonl)
+	case "none":
+	default:
+		return fmt.Errorf("unknown --events format %q (want console, jsonl or none)", *events)
+	}
+	console := *events == "console"
+
+	machine := vm.NewVM()
+	machine.Quorum = vm.QuorumPolicy{DistinctDeviceTypes: *distinctTypes, DistinctNetworks: *distinctNetworks}
+	machine.RequirePayload = *requirePayload
+	machine.GasLimit = *gasLimit
+	machine.ResolvePayload = readPayloadFile
+	machine.Sinks = sinks
+
+	// Remember accepted proof receipts across runs
+	if *receiptsPath != "" {
+		store, err := replay.OpenFileStore(*receiptsPath)
+		if err != nil {
+			return fmt.Errorf("Error opening receipt store: %w", err)
+		}
+		machine.Receipts = store
+	}
+
+	// Seal execution receipts with the operator's key onto a persistent chain
+	if *operatorKey != "" {
+		op, err := receipt.LoadOperator(*operatorKey)
+		if err != nil {
+			return err
+		}
+		machine.Operator = op
+	}
+	if *receiptLog != "" {
+		log, err := receipt.OpenFileLog(*receiptLog)
+		if err != nil {
+			return fmt.Errorf("Error opening receipt log: %w", err)
+		}
+		machine.ReceiptLog = log
+	}
+
+	// Route transfers through a persistent ledger, under the operator's rules
+	if *ledgerPath != "" {
+		l, err := ledger.Open(*ledgerPath)
+		if err != nil {
+			return err
+		}
+		machine.Ledger = l
+	}
+	if *routesPath != "" {
+		if err := decodeJSONFile(*routesPath, &machine.RouteRules); err != nil {
+			return err
+		}
+		for i := range machine.RouteRules {
+			if err := machine.RouteRules[i].Check(); err != nil {
+				return fmt.Errorf("%s: %w", *routesPath, err)
+			}
+		}
+	}
+	if *governancePath != "" {
+		if err := decodeJSONFile(*governancePath, &machine.Governance); err != nil {
+			return err
+		}
+	}
+	if *cachePath != "" {
+		c, err := cache.Open(*cachePath)
+		if err != nil {
+			return err
+		}
+		machine.Cache = c
+	}
+	if *herbsPath != "" {
+		var herbs []vm.Herb
+		if err := decodeJSONFile(*herbsPath, &herbs); err != nil {
+			return err
+		}
+		registry, err := vm.NewHerbRegistry(herbs...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *herbsPath, err)
+		}
+		machine.Herbs = registry
+	}
+	if *oraclePath != "" {
+		var table vm.OracleTable
+		if err := decodeJSONFile(*oraclePath, &table); err != nil {
+			return err
+		}
+		machine.Oracle = table
+	}
+
+	// Demo witnesses are enrolled in the in-memory registry; real ones
+	// must already be in the persistent registry
+	if !*demo {
+		registry, err := witness.OpenRegistry(*registryPath)
+		if err != nil {
+			return err
+		}
+		machine.Witnesses = registry
+	}
+
+	if err := machine.LoadRitual(ritualPath); err != nil {
+		return fmt.Errorf("Error loading ritual:\n%w", err)
+	}
+
+	ritualName := *ritualFlag
+	if ritualName == "" {
+		ritualName = vm.RitualName(ritualPath)
+		// A renamed file still runs its only ritual, e.g. a shipped program
+		if _, ok := machine.Rituals[ritualName]; !ok && len(machine.Rituals) == 1 {
+			for only := range machine.Rituals {
+				ritualName = only
+			}
+		}
+	}
+
+	var proof *vm.Proof
+	var witnesses []vm.Witness
+	var err error
+	switch {
+	case *demo:
+		if console {
+			fmt.Println("🎭 Demo mode: mock proof and witnesses")
+		}
+		proof, witnesses, err = demoInputs(machine, ritualName)
+	case *proofPath != "":
+		proof, witnesses, err = readInputFiles(*proofPath, *witnessesPath)
+	case stdinPiped():
+		proof, witnesses, err = readEnvelope(os.Stdin)
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := machine.Execute(ritualName, proof, witnesses)
+	if jsonl != nil && jsonl.Err() != nil {
+		return fmt.Errorf("failed to write events: %w", jsonl.Err())
+	}
+	if err != nil {
+		return fmt.Errorf("Execution failed: %w", err)
+	}
+	// Only a completed execution's postings and cache writes reach disk
+	if *ledgerPath != "" {
+		if err := machine.Ledger.Save(); err != nil {
+			return err
+		}
+	}
+	if *cachePath != "" {
+		if err := machine.Cache.Save(); err != nil {
+			return err
+		}
+	}
+	if console && result.Return != nil {
+		out, err := json.Marshal(result.Return)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("↩️  Result: %s\n", out)
+	}
+	return nil
+}
+
+// ========== Input Decoding ==========
+
+// readInputFiles decodes a proof file and an optional witnesses file
+func readInputFiles(proofPath, witnessesPath string) (*vm.Proof, []vm.Witness, error) {
+	var proof vm.Proof
+	if err := decodeJSONFile(proofPath, &proof); err != nil {
+		return nil, nil, err
+	}
+	var witnesses []vm.Witness
+	if witnessesPath != "" {
+		if err := decodeJSONFile(witnessesPath, &witnesses); err != nil {
+			return nil, nil, err
+		}
+	}
+	return &proof, witnesses, nil
+}
+
+// readEnvelope decodes a proof/witnesses envelope
+func readEnvelope(r io.Reader) (*vm.Proof, []vm.Witness, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read envelope: %w", err)
+	}
+	var env Envelope
+	if err := decodeStrict(data, &env); err != nil {
+		return nil, nil, fmt.Errorf("invalid envelope on stdin: %w", err)
+	}
+	if env.Proof == nil {
+		return nil, nil, fmt.Errorf("invalid envelope on stdin: missing \"proof\"")
+	}
+	return env.Proof, env.Witnesses, nil
+}
+
+func decodeJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := decodeStrict(data, v); err != nil {
+		return fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	return nil
+}
+
+// decodeStrict rejects unknown fields and trailing data
+func decodeStrict(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	// More misses a stray closing bracket; only the end of input will do
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// readPayloadFile resolves a Proof.PayloadRef naming a local file, either
+// as a plain path or a file:// URI
+func readPayloadFile(ref string) ([]byte, error) {
+	path := ref
+	if i := strings.Index(ref, "://"); i >= 0 {
+		if ref[:i] != "file" {
+			return nil, fmt.Errorf("unsupported payload reference scheme %q", ref[:i])
+		}
+		path = ref[i+3:]
+	}
+	return os.ReadFile(path)
+}
+
+// stdinPiped reports whether stdin is a pipe or file rather than a terminal
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// ========== Demo Inputs ==========
+
+// demoInputs gives the VM a mock camera and an in-process LoRa witness mesh.
+// QR rituals capture their own proof; others get a mock proof of the type
+// their àṣẹ expects, from their @device if they declare one.
+func demoInputs(machine *vm.VM, ritualName string) (*vm.Proof, []vm.Witness, error) {
+	machine.Device = &vm.DeviceAttr{Type: "drone", ID: "drone_001"}
+	machine.Scanner = camera.NewMockScanner("")
+	machine.Mesh = vm.NewLocalMesh(machine.Witnesses, witness.NetworkLoRa)
+
+	ritual, ok := machine.Rituals[ritualName]
+	if !ok || ritual.Ase == nil {
+		return nil, nil, nil
+	}
+	if ase := ritual.Universal(); ase != nil && ase.QR {
+		return nil, nil, nil
+	}
+
+	deviceID := machine.Device.ID
+	if device, ok := ritual.Attributes["device"].(*vm.DeviceAttr); ok {
+		deviceID = device.ID
+	}
+	proof := &vm.Proof{
+		Type:      ritual.Ase.ProofType,
+		Receipt:   "a4f2b8c3d9e1f5a7b2c8d4e9f1a3b5c7d2e4f6a8b1c3d5e7f9a2b4c6d8e1f3a5",
+		Timestamp: time.Now().Unix(),
+		DeviceID:  deviceID,
+	}
+	witnesses, err := machine.Mesh.Broadcast(ritualName, proof, ritual.Ase.Witnesses)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error gathering demo witnesses: %w", err)
+	}
+	return proof, witnesses, nil
+}
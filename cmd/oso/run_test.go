@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/vm"
+	"github.com/ase-lang/osovm/pkg/witness"
+)
+
+const testReceipt = "a4f2b8c3d9e1f5a7b2c8d4e9f1a3b5c7d2e4f6a8b1c3d5e7f9a2b4c6d8e1f3a5"
+
+// writeFile writes data to name in dir and returns its path
+func writeFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadInputFiles(t *testing.T) {
+	dir := t.TempDir()
+	proofPath := writeFile(t, dir, "proof.json", `{
+		"type": "telemetry",
+		"receipt": "`+testReceipt+`",
+		"timestamp": 1700000000,
+		"device_id": "drone_001",
+		"payload_ref": "file://telemetry.json"
+	}`)
+	witnessesPath := writeFile(t, dir, "witnesses.json", `[
+		{"device_id": "witness_001", "signature": "ab", "timestamp": 1700000001, "network": "lora"},
+		{"device_id": "witness_002", "signature": "cd", "timestamp": 1700000002, "network": "ble"}
+	]`)
+
+	proof, witnesses, err := readInputFiles(proofPath, witnessesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := vm.Proof{Type: "telemetry", Receipt: testReceipt, Timestamp: 1700000000, DeviceID: "drone_001", PayloadRef: "file://telemetry.json"}
+	if proof.Type != want.Type || proof.Receipt != want.Receipt || proof.Timestamp != want.Timestamp || proof.DeviceID != want.DeviceID || proof.PayloadRef != want.PayloadRef {
+		t.Errorf("proof %+v", proof)
+	}
+	if len(witnesses) != 2 || witnesses[1] != (vm.Witness{DeviceID: "witness_002", Signature: "cd", Timestamp: 1700000002, Network: "ble"}) {
+		t.Errorf("witnesses %+v", witnesses)
+	}
+
+	// Witnesses are optional
+	if _, witnesses, err := readInputFiles(proofPath, ""); err != nil || witnesses != nil {
+		t.Errorf("proof alone: %v, %v", witnesses, err)
+	}
+
+	for _, tc := range []struct {
+		name, proof, witnesses, err string
+	}{
+		{"unknown proof field", `{"type": "telemetry", "reciept": "ab"}`, "", `unknown field "reciept"`},
+		{"trailing data", `{"type": "telemetry"} {}`, "", "unexpected data after JSON value"},
+		{"proof not an object", `["telemetry"]`, "", "invalid JSON in"},
+		{"timestamp not a number", `{"timestamp": "now"}`, "", "invalid JSON in"},
+		{"witnesses not an array", `{}`, `{"device_id": "witness_001"}`, "invalid JSON in"},
+		{"unknown witness field", `{}`, `[{"device_id": "witness_001", "key": "ab"}]`, `unknown field "key"`},
+	} {
+		proofPath := writeFile(t, dir, "bad_proof.json", tc.proof)
+		witnessesPath := ""
+		if tc.witnesses != "" {
+			witnessesPath = writeFile(t, dir, "bad_witnesses.json", tc.witnesses)
+		}
+		if _, _, err := readInputFiles(proofPath, witnessesPath); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.err)
+		}
+	}
+	if _, _, err := readInputFiles(filepath.Join(dir, "missing.json"), ""); err == nil || !strings.Contains(err.Error(), "failed to read") {
+		t.Errorf("missing proof: %v", err)
+	}
+}
+
+func TestReadEnvelope(t *testing.T) {
+	proof, witnesses, err := readEnvelope(strings.NewReader(`{
+		"proof": {"type": "qr", "receipt": "` + testReceipt + `", "timestamp": 1700000000, "device_id": "scanner_001"},
+		"witnesses": [{"device_id": "witness_001", "signature": "ab", "timestamp": 1700000001, "network": "lora"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.Type != "qr" || proof.DeviceID != "scanner_001" || len(witnesses) != 1 || witnesses[0].Network != "lora" {
+		t.Errorf("envelope %+v %+v", proof, witnesses)
+	}
+
+	for name, tc := range map[string]struct {
+		data, err string
+	}{
+		"empty":         {"", "invalid envelope on stdin: EOF"},
+		"no proof":      {`{"witnesses": []}`, `missing "proof"`},
+		"null proof":    {`{"proof": null}`, `missing "proof"`},
+		"unknown field": {`{"proof": {}, "witness": []}`, `unknown field "witness"`},
+		"trailing data": {`{"proof": {}}]`, "unexpected data after JSON value"},
+		"not JSON":      {`proof=abc`, "invalid envelope on stdin"},
+	} {
+		if _, _, err := readEnvelope(strings.NewReader(tc.data)); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", name, err, tc.err)
+		}
+	}
+}
+
+func TestRunRejectsConflictingFlags(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{}, "Usage: oso run"},
+		{[]string{"r.oso", "extra.oso"}, "unexpected arguments: [extra.oso]"},
+		{[]string{"r.oso", "--demo", "--proof", "p.json"}, "--demo cannot be combined with --proof or --witnesses"},
+		{[]string{"--witnesses", "w.json", "r.oso"}, "--witnesses requires --proof"},
+		{[]string{"r.oso", "--events", "xml"}, `unknown --events format "xml"`},
+		{[]string{"r.oso", "--demo", "--ledger", "ledger.json"}, "--demo cannot be combined with --ledger: demo runs use in-memory stores"},
+		{[]string{"r.oso", "--demo", "--receipts", "r.json"}, "--demo cannot be combined with --receipts"},
+		{[]string{"r.oso", "--demo", "--receipt-log", "log.jsonl"}, "--demo cannot be combined with --receipt-log"},
+		{[]string{"--cache", "cache.json", "--demo", "r.oso", "--ledger", "l.json"}, "--demo cannot be combined with --cache, --ledger"},
+	} {
+		if err := runRitualCommand(tc.args); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("oso run %s: got %v, want %q", strings.Join(tc.args, " "), err, tc.err)
+		}
+	}
+}
+
+func TestDemoIgnoresStoresFromTheEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	stores := map[string]string{
+		"OSO_LEDGER":        filepath.Join(dir, "ledger.json"),
+		"OSO_RECEIPT_STORE": filepath.Join(dir, "receipts.json"),
+		"OSO_RECEIPT_LOG":   filepath.Join(dir, "receipts.jsonl"),
+		"OSO_CACHE":         filepath.Join(dir, "cache.json"),
+	}
+	for env, path := range stores {
+		t.Setenv(env, path)
+	}
+	if err := runRitualCommand([]string{"../../examples/drone_delivery.oso", "--demo", "--events", "none"}); err != nil {
+		t.Fatal(err)
+	}
+	for env, path := range stores {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("demo run wrote %s (%s): %v", path, env, err)
+		}
+	}
+}
+
+func TestRunFromProofAndWitnessFiles(t *testing.T) {
+	dir := t.TempDir()
+	registryPath := filepath.Join(dir, "witnesses.json")
+	registry, err := witness.OpenRegistry(registryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	var witnesses []vm.Witness
+	for _, id := range []string{"witness_001", "witness_002"} {
+		node, err := witness.CreateNode(id, "phone", witness.NetworkLoRa)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := registry.EnrollNode(node); err != nil {
+			t.Fatal(err)
+		}
+		att := witness.Attestation{Receipt: testReceipt, Device: "drone_001", Ritual: "delivered", Timestamp: now, Network: node.Network}
+		sig, err := node.Sign(att)
+		if err != nil {
+			t.Fatal(err)
+		}
+		witnesses = append(witnesses, vm.Witness{DeviceID: id, Signature: sig, Timestamp: now, Network: node.Network})
+	}
+	if err := registry.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	ritualPath := writeFile(t, dir, "delivered.oso", `{
+		"name": "delivered", "orisa": "eshu_router",
+		"ase": {"proof": "telemetry", "witnesses": 2},
+		"statements": [{"type": "return", "data": {"expr": "\"ok\""}}]
+	}`)
+	proof, _ := json.Marshal(vm.Proof{Type: "telemetry", Receipt: testReceipt, Timestamp: now, DeviceID: "drone_001"})
+	proofPath := writeFile(t, dir, "proof.json", string(proof))
+	data, _ := json.Marshal(witnesses)
+	witnessesPath := writeFile(t, dir, "attested.json", string(data))
+	args := []string{ritualPath, "--proof", proofPath, "--witnesses", witnessesPath, "--registry", registryPath, "--events", "none"}
+
+	if err := runRitualCommand(args); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	// With one witness the quorum is not met
+	data, _ = json.Marshal(witnesses[:1])
+	writeFile(t, dir, "attested.json", string(data))
+	if err := runRitualCommand(args); err == nil || !strings.Contains(err.Error(), "Execution failed") {
+		t.Errorf("one witness: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+// OSOVM Witness CLI
+// oso witness keygen|enroll|rotate|revoke|list - manages the trusted witness registry
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/vm"
+	"github.com/ase-lang/osovm/pkg/witness"
+)
+
+const defaultWitnessRegistry = "witness_registry.json"
+
+const witnessUsage = `Usage: oso witness <command> [flags]
+
+Commands:
+  keygen  --id ID --type TYPE --network NET --out FILE   create a witness keypair
+  enroll  --id ID --type TYPE --network NET --pubkey HEX  trust a witness key
+          --key FILE                                      (or take all of it from a key file)
+  rotate  --id ID (--pubkey HEX | --key FILE)             replace a witness key
+  revoke  --id ID [--reason TEXT]                         stop trusting a witness
+  list                                                    show enrolled witnesses
+  attest  --key FILE --receipt HEX --device ID --ritual NAME
+                                                          print a signed witness entry as JSON
+
+All commands except keygen and attest accept --registry FILE (default: $OSO_WITNESS_REGISTRY or ` + defaultWitnessRegistry + `)`
+
+// witnessRegistryPath returns the registry path used when --registry is not given
+func witnessRegistryPath() string {
+	if path := os.Getenv("OSO_WITNESS_REGISTRY"); path != "" {
+		return path
+	}
+	return defaultWitnessRegistry
+}
+
+func runWitnessCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", witnessUsage)
+	}
+
+	fs := flag.NewFlagSet("oso witness "+args[0], flag.ContinueOnError)
+	registryPath := fs.String("registry", witnessRegistryPath(), "witness registry file")
+	id := fs.String("id", "", "witness device ID")
+	deviceType := fs.String("type", "", "device type (phone, drone, av, robot, sensor)")
+	network := fs.String("network", "", "network (lora, ble, wifi, mesh)")
+	pubkey := fs.String("pubkey", "", "hex Ed25519 public key")
+	keyPath := fs.String("key", "", "witness key file from keygen")
+	out := fs.String("out", "", "where keygen writes the key file")
+	reason := fs.String("reason", "", "why the witness is revoked")
+	receipt := fs.String("receipt", "", "proof receipt to attest")
+	device := fs.String("device", "", "device that produced the proof")
+	ritual := fs.String("ritual", "", "ritual the proof is for")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	// --key supplies identity and public key for enroll/rotate
+	if *keyPath != "" {
+		node, err := witness.LoadNode(*keyPath)
+		if err != nil {
+			return err
+		}
+		if *id == "" {
+			*id = node.DeviceID
+		}
+		if *deviceType == "" {
+			*deviceType = node.DeviceType
+		}
+		if *network == "" {
+			*network = node.Network
+		}
+		if *pubkey == "" {
+			*pubkey = node.PublicKey
+		}
+	}
+
+	if args[0] == "keygen" {
+		if *id == "" || *network == "" || *out == "" {
+			return fmt.Errorf("keygen requires --id, --network and --out")
+		}
+		node, err := witness.CreateNode(*id, *deviceType, *network)
+		if err != nil {
+			return err
+		}
+		if err := node.SaveKey(*out); err != nil {
+			return err
+		}
+		fmt.Printf("🔑 Witness key for %s written to %s\n", node.DeviceID, *out)
+		fmt.Printf("   public key: %s\n", node.PublicKey)
+		return nil
+	}
+
+	if args[0] == "attest" {
+		if *keyPath == "" || *receipt == "" || *device == "" || *ritual == "" {
+			return fmt.Errorf("attest requires --key, --receipt, --device and --ritual")
+		}
+		return attest(*keyPath, *receipt, *device, *ritual)
+	}
+
+	registry, err := witness.OpenRegistry(*registryPath)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "enroll":
+		if *id == "" || *pubkey == "" {
+			return fmt.Errorf("enroll requires --id and --pubkey (or --key)")
+		}
+		e, err := registry.Enroll(*id, *deviceType, *network, *pubkey)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Enrolled witness %s (%s via %s)\n", e.DeviceID, e.DeviceType, e.Network)
+
+	case "rotate":
+		if *id == "" || *pubkey == "" {
+			return fmt.Errorf("rotate requires --id and --pubkey (or --key)")
+		}
+		e, err := registry.Rotate(*id, *pubkey)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🔄 Rotated key for %s (%d keys held)\n", e.DeviceID, len(e.Keys))
+
+	case "revoke":
+		if *id == "" {
+			return fmt.Errorf("revoke requires --id")
+		}
+		e, err := registry.Revoke(*id, *reason)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("⛔ Revoked witness %s\n", e.DeviceID)
+
+	case "list":
+		for _, e := range registry.List() {
+			fmt.Printf("%-20s %-8s %-6s %-8s enrolled %s  key %s…\n",
+				e.DeviceID, e.DeviceType, e.Network, e.Status,
+				time.Unix(e.EnrolledAt, 0).UTC().Format(time.RFC3339), e.PublicKey[:16])
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown witness command: %s\n%s", args[0], witnessUsage)
+	}
+
+	return registry.Save()
+}
+
+// attest signs an attestation with a witness key file and prints the
+// Witness entry that oso run --witnesses expects
+func attest(keyPath, receipt, device, ritual string) error {
+	node, err := witness.LoadNode(keyPath)
+	if err != nil {
+		return err
+	}
+	att := witness.Attestation{
+		Receipt:   receipt,
+		Device:    device,
+		Ritual:    ritual,
+		Timestamp: time.Now().Unix(),
+		Network:   node.Network,
+	}
+	signature, err := node.Sign(att)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(vm.Witness{
+		DeviceID:  node.DeviceID,
+		Signature: signature,
+		Timestamp: att.Timestamp,
+		Network:   att.Network,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -4,66 +4,25 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
-	"time"
-)
-
-// Import Phase 2 packages
-// Note: Using relative imports for now, production would use go modules
-
-type UniversalAse struct {
-	ProofType  string `json:"proof"`
-	Witnesses  int    `json:"witnesses"`
-	QR         bool   `json:"qr,omitempty"`
-	AutoDevice bool   `json:"auto_device,omitempty"`
-}
-
-type DeviceAttr struct {
-	Type string `json:"type"`
-	ID   string `json:"id"`
-}
-
-type DeliveryAttr struct {
-	From string `json:"from"`
-	To   string `json:"to"`
-}
-
-type Ritual struct {
-	Name     string            `json:"name"`
-	Orisa    string            `json:"orisa"`
-	Ase      *UniversalAse     `json:"ase,omitempty"`
-	Device   *DeviceAttr       `json:"device,omitempty"`
-	Delivery *DeliveryAttr     `json:"delivery,omitempty"`
-	Args     map[string]string `json:"args"`
-	Statements []Statement     `json:"statements"`
-}
 
-type Statement struct {
-	Type string                 `json:"type"`
-	Data map[string]interface{} `json:"data"`
-}
-
-type QRScan struct {
-	Hash      string `json:"hash"`
-	Timestamp int64  `json:"timestamp"`
-	DeviceID  string `json:"device_id"`
-}
-
-type WitnessSignature struct {
-	DeviceID  string `json:"device_id"`
-	Signature string `json:"signature"`
-	Timestamp int64  `json:"timestamp"`
-}
+	"github.com/ase-lang/osovm/pkg/camera"
+	"github.com/ase-lang/osovm/pkg/vm"
+	"github.com/ase-lang/osovm/pkg/witness"
+)
 
 func main() {
 	fmt.Println("🌍 Any device can now breathe Àṣẹ")
 	fmt.Println("📷 QR scanner live")
-	fmt.Println("🔢 143 attributes loaded")
+	fmt.Printf("🔢 %d attributes loaded (%d canon)\n", vm.GetAttributeCount(), vm.GetCanonAttributeCount())
 	fmt.Println()
 
+	if err := vm.CheckAttributeCanon(); err != nil {
+		fmt.Printf("❌ Attribute registry self-check failed: %v\n", err)
+		os.Exit(1)
+	}
+
 	argsOffset := 1
 	if len(os.Args) > 1 && os.Args[1][0] == '/' {
 		argsOffset = 2
@@ -88,50 +47,20 @@ func main() {
 	}
 }
 
+// executeRitual runs the ritual on the shared engine with a simulated
+// camera, host device and LoRa witness mesh
 func executeRitual(path string) error {
-	// Load ritual
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return fmt.Errorf("failed to read ritual: %w", err)
-	}
+	machine := vm.NewVM()
+	machine.Device = &vm.DeviceAttr{Type: "phone", ID: "phase2_host"}
+	machine.Scanner = camera.NewMockScanner("")
+	machine.Mesh = vm.NewLocalMesh(machine.Witnesses, witness.NetworkLoRa)
 
-	var ritual Ritual
-	if err := json.Unmarshal(data, &ritual); err != nil {
-		return fmt.Errorf("failed to parse ritual: %w", err)
+	if err := machine.LoadRitual(path); err != nil {
+		return fmt.Errorf("failed to parse ritual:\n%w", err)
 	}
 
-	fmt.Printf("🔮 Invoking %s ritual...\n", ritual.Orisa)
-
-	// Auto-detect device if requested
-	if ritual.Ase != nil && ritual.Ase.AutoDevice {
-		fmt.Printf("🤖 Auto-detected device: %s (%s)\n", ritual.Device.ID, ritual.Device.Type)
-	}
-
-	// Execute QR scan if requested
-	if ritual.Ase != nil && ritual.Ase.QR {
-		qrScan := simulateQRScan(ritual.Device.ID)
-		fmt.Printf("📷 QR scanned: %s\n", qrScan.Hash[:16]+"...")
-		
-		// Broadcast to witness mesh
-		witnesses := simulateWitnessMesh(qrScan.Hash, ritual.Ase.Witnesses)
-		fmt.Printf("📡 Broadcast → %d nodes echo → seal\n", len(witnesses))
-		
-		for _, w := range witnesses {
-			fmt.Printf("   👁️  %s confirmed\n", w.DeviceID)
-		}
-	}
-
-	// Validate proof
-	if ritual.Ase != nil {
-		fmt.Printf("✅ Àṣẹ sealed with %s proof + %d witnesses\n", ritual.Ase.ProofType, ritual.Ase.Witnesses)
-	}
-
-	// Execute Òrìṣà
-	executeOrisa(ritual.Orisa)
-
-	// Execute statements
-	for _, stmt := range ritual.Statements {
-		executeStatement(&stmt)
+	if _, err := machine.Execute(selectRitual(machine, path), nil, nil); err != nil {
+		return err
 	}
 
 	fmt.Println()
@@ -139,55 +68,13 @@ func executeRitual(path string) error {
 	return nil
 }
 
-func simulateQRScan(deviceID string) *QRScan {
-	// Simulate QR code hash
-	hash := "9f2ae8b1c4d7f3a6e9b2c5d8f1a4b7e0c3f6a9d2e5b8c1f4a7d0e3b6c9f2a5e8"
-	
-	return &QRScan{
-		Hash:      hash,
-		Timestamp: time.Now().Unix(),
-		DeviceID:  deviceID,
-	}
-}
-
-func simulateWitnessMesh(proofHash string, count int) []*WitnessSignature {
-	witnesses := make([]*WitnessSignature, count)
-	
-	for i := 0; i < count; i++ {
-		witnesses[i] = &WitnessSignature{
-			DeviceID:  fmt.Sprintf("witness_lora_%d", i+1),
-			Signature: proofHash, // Simplified
-			Timestamp: time.Now().Unix(),
+// selectRitual picks the ritual named after the file, or the only one loaded
+func selectRitual(machine *vm.VM, path string) string {
+	name := vm.RitualName(path)
+	if _, ok := machine.Rituals[name]; !ok && len(machine.Rituals) == 1 {
+		for only := range machine.Rituals {
+			return only
 		}
 	}
-	
-	return witnesses
-}
-
-func executeOrisa(orisa string) {
-	switch orisa {
-	case "eshu_router":
-		fmt.Println("🍶 Èṣù routes the action...")
-	case "obatala_guard":
-		fmt.Println("🤍 Ọbàtálá enforces quorum...")
-	case "sango_vault":
-		fmt.Println("⚡ Ṣàngó manages the vault...")
-	}
-}
-
-func executeStatement(stmt *Statement) {
-	switch stmt.Type {
-	case "call":
-		function := stmt.Data["function"].(string)
-		fmt.Printf("  → Calling %s\n", function)
-	case "return":
-		value := stmt.Data["value"]
-		fmt.Printf("  → Returning: %v\n", value)
-	}
-}
-
-func getRitualName(path string) string {
-	parts := strings.Split(path, "/")
-	filename := parts[len(parts)-1]
-	return strings.TrimSuffix(filename, ".oso")
+	return name
 }
@@ -0,0 +1,11 @@
+// OSOVM Documentation Sources
+// Embeds the docs the runtime checks itself against
+
+package docs
+
+import _ "embed"
+
+// AttributesCanon is docs/ATTRIBUTES_CANON.md
+//
+//go:embed ATTRIBUTES_CANON.md
+var AttributesCanon string
@@ -0,0 +1,16 @@
+// OSOVM Grammar Sources
+// Embeds the pest grammars so the runtime can check itself against them
+
+package grammar
+
+import _ "embed"
+
+// Full is grammar/ORISA_FULL.pest, the full attribute canon
+//
+//go:embed ORISA_FULL.pest
+var Full string
+
+// Phase2 is grammar/ORISA_PHASE2.pest, the Phase 2 device attributes
+//
+//go:embed ORISA_PHASE2.pest
+var Phase2 string
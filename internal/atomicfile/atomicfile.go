@@ -0,0 +1,51 @@
+// OSOVM Atomic Files
+// Replaces the JSON stores the CLI keeps between runs without ever leaving
+// a torn file behind
+
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Write replaces the file at path with data. The data goes to a temporary
+// file in the same directory, is synced to disk and then renamed over
+// path, so a reader or a crash sees either the old contents or the new,
+// never part of either. The directory is synced too, so the rename itself
+// survives a crash.
+func Write(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes the directory entry the rename wrote. Not every
+// platform can sync a directory, and by now the data itself is on disk,
+// so failures are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
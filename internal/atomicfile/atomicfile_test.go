@@ -0,0 +1,41 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteReplacesTheFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "store.json")
+	for _, data := range []string{"{\"v\": 1}\n", "{\"v\": 2}\n"} {
+		if err := Write(path, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := os.ReadFile(path); err != nil || string(got) != data {
+			t.Errorf("read %q, %v; want %q", got, err, data)
+		}
+	}
+	// No temporary file is left behind
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory holds %d files", len(entries))
+	}
+
+	// A write that can't complete leaves the old contents in place
+	if err := Write(filepath.Join(dir, "missing", "store.json"), []byte("x")); err == nil {
+		t.Error("wrote into a missing directory")
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(filepath.Join(dir, "sub"), []byte("x")); err == nil {
+		t.Error("replaced a directory")
+	}
+	if got, _ := os.ReadFile(path); string(got) != "{\"v\": 2}\n" {
+		t.Errorf("store now %q", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("directory holds %d files after failed writes", len(entries))
+	}
+}
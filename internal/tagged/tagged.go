@@ -0,0 +1,41 @@
+// OSOVM Tagged Encoding
+// The length-prefixed, domain-tagged byte strings that OSOVM signs and
+// hashes
+
+package tagged
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// Message returns the domain tag followed by each field, every one
+// prefixed with its big-endian uint32 length. The prefixes keep field
+// boundaries unambiguous, and the tag keeps one kind of message from ever
+// being mistaken for another.
+func Message(domain string, fields ...string) []byte {
+	n := 4 + len(domain)
+	for _, f := range fields {
+		n += 4 + len(f)
+	}
+	msg := make([]byte, 0, n)
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(domain)))
+	msg = append(msg, domain...)
+	for _, f := range fields {
+		msg = binary.BigEndian.AppendUint32(msg, uint32(len(f)))
+		msg = append(msg, f...)
+	}
+	return msg
+}
+
+// Sum returns the SHA-256 of Message(domain, fields...)
+func Sum(domain string, fields ...string) [sha256.Size]byte {
+	return sha256.Sum256(Message(domain, fields...))
+}
+
+// Hash returns the hex SHA-256 of Message(domain, fields...)
+func Hash(domain string, fields ...string) string {
+	sum := Sum(domain, fields...)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,25 @@
+package tagged
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestMessageLayout(t *testing.T) {
+	want := "00000003" + hex.EncodeToString([]byte("d/1")) +
+		"00000002" + hex.EncodeToString([]byte("ab")) +
+		"00000000" +
+		"00000001" + hex.EncodeToString([]byte("c"))
+	if got := hex.EncodeToString(Message("d/1", "ab", "", "c")); got != want {
+		t.Errorf("message\n%s\nwant\n%s", got, want)
+	}
+
+	// Moving a byte across a field boundary changes the message
+	if Hash("d/1", "ab", "c") == Hash("d/1", "a", "bc") || Hash("d/1", "ab") == Hash("d/2", "ab") {
+		t.Error("distinct messages hash alike")
+	}
+	// SHA-256 of the empty-domain, no-field message, 00000000
+	if got := Hash(""); got != "df3f619804a92fdb4057192dc43dd748ea778adc52bc498ce80524c014b81119" {
+		t.Errorf("empty message hashes to %s", got)
+	}
+}
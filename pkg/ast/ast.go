@@ -0,0 +1,327 @@
+// OSOVM AST
+// Typed syntax tree for Ọ̀ṢỌ́ modules, shared by the JSON and textual front ends
+
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ========== Positions ==========
+
+// Pos is a location in a source file. Line and Col are 1-based; Col counts runes.
+type Pos struct {
+	Offset int
+	Line   int
+	Col    int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Col)
+}
+
+// IsValid reports whether the position was set
+func (p Pos) IsValid() bool {
+	return p.Line > 0
+}
+
+// Span is a half-open source range [Start, End)
+type Span struct {
+	Start Pos
+	End   Pos
+}
+
+// Extent returns the span itself; embedding Span makes every node a Node
+func (s Span) Extent() Span {
+	return s
+}
+
+// Node is implemented by every AST node
+type Node interface {
+	Extent() Span
+}
+
+// ========== Module ==========
+
+// Format records which surface syntax a module was written in
+type Format int
+
+const (
+	FormatText Format = iota // ORISA syntax (grammar/ORISA_FULL.pest)
+	FormatJSON               // JSON ritual objects
+)
+
+func (f Format) String() string {
+	if f == FormatJSON {
+		return "json"
+	}
+	return "text"
+}
+
+// Module - One .oso file
+type Module struct {
+	Span
+	File    string
+	Format  Format
+	Rituals []*Ritual
+}
+
+// Ident - A name with its location
+type Ident struct {
+	Span
+	Name string
+}
+
+// Ritual - Attributes, an Òrìṣà header with args, and a body
+type Ritual struct {
+	Span
+	Name       Ident // Declared name (JSON) or derived from the file name (text)
+	Orisa      Ident
+	Attributes []*Attribute
+	Args       []*Arg
+	Body       []Stmt
+}
+
+// Attribute returns the first attribute with the given name, or nil
+func (r *Ritual) Attribute(name string) *Attribute {
+	for _, attr := range r.Attributes {
+		if attr.Name.Name == name {
+			return attr
+		}
+	}
+	return nil
+}
+
+// Attribute - An @attribute (text) or an entry of the "attributes" object (JSON).
+// The JSON "ase" key is normalised to the textual name "àṣẹ".
+type Attribute struct {
+	Span
+	Name   Ident
+	Rule   string // Grammar rule that matched (text only)
+	Params []*Arg
+}
+
+// Param returns the parameter with the given name, or nil
+func (a *Attribute) Param(name string) *Arg {
+	for _, p := range a.Params {
+		if p.Name.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Arg - A `name: value` pair. Name is empty for positional arguments.
+type Arg struct {
+	Span
+	Name  Ident
+	Value *Value
+}
+
+// ========== Statements ==========
+
+// Stmt is implemented by statement nodes in a ritual body
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// Call - `orisa_kw(args);` (text) or a "call" statement (JSON). Args are
+// positional or named; Result, if set, receives the function's result.
+type Call struct {
+	Span
+	Func   Ident
+	Args   []*Arg
+	Result Ident
+}
+
+// Assign - An "assign" statement. A literal "value" is a *Literal.
+type Assign struct {
+	Span
+	Variable Ident
+	Value    Expr
+}
+
+// Return - A "return" statement; Value may be nil
+type Return struct {
+	Span
+	Value Expr
+}
+
+// If - An "if" statement; Else may be empty
+type If struct {
+	Span
+	Cond Expr
+	Then []Stmt
+	Else []Stmt
+}
+
+// Loop - A bounded loop. A "repeat" loop runs Count times, binding the
+// iteration index to Var if set. A "while" loop runs while Cond holds, for
+// at most Max iterations.
+type Loop struct {
+	Span
+	Count Expr
+	Var   Ident
+	Cond  Expr
+	Max   int64
+	Body  []Stmt
+}
+
+func (*Call) stmtNode()   {}
+func (*Assign) stmtNode() {}
+func (*Return) stmtNode() {}
+func (*If) stmtNode()     {}
+func (*Loop) stmtNode()   {}
+
+// ========== Expressions ==========
+
+// Expr is implemented by expression nodes
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// Literal - A constant value
+type Literal struct {
+	Span
+	Value *Value
+}
+
+// VarRef - A variable or ritual argument, looked up in that order
+type VarRef struct {
+	Span
+	Name string
+}
+
+// Unary - `!x` or `-x`
+type Unary struct {
+	Span
+	Op string
+	X  Expr
+}
+
+// Binary - `x op y` for arithmetic, comparison and logical operators
+type Binary struct {
+	Span
+	Op string
+	X  Expr
+	Y  Expr
+}
+
+// FuncCall - A builtin function call inside an expression, e.g. `upper(name)`
+type FuncCall struct {
+	Span
+	Func Ident
+	Args []Expr
+}
+
+func (*Literal) exprNode()  {}
+func (*VarRef) exprNode()   {}
+func (*Unary) exprNode()    {}
+func (*Binary) exprNode()   {}
+func (*FuncCall) exprNode() {}
+
+// ========== Values ==========
+
+type ValueKind int
+
+const (
+	ValueNull ValueKind = iota
+	ValueString
+	ValueInt
+	ValueFloat
+	ValueBool
+	ValueIdent // Bare identifier or keyword
+	ValueList
+	ValueObject
+)
+
+var valueKindNames = map[ValueKind]string{
+	ValueNull:   "null",
+	ValueString: "string",
+	ValueInt:    "integer",
+	ValueFloat:  "float",
+	ValueBool:   "boolean",
+	ValueIdent:  "identifier",
+	ValueList:   "list",
+	ValueObject: "object",
+}
+
+func (k ValueKind) String() string {
+	return valueKindNames[k]
+}
+
+// Value - A literal. Str holds String and Ident values.
+type Value struct {
+	Span
+	Kind   ValueKind
+	Str    string
+	Int    int64
+	Float  float64
+	Bool   bool
+	List   []*Value
+	Fields []*Arg // ValueObject entries in source order
+}
+
+// IsScalar reports whether the value is a single literal
+func (v *Value) IsScalar() bool {
+	return v.Kind != ValueList && v.Kind != ValueObject
+}
+
+// Interface converts the value to plain Go data (string, int64, float64,
+// bool, []interface{}, map[string]interface{} or nil)
+func (v *Value) Interface() interface{} {
+	switch v.Kind {
+	case ValueString, ValueIdent:
+		return v.Str
+	case ValueInt:
+		return v.Int
+	case ValueFloat:
+		return v.Float
+	case ValueBool:
+		return v.Bool
+	case ValueList:
+		items := make([]interface{}, len(v.List))
+		for i, item := range v.List {
+			items[i] = item.Interface()
+		}
+		return items
+	case ValueObject:
+		fields := make(map[string]interface{}, len(v.Fields))
+		for _, f := range v.Fields {
+			fields[f.Name.Name] = f.Value.Interface()
+		}
+		return fields
+	}
+	return nil
+}
+
+// String renders the value the way it would appear in a JSON ritual's args
+func (v *Value) String() string {
+	switch v.Kind {
+	case ValueString, ValueIdent:
+		return v.Str
+	case ValueInt:
+		return strconv.FormatInt(v.Int, 10)
+	case ValueFloat:
+		return strconv.FormatFloat(v.Float, 'f', -1, 64)
+	case ValueBool:
+		return strconv.FormatBool(v.Bool)
+	case ValueList:
+		items := make([]string, len(v.List))
+		for i, item := range v.List {
+			items[i] = item.String()
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case ValueObject:
+		fields := make([]string, len(v.Fields))
+		for i, f := range v.Fields {
+			fields[i] = f.Name.Name + ":" + f.Value.String()
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return "null"
+}
@@ -0,0 +1,157 @@
+// OSOVM Attribute Binding
+// Decodes attribute parameters from the AST into typed Go structs
+
+package bind
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+	"github.com/ase-lang/osovm/pkg/diag"
+)
+
+// Attribute binds attr's parameters to the struct pointed to by target,
+// matching parameter names against the fields' json tags. Unknown
+// parameters and type mismatches are reported to diags; it returns false
+// if any were found.
+func Attribute(attr *ast.Attribute, target interface{}, diags *diag.List) bool {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		panic("bind: target must be a pointer to a struct")
+	}
+	st := rv.Elem()
+	fields := fieldsByTag(st.Type())
+
+	ok := true
+	for _, p := range attr.Params {
+		index, known := fields[p.Name.Name]
+		if !known {
+			diags.Errorf(p.Name.Span, "unknown parameter %q for attribute %q (expected %s)",
+				p.Name.Name, attr.Name.Name, strings.Join(sortedKeys(fields), ", "))
+			ok = false
+			continue
+		}
+		what := attr.Name.Name + "." + p.Name.Name
+		if !setValue(st.Field(index), p.Value, what, diags) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// Fields returns the parameter names a struct type accepts, in declaration order
+func Fields(t reflect.Type) []string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := tagName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func fieldsByTag(t reflect.Type) map[string]int {
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name := tagName(t.Field(i)); name != "" {
+			fields[name] = i
+		}
+	}
+	return fields
+}
+
+func tagName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return "" // unexported
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return f.Name
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setValue assigns v to field, converting between AST value kinds and Go kinds
+func setValue(field reflect.Value, v *ast.Value, what string, diags *diag.List) bool {
+	mismatch := func(want string) bool {
+		diags.Errorf(v.Span, "%s must be %s, found %s", what, want, v.Kind)
+		return false
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if v.Kind != ast.ValueString && v.Kind != ast.ValueIdent {
+			return mismatch("a string")
+		}
+		field.SetString(v.Str)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Kind != ast.ValueInt {
+			return mismatch("an integer")
+		}
+		if field.OverflowInt(v.Int) {
+			diags.Errorf(v.Span, "%s: %d out of range", what, v.Int)
+			return false
+		}
+		field.SetInt(v.Int)
+
+	case reflect.Float32, reflect.Float64:
+		switch v.Kind {
+		case ast.ValueFloat:
+			field.SetFloat(v.Float)
+		case ast.ValueInt:
+			field.SetFloat(float64(v.Int))
+		default:
+			return mismatch("a number")
+		}
+		if math.IsInf(field.Float(), 0) {
+			diags.Errorf(v.Span, "%s out of range", what)
+			return false
+		}
+
+	case reflect.Bool:
+		if v.Kind != ast.ValueBool {
+			return mismatch("a boolean")
+		}
+		field.SetBool(v.Bool)
+
+	case reflect.Slice:
+		// A single value is accepted where a list is expected (grammar
+		// rules such as safety_attr take an ident the JSON form spells as a list)
+		items := v.List
+		if v.Kind != ast.ValueList {
+			items = []*ast.Value{v}
+		}
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		ok := true
+		for i, item := range items {
+			if !setValue(slice.Index(i), item, what, diags) {
+				ok = false
+			}
+		}
+		field.Set(slice)
+		return ok
+
+	default:
+		panic("bind: unsupported field kind " + field.Kind().String())
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package bind
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+	"github.com/ase-lang/osovm/pkg/diag"
+)
+
+type testAttr struct {
+	Name    string   `json:"name"`
+	Count   int      `json:"count"`
+	Small   int8     `json:"small"`
+	Rate    float64  `json:"rate"`
+	Enabled bool     `json:"enabled,omitempty"`
+	Tags    []string `json:"tags"`
+	Plain   string   // bound by its Go name
+	Skipped string   `json:"-"`
+	hidden  string
+}
+
+func str(s string) *ast.Value         { return &ast.Value{Kind: ast.ValueString, Str: s} }
+func ident(s string) *ast.Value       { return &ast.Value{Kind: ast.ValueIdent, Str: s} }
+func integer(i int64) *ast.Value      { return &ast.Value{Kind: ast.ValueInt, Int: i} }
+func float(f float64) *ast.Value      { return &ast.Value{Kind: ast.ValueFloat, Float: f} }
+func boolean(b bool) *ast.Value       { return &ast.Value{Kind: ast.ValueBool, Bool: b} }
+func list(v ...*ast.Value) *ast.Value { return &ast.Value{Kind: ast.ValueList, List: v} }
+
+// attr builds @test with params given as name, value pairs
+func attr(params ...interface{}) *ast.Attribute {
+	a := &ast.Attribute{Name: ast.Ident{Name: "test"}}
+	for i := 0; i < len(params); i += 2 {
+		a.Params = append(a.Params, &ast.Arg{Name: ast.Ident{Name: params[i].(string)}, Value: params[i+1].(*ast.Value)})
+	}
+	return a
+}
+
+func TestAttribute(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		attr   *ast.Attribute
+		want   testAttr
+		errors []string
+	}{
+		{
+			name: "every kind",
+			attr: attr("name", str("tithe"), "count", integer(3), "small", integer(-128), "rate", float(0.5),
+				"enabled", boolean(true), "tags", list(str("a"), ident("b")), "Plain", str("p")),
+			want: testAttr{Name: "tithe", Count: 3, Small: -128, Rate: 0.5, Enabled: true, Tags: []string{"a", "b"}, Plain: "p"},
+		},
+		// Coercions
+		{name: "identifier as string", attr: attr("name", ident("treasury")), want: testAttr{Name: "treasury"}},
+		{name: "integer as float", attr: attr("rate", integer(369)), want: testAttr{Rate: 369}},
+		{name: "single value as list", attr: attr("tags", ident("estop")), want: testAttr{Tags: []string{"estop"}}},
+		{name: "empty list", attr: attr("tags", list()), want: testAttr{Tags: []string{}}},
+		// Missing parameters keep their zero value; Validate decides which
+		// ones an attribute requires
+		{name: "no params", attr: attr(), want: testAttr{}},
+		{name: "some params", attr: attr("count", integer(7)), want: testAttr{Count: 7}},
+		// Bad types
+		{name: "string for integer", attr: attr("count", str("3")), errors: []string{"test.count must be an integer, found string"}},
+		{name: "float for integer", attr: attr("count", float(3.5)), errors: []string{"test.count must be an integer, found float"}},
+		{name: "integer for string", attr: attr("name", integer(1)), errors: []string{"test.name must be a string, found integer"}},
+		{name: "string for float", attr: attr("rate", str("half")), errors: []string{"test.rate must be a number, found string"}},
+		{name: "integer for boolean", attr: attr("enabled", integer(1)), errors: []string{"test.enabled must be a boolean, found integer"}},
+		{name: "list for string", attr: attr("name", list(str("a"))), errors: []string{"test.name must be a string, found list"}},
+		{name: "bad list item", attr: attr("tags", list(str("a"), integer(2))), errors: []string{"test.tags must be a string, found integer"}},
+		{name: "integer overflow", attr: attr("small", integer(128)), errors: []string{"test.small: 128 out of range"}},
+		{name: "infinite float", attr: attr("rate", float(math.Inf(1))), errors: []string{"test.rate out of range"}},
+		// Unknown keys, including fields that can't be bound
+		{name: "unknown", attr: attr("colour", str("red")), errors: []string{
+			`unknown parameter "colour" for attribute "test" (expected Plain, count, enabled, name, rate, small, tags)`}},
+		{name: "json -", attr: attr("Skipped", str("x")), errors: []string{`unknown parameter "Skipped"`}},
+		{name: "unexported", attr: attr("hidden", str("x")), errors: []string{`unknown parameter "hidden"`}},
+		{name: "case matters", attr: attr("Name", str("x")), errors: []string{`unknown parameter "Name"`}},
+		// Every problem is reported, not just the first
+		{name: "several", attr: attr("count", str("x"), "colour", str("red"), "name", str("ok"), "enabled", str("yes")), errors: []string{
+			"test.count must be an integer",
+			`unknown parameter "colour"`,
+			"test.enabled must be a boolean",
+		}},
+	} {
+		var got testAttr
+		diags := diag.NewList("test.oso", nil)
+		ok := Attribute(tc.attr, &got, diags)
+		if ok != (len(tc.errors) == 0) || len(diags.Diagnostics) != len(tc.errors) {
+			t.Errorf("%s: ok %v, diagnostics %v", tc.name, ok, diags.Diagnostics)
+			continue
+		}
+		for i, want := range tc.errors {
+			if msg := diags.Diagnostics[i].Message; !strings.Contains(msg, want) {
+				t.Errorf("%s: diagnostic %d is %q, want %q", tc.name, i, msg, want)
+			}
+		}
+		if ok && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: bound %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAttributeKeepsDefaults(t *testing.T) {
+	got := testAttr{Name: "default", Rate: 0.25}
+	if !Attribute(attr("count", integer(2)), &got, diag.NewList("test.oso", nil)) {
+		t.Fatal("bind failed")
+	}
+	if !reflect.DeepEqual(got, testAttr{Name: "default", Rate: 0.25, Count: 2}) {
+		t.Errorf("bound %+v", got)
+	}
+}
+
+func TestFields(t *testing.T) {
+	want := []string{"name", "count", "small", "rate", "enabled", "tags", "Plain"}
+	for _, typ := range []reflect.Type{reflect.TypeOf(testAttr{}), reflect.TypeOf(&testAttr{})} {
+		if got := Fields(typ); !reflect.DeepEqual(got, want) {
+			t.Errorf("Fields(%s) = %v", typ, got)
+		}
+	}
+}
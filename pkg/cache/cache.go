@@ -0,0 +1,212 @@
+// OSOVM Cache
+// Key/value state rituals keep between executions. Keys are namespaced by
+// the ritual that stored them, every entry expires after its TTL, and Save
+// keeps only the live ones.
+
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ase-lang/osovm/internal/atomicfile"
+)
+
+// Entry - A cached value and when it stops being served. Only the ritual
+// that stored it is served it.
+type Entry struct {
+	Ritual  string      `json:"ritual"` // identity of the ritual that stored it
+	Key     string      `json:"key"`
+	Value   interface{} `json:"value"`
+	Stored  int64       `json:"stored"`
+	Expires int64       `json:"expires"` // unix seconds
+}
+
+// Live reports whether the entry is still served at now
+func (e *Entry) Live(now time.Time) bool {
+	return now.Unix() < e.Expires
+}
+
+// Cache - Entries by ritual and key. Expired entries are never served and
+// are dropped by Purge and Save.
+type Cache struct {
+	Path string
+	Now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[entryID]Entry
+}
+
+// entryID - Where an entry is kept: one ritual's key never names another's
+type entryID struct {
+	ritual, key string
+}
+
+// cacheFile is the on-disk format
+type cacheFile struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+const cacheVersion = 1
+
+// New creates an empty in-memory cache
+func New() *Cache {
+	return &Cache{Now: time.Now, entries: make(map[entryID]Entry)}
+}
+
+// Open loads the cache at path. A missing file is an empty cache.
+func Open(path string) (*Cache, error) {
+	c := New()
+	c.Path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	// Numbers keep the int/float distinction rituals see
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var file cacheFile
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse cache %s: %w", path, err)
+	}
+	if file.Version != cacheVersion {
+		return nil, fmt.Errorf("cache %s: unsupported version %d", path, file.Version)
+	}
+	for _, e := range file.Entries {
+		if e.Key == "" || e.Ritual == "" {
+			return nil, fmt.Errorf("cache %s: entry without a ritual and key", path)
+		}
+		id := entryID{e.Ritual, e.Key}
+		if _, dup := c.entries[id]; dup {
+			return nil, fmt.Errorf("cache %s: duplicate entry %s for %s", path, e.Key, e.Ritual)
+		}
+		e.Value = normalize(e.Value)
+		c.entries[id] = e
+	}
+	return c, nil
+}
+
+// Save writes the live entries back to the cache's path atomically
+func (c *Cache) Save() error {
+	if c.Path == "" {
+		return fmt.Errorf("cache has no path")
+	}
+
+	c.Purge()
+	file := cacheFile{Version: cacheVersion, Entries: c.Entries()}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := atomicfile.Write(c.Path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to save cache: %w", err)
+	}
+	return nil
+}
+
+// Get returns the live entry ritual stored under key
+func (c *Cache) Get(ritual, key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[entryID{ritual, key}]
+	if !ok || e.Ritual != ritual || !e.Live(c.Now()) {
+		return nil, false
+	}
+	return &e, true
+}
+
+// Set stores value under ritual's key for ttl, replacing any earlier entry
+func (c *Cache) Set(ritual, key string, value interface{}, ttl time.Duration) (*Entry, error) {
+	if ritual == "" {
+		return nil, fmt.Errorf("cache ritual required")
+	}
+	if key == "" {
+		return nil, fmt.Errorf("cache key required")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("cache ttl must be positive (got %s)", ttl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.Now()
+	e := Entry{Ritual: ritual, Key: key, Value: value, Stored: now.Unix(), Expires: now.Add(ttl).Unix()}
+	c.entries[entryID{ritual, key}] = e
+	return &e, nil
+}
+
+// Delete removes ritual's entry under key
+func (c *Cache) Delete(ritual, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, entryID{ritual, key})
+}
+
+// Purge drops every expired entry and returns how many it dropped
+func (c *Cache) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.Now()
+	n := 0
+	for id, e := range c.entries {
+		if !e.Live(now) {
+			delete(c.entries, id)
+			n++
+		}
+	}
+	return n
+}
+
+// Entries returns every entry, expired ones included, sorted by ritual
+// and key
+func (c *Cache) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list := make([]Entry, 0, len(c.entries))
+	for _, e := range c.entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Ritual != list[j].Ritual {
+			return list[i].Ritual < list[j].Ritual
+		}
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
+
+// normalize turns decoded json.Numbers into int64 or float64
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if !strings.ContainsAny(v.String(), ".eE") {
+			if i, err := v.Int64(); err == nil {
+				return i
+			}
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = normalize(v[k])
+		}
+	}
+	return v
+}
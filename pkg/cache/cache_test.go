@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntriesBelongToTheirRitual(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := New()
+	c.Now = func() time.Time { return now }
+
+	if _, err := c.Set("memo@aa", "state", int64(1), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Set("other@bb", "state", int64(2), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := c.Get("memo@aa", "state"); !ok || e.Value != int64(1) || e.Ritual != "memo@aa" {
+		t.Errorf("memo's entry: %+v, %v", e, ok)
+	}
+	if e, ok := c.Get("other@bb", "state"); !ok || e.Value != int64(2) {
+		t.Errorf("other's entry: %+v, %v", e, ok)
+	}
+	if _, ok := c.Get("memo@cc", "state"); ok {
+		t.Error("served to another version of memo")
+	}
+
+	c.Delete("memo@aa", "state")
+	if _, ok := c.Get("other@bb", "state"); !ok {
+		t.Error("deleting memo's entry deleted other's")
+	}
+
+	for _, tc := range []struct {
+		ritual, key, err string
+	}{
+		{"", "state", "cache ritual required"},
+		{"memo@aa", "", "cache key required"},
+	} {
+		if _, err := c.Set(tc.ritual, tc.key, 1, time.Minute); err == nil || err.Error() != tc.err {
+			t.Errorf("Set(%q, %q): got %v, want %q", tc.ritual, tc.key, err, tc.err)
+		}
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("other@bb", "state"); ok {
+		t.Error("expired entry served")
+	}
+}
+
+func TestSaveAndOpen(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	path := filepath.Join(t.TempDir(), "cache.json")
+	c, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Now = func() time.Time { return now }
+	for _, ritual := range []string{"b@01", "a@01"} {
+		if _, err := c.Set(ritual, "state", map[string]interface{}{"visits": int64(3), "rate": 0.5}, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.Set("a@01", "gone", true, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := reopened.Entries()
+	if len(list) != 2 || list[0].Ritual != "a@01" || list[1].Ritual != "b@01" {
+		t.Fatalf("reopened %+v", list)
+	}
+	if state := list[0].Value.(map[string]interface{}); state["visits"] != int64(3) || state["rate"] != 0.5 {
+		t.Errorf("state %v", state)
+	}
+
+	for name, data := range map[string]string{
+		"version":   `{"version": 2, "entries": []}`,
+		"no ritual": `{"version": 1, "entries": [{"key": "state", "value": 1, "expires": 1}]}`,
+		"duplicate": `{"version": 1, "entries": [{"ritual": "a@01", "key": "k"}, {"ritual": "a@01", "key": "k"}]}`,
+		"json":      `{"version": 1,`,
+	} {
+		bad := filepath.Join(t.TempDir(), "cache.json")
+		if err := os.WriteFile(bad, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(bad); err == nil || !strings.Contains(err.Error(), bad) {
+			t.Errorf("opened a cache with a bad %s: %v", name, err)
+		}
+	}
+}
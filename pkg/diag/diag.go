@@ -0,0 +1,173 @@
+// OSOVM Diagnostics
+// Collects every syntax and semantic error in a file and renders them
+// with caret-underlined source snippets
+
+package diag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	if s == Warning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Diagnostic - One reported problem
+type Diagnostic struct {
+	Severity Severity
+	Span     ast.Span
+	Message  string
+}
+
+// List collects diagnostics for a single source file. A List with errors
+// is itself an error, so it can be returned from loaders unchanged.
+type List struct {
+	File        string
+	Source      []byte
+	Diagnostics []*Diagnostic
+}
+
+func NewList(file string, source []byte) *List {
+	return &List{File: file, Source: source}
+}
+
+// Errorf records an error at span
+func (l *List) Errorf(span ast.Span, format string, args ...interface{}) {
+	l.add(Error, span, format, args...)
+}
+
+// Warnf records a warning at span
+func (l *List) Warnf(span ast.Span, format string, args ...interface{}) {
+	l.add(Warning, span, format, args...)
+}
+
+func (l *List) add(sev Severity, span ast.Span, format string, args ...interface{}) {
+	l.Diagnostics = append(l.Diagnostics, &Diagnostic{
+		Severity: sev,
+		Span:     span,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// ErrorCount returns the number of error-severity diagnostics
+func (l *List) ErrorCount() int {
+	n := 0
+	for _, d := range l.Diagnostics {
+		if d.Severity == Error {
+			n++
+		}
+	}
+	return n
+}
+
+// HasErrors reports whether any error-severity diagnostic was recorded
+func (l *List) HasErrors() bool {
+	return l.ErrorCount() > 0
+}
+
+// Err returns the list as an error if it contains errors, otherwise nil
+func (l *List) Err() error {
+	if !l.HasErrors() {
+		return nil
+	}
+	return l
+}
+
+// Error renders all diagnostics, sorted by position
+func (l *List) Error() string {
+	var sb strings.Builder
+	l.sort()
+	for i, d := range l.Diagnostics {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(l.Format(d))
+	}
+	if n := l.ErrorCount(); n > 1 {
+		fmt.Fprintf(&sb, "\n%d errors", n)
+	}
+	return sb.String()
+}
+
+func (l *List) sort() {
+	sort.SliceStable(l.Diagnostics, func(i, j int) bool {
+		return l.Diagnostics[i].Span.Start.Offset < l.Diagnostics[j].Span.Start.Offset
+	})
+}
+
+// Format renders a single diagnostic:
+//
+//	ritual.oso:5:12: error: expected ',', found "witnesses"
+//	    5 | @àṣẹ(proof:qr witnesses:2)
+//	      |              ^^^^^^^^^
+func (l *List) Format(d *Diagnostic) string {
+	start := d.Span.Start
+	if !start.IsValid() {
+		return fmt.Sprintf("%s: %s: %s", l.File, d.Severity, d.Message)
+	}
+
+	header := fmt.Sprintf("%s:%d:%d: %s: %s", l.File, start.Line, start.Col, d.Severity, d.Message)
+	line, ok := l.line(start)
+	if !ok {
+		return header
+	}
+
+	gutter := fmt.Sprintf("%5d | ", start.Line)
+	pad := strings.Repeat(" ", len(gutter)-2) + "| "
+
+	// Indent the caret by the rune width of the prefix, keeping tabs
+	var indent strings.Builder
+	col := 1
+	for _, r := range line {
+		if col >= start.Col {
+			break
+		}
+		if r == '\t' {
+			indent.WriteRune('\t')
+		} else {
+			indent.WriteRune(' ')
+		}
+		col++
+	}
+
+	width := 1
+	end := d.Span.End
+	if end.Line == start.Line && end.Col > start.Col {
+		width = end.Col - start.Col
+	} else if end.Line > start.Line {
+		width = utf8.RuneCountInString(line) - start.Col + 1
+	}
+	if width < 1 {
+		width = 1
+	}
+
+	return header + "\n" + gutter + line + "\n" + pad + indent.String() + strings.Repeat("^", width)
+}
+
+// line returns the source line containing pos, without its terminator
+func (l *List) line(pos ast.Pos) (string, bool) {
+	if pos.Offset < 0 || pos.Offset > len(l.Source) {
+		return "", false
+	}
+	start := strings.LastIndexByte(string(l.Source[:pos.Offset]), '\n') + 1
+	end := len(l.Source)
+	if i := strings.IndexByte(string(l.Source[pos.Offset:]), '\n'); i >= 0 {
+		end = pos.Offset + i
+	}
+	return strings.TrimRight(string(l.Source[start:end]), "\r"), true
+}
@@ -0,0 +1,96 @@
+package diag
+
+import (
+	"testing"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+)
+
+// span builds a span on one line from 1-based columns, given the byte
+// offset of the line's first character
+func span(lineOffset, line, startCol, endCol int) ast.Span {
+	return ast.Span{
+		Start: ast.Pos{Offset: lineOffset + startCol - 1, Line: line, Col: startCol},
+		End:   ast.Pos{Offset: lineOffset + endCol - 1, Line: line, Col: endCol},
+	}
+}
+
+func TestFormatUnderlinesTheSpan(t *testing.T) {
+	src := "@witness(3)\neshu_router(to alice) {}\n"
+	l := NewList("r.oso", []byte(src))
+	l.Errorf(span(12, 2, 16, 21), "expected %s, found %q", "':'", "alice")
+
+	want := "r.oso:2:16: error: expected ':', found \"alice\"\n" +
+		"    2 | eshu_router(to alice) {}\n" +
+		"      |                ^^^^^"
+	if got := l.Format(l.Diagnostics[0]); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatCountsRunesAndKeepsTabs(t *testing.T) {
+	// The caret lines up under "x" past the tab and the multi-byte àṣẹ
+	src := "\t@àṣẹ(witnesses:x)"
+	l := NewList("r.oso", []byte(src))
+	l.Errorf(ast.Span{
+		Start: ast.Pos{Offset: 21, Line: 1, Col: 17},
+		End:   ast.Pos{Offset: 22, Line: 1, Col: 18},
+	}, "expected integer")
+
+	want := "r.oso:1:17: error: expected integer\n" +
+		"    1 | \t@àṣẹ(witnesses:x)\n" +
+		"      | \t               ^"
+	if got := l.Format(l.Diagnostics[0]); got != want {
+		t.Errorf("got\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestFormatEdgeSpans(t *testing.T) {
+	src := "abc\r\ndef\n"
+	l := NewList("r.oso", []byte(src))
+
+	for _, tc := range []struct {
+		span ast.Span
+		want string
+	}{
+		// Empty spans still get one caret; CRLF is trimmed
+		{span(0, 1, 2, 2), "r.oso:1:2: error: e\n    1 | abc\n      |  ^"},
+		// A span running past its line underlines to the end of it
+		{ast.Span{Start: ast.Pos{Offset: 1, Line: 1, Col: 2}, End: ast.Pos{Offset: 7, Line: 2, Col: 3}}, "r.oso:1:2: error: e\n    1 | abc\n      |  ^^"},
+		// A span without a position renders without a snippet
+		{ast.Span{}, "r.oso: error: e"},
+		// As does one past the end of the source
+		{ast.Span{Start: ast.Pos{Offset: 99, Line: 9, Col: 1}}, "r.oso:9:1: error: e"},
+	} {
+		d := &Diagnostic{Span: tc.span, Message: "e"}
+		if got := l.Format(d); got != tc.want {
+			t.Errorf("got\n%q\nwant\n%q", got, tc.want)
+		}
+	}
+}
+
+func TestListSortsAndCountsErrors(t *testing.T) {
+	src := "one\ntwo\nthree\n"
+	l := NewList("r.oso", []byte(src))
+	if l.Err() != nil {
+		t.Fatal("empty list is an error")
+	}
+
+	l.Warnf(span(0, 1, 1, 4), "first")
+	if l.HasErrors() || l.Err() != nil {
+		t.Error("a warning made the list an error")
+	}
+	l.Errorf(span(8, 3, 1, 6), "third")
+	l.Errorf(span(4, 2, 1, 4), "second")
+
+	if l.ErrorCount() != 2 || l.Err() == nil {
+		t.Fatalf("%d errors", l.ErrorCount())
+	}
+	want := "r.oso:1:1: warning: first\n    1 | one\n      | ^^^\n" +
+		"r.oso:2:1: error: second\n    2 | two\n      | ^^^\n" +
+		"r.oso:3:1: error: third\n    3 | three\n      | ^^^^^\n" +
+		"2 errors"
+	if got := l.Error(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,570 @@
+// OSOVM Ledger
+// Double-entry accounts rituals move value between: every journal entry
+// is a set of postings applied together or not at all. Holds set funds
+// aside and back streams paid out over time; checkpoints let an execution
+// undo everything it posted.
+
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ase-lang/osovm/internal/atomicfile"
+)
+
+// Equity is the account deposits are drawn from. It is the only account
+// whose balance may go negative, so every balance sums to zero.
+const Equity = "equity"
+
+// Posting - One double-entry movement: Amount leaves From and enters To
+type Posting struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount int64  `json:"amount"`
+	Memo   string `json:"memo,omitempty"`
+}
+
+// Entry - A journal entry: postings that were applied together
+type Entry struct {
+	Seq       uint64    `json:"seq"`
+	Ritual    string    `json:"ritual,omitempty"` // empty for deposits made outside a ritual
+	Timestamp int64     `json:"timestamp"`
+	Postings  []Posting `json:"postings"`
+}
+
+// Hold - Funds set aside in an account. Held funds can't be posted away
+// until the hold is released.
+type Hold struct {
+	ID      uint64 `json:"id"`
+	Account string `json:"account"`
+	Amount  int64  `json:"amount"`
+	Ritual  string `json:"ritual,omitempty"`
+	Memo    string `json:"memo,omitempty"`
+}
+
+// InsufficientFundsError - An entry or hold would overdraw an account.
+// Available is the balance less any holds.
+type InsufficientFundsError struct {
+	Account   string
+	Available int64
+	Needed    int64
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf("insufficient funds in %s: available %d, needs %d", e.Account, e.Available, e.Needed)
+}
+
+// Ledger - Account balances and the journal that produced them. Balances
+// are never stored: Open replays the saved journal to rebuild them, and
+// Save writes the journal, holds and streams back.
+type Ledger struct {
+	Path string
+	Now  func() time.Time
+
+	mu       sync.Mutex
+	balances map[string]int64
+	journal  []Entry
+	holds    map[uint64]Hold
+	nextHold uint64
+
+	streams    map[uint64]Stream
+	nextStream uint64
+}
+
+// ledgerFile is the on-disk format; balances are rebuilt from the journal
+type ledgerFile struct {
+	Version    int      `json:"version"`
+	Journal    []Entry  `json:"journal"`
+	Holds      []Hold   `json:"holds,omitempty"`
+	NextHold   uint64   `json:"next_hold,omitempty"`
+	Streams    []Stream `json:"streams,omitempty"`
+	NextStream uint64   `json:"next_stream,omitempty"`
+}
+
+const ledgerVersion = 1
+
+// New creates an empty in-memory ledger
+func New() *Ledger {
+	return &Ledger{
+		Now:      time.Now,
+		balances: make(map[string]int64),
+		holds:    make(map[uint64]Hold),
+		nextHold: 1,
+
+		streams:    make(map[uint64]Stream),
+		nextStream: 1,
+	}
+}
+
+// Open loads the ledger at path, replaying its journal. A missing file is
+// an empty ledger.
+func Open(path string) (*Ledger, error) {
+	l := New()
+	l.Path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ledger: %w", err)
+	}
+
+	var file ledgerFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse ledger %s: %w", path, err)
+	}
+	if file.Version != ledgerVersion {
+		return nil, fmt.Errorf("ledger %s: unsupported version %d", path, file.Version)
+	}
+	for _, e := range file.Journal {
+		if e.Seq != uint64(len(l.journal))+1 {
+			return nil, fmt.Errorf("ledger %s: entry %d out of sequence", path, e.Seq)
+		}
+		if err := l.apply(e.Postings); err != nil {
+			return nil, fmt.Errorf("ledger %s: entry %d: %w", path, e.Seq, err)
+		}
+		l.journal = append(l.journal, e)
+	}
+	if file.NextHold > 0 {
+		l.nextHold = file.NextHold
+	}
+	for _, h := range file.Holds {
+		if _, dup := l.holds[h.ID]; dup || h.ID == 0 || h.ID >= l.nextHold {
+			return nil, fmt.Errorf("ledger %s: invalid hold %d", path, h.ID)
+		}
+		if err := l.hold(h); err != nil {
+			return nil, fmt.Errorf("ledger %s: hold %d: %w", path, h.ID, err)
+		}
+	}
+	if file.NextStream > 0 {
+		l.nextStream = file.NextStream
+	}
+	for _, s := range file.Streams {
+		if _, dup := l.streams[s.ID]; dup || s.ID == 0 || s.ID >= l.nextStream {
+			return nil, fmt.Errorf("ledger %s: invalid stream %d", path, s.ID)
+		}
+		if h, ok := l.holds[s.Hold]; !ok || h.Account != s.From || h.Amount != s.Amount-s.Paid {
+			return nil, fmt.Errorf("ledger %s: stream %d: hold %d does not back it", path, s.ID, s.Hold)
+		}
+		l.streams[s.ID] = s
+	}
+	return l, nil
+}
+
+// Save writes the journal and open holds back to the ledger's path atomically
+func (l *Ledger) Save() error {
+	if l.Path == "" {
+		return fmt.Errorf("ledger has no path")
+	}
+
+	l.mu.Lock()
+	file := ledgerFile{
+		Version:    ledgerVersion,
+		Journal:    l.journal,
+		Holds:      l.sortedHolds(),
+		NextHold:   l.nextHold,
+		Streams:    l.sortedStreams(),
+		NextStream: l.nextStream,
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := atomicfile.Write(l.Path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to save ledger: %w", err)
+	}
+	return nil
+}
+
+// Balance returns the balance of account, holds included; unknown
+// accounts hold 0
+func (l *Ledger) Balance(account string) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.balances[account]
+}
+
+// Available returns the balance of account less its holds
+func (l *Ledger) Available(account string) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.balances[account] - l.held(account)
+}
+
+// Opened reports whether account has seen a posting
+func (l *Ledger) Opened(account string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.balances[account]
+	return ok
+}
+
+// Balances returns every account that has seen a posting
+func (l *Ledger) Balances() map[string]int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make(map[string]int64, len(l.balances))
+	for k, v := range l.balances {
+		out[k] = v
+	}
+	return out
+}
+
+// Accounts returns the names of every account that has seen a posting, sorted
+func (l *Ledger) Accounts() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	names := make([]string, 0, len(l.balances))
+	for name := range l.balances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Journal returns every entry, oldest first
+func (l *Ledger) Journal() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Entry(nil), l.journal...)
+}
+
+// Post records the postings as one journal entry for ritual. Either every
+// posting is applied or, if any would overdraw an account, none is.
+func (l *Ledger) Post(ritual string, postings ...Posting) (*Entry, error) {
+	if len(postings) == 0 {
+		return nil, fmt.Errorf("journal entry has no postings")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.apply(postings); err != nil {
+		return nil, err
+	}
+	e := Entry{
+		Seq:       uint64(len(l.journal)) + 1,
+		Ritual:    ritual,
+		Timestamp: l.Now().Unix(),
+		Postings:  append([]Posting(nil), postings...),
+	}
+	l.journal = append(l.journal, e)
+	return &e, nil
+}
+
+// Deposit credits account from Equity
+func (l *Ledger) Deposit(account string, amount int64, memo string) (*Entry, error) {
+	return l.Post("", Posting{From: Equity, To: account, Amount: amount, Memo: memo})
+}
+
+// apply checks postings against the current balances and applies them all;
+// the caller holds mu
+func (l *Ledger) apply(postings []Posting) error {
+	next := make(map[string]int64)
+	balance := func(account string) int64 {
+		if b, ok := next[account]; ok {
+			return b
+		}
+		return l.balances[account]
+	}
+	for i, p := range postings {
+		switch {
+		case p.From == "" || p.To == "":
+			return fmt.Errorf("posting %d: account required", i+1)
+		case p.From == p.To:
+			return fmt.Errorf("posting %d: %s cannot pay itself", i+1, p.From)
+		case p.Amount <= 0:
+			return fmt.Errorf("posting %d: amount must be positive (got %d)", i+1, p.Amount)
+		}
+		from, to := balance(p.From), balance(p.To)
+		if from < math.MinInt64+p.Amount || to > math.MaxInt64-p.Amount {
+			return fmt.Errorf("posting %d: %d from %s to %s is out of range", i+1, p.Amount, p.From, p.To)
+		}
+		next[p.From] = from - p.Amount
+		next[p.To] = to + p.Amount
+	}
+	for account, b := range next {
+		if held := l.held(account); b < held && account != Equity {
+			available := l.balances[account] - held
+			return &InsufficientFundsError{Account: account, Available: available, Needed: available - (b - held)}
+		}
+	}
+	for account, b := range next {
+		l.balances[account] = b
+	}
+	return nil
+}
+
+// ========== Holds ==========
+
+// Hold sets amount aside in account for ritual
+func (l *Ledger) Hold(ritual, account string, amount int64, memo string) (*Hold, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h := Hold{ID: l.nextHold, Account: account, Amount: amount, Ritual: ritual, Memo: memo}
+	if err := l.hold(h); err != nil {
+		return nil, err
+	}
+	l.nextHold++
+	return &h, nil
+}
+
+// Release frees a hold's funds
+func (l *Ledger) Release(id uint64) (*Hold, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h, ok := l.holds[id]
+	if !ok {
+		return nil, fmt.Errorf("no hold %d", id)
+	}
+	return l.release(h)
+}
+
+// ReleaseFor frees a hold on behalf of ritual, which must be the ritual
+// that placed it
+func (l *Ledger) ReleaseFor(ritual string, id uint64) (*Hold, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h, ok := l.holds[id]
+	if !ok {
+		return nil, fmt.Errorf("no hold %d", id)
+	}
+	if h.Ritual != ritual {
+		return nil, fmt.Errorf("hold %d belongs to %s, not %s", id, h.Ritual, ritual)
+	}
+	return l.release(h)
+}
+
+// release deletes h unless it backs a stream; the caller holds mu
+func (l *Ledger) release(h Hold) (*Hold, error) {
+	for _, s := range l.streams {
+		if s.Hold == h.ID {
+			return nil, fmt.Errorf("hold %d backs stream %d", h.ID, s.ID)
+		}
+	}
+	delete(l.holds, h.ID)
+	return &h, nil
+}
+
+// Holds returns every open hold, oldest first
+func (l *Ledger) Holds() []Hold {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sortedHolds()
+}
+
+// hold checks h against the account's available funds and records it;
+// the caller holds mu
+func (l *Ledger) hold(h Hold) error {
+	switch {
+	case h.Account == "":
+		return fmt.Errorf("hold needs an account")
+	case h.Account == Equity:
+		return fmt.Errorf("cannot hold funds in %s", Equity)
+	case h.Amount <= 0:
+		return fmt.Errorf("hold amount must be positive (got %d)", h.Amount)
+	}
+	if available := l.balances[h.Account] - l.held(h.Account); available < h.Amount {
+		return &InsufficientFundsError{Account: h.Account, Available: available, Needed: h.Amount}
+	}
+	l.holds[h.ID] = h
+	return nil
+}
+
+// held totals the holds on account; the caller holds mu
+func (l *Ledger) held(account string) int64 {
+	var total int64
+	for _, h := range l.holds {
+		if h.Account == account {
+			total += h.Amount
+		}
+	}
+	return total
+}
+
+// sortedHolds lists the holds by ID; the caller holds mu
+func (l *Ledger) sortedHolds() []Hold {
+	list := make([]Hold, 0, len(l.holds))
+	for _, h := range l.holds {
+		list = append(list, h)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// ========== Streams ==========
+
+// Stream - Amount paid from From to To in proportion to the time elapsed
+// since Start, in full after Duration seconds. The unpaid rest is held
+// in From by Hold.
+type Stream struct {
+	ID       uint64 `json:"id"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Amount   int64  `json:"amount"`
+	Paid     int64  `json:"paid"`
+	Start    int64  `json:"start"`    // unix seconds
+	Duration int64  `json:"duration"` // seconds
+	Hold     uint64 `json:"hold"`
+	Ritual   string `json:"ritual,omitempty"`
+}
+
+// Due returns what has accrued by now and not yet been paid
+func (s *Stream) Due(now int64) int64 {
+	elapsed := now - s.Start
+	switch {
+	case elapsed <= 0:
+		return 0
+	case elapsed >= s.Duration:
+		return s.Amount - s.Paid
+	}
+	// Amount*elapsed/Duration is below Amount, but the product may not fit
+	hi, lo := bits.Mul64(uint64(s.Amount), uint64(elapsed))
+	accrued, _ := bits.Div64(hi, lo, uint64(s.Duration))
+	return int64(accrued) - s.Paid
+}
+
+// OpenStream holds amount in from and starts paying it to to over
+// duration seconds
+func (l *Ledger) OpenStream(ritual, from, to string, amount, duration int64) (*Stream, error) {
+	switch {
+	case from == "" || to == "":
+		return nil, fmt.Errorf("stream needs from and to accounts")
+	case from == to:
+		return nil, fmt.Errorf("%s cannot stream to itself", from)
+	case duration <= 0:
+		return nil, fmt.Errorf("stream duration must be positive (got %d)", duration)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := Stream{ID: l.nextStream, From: from, To: to, Amount: amount, Start: l.Now().Unix(), Duration: duration, Hold: l.nextHold, Ritual: ritual}
+	h := Hold{ID: l.nextHold, Account: from, Amount: amount, Ritual: ritual, Memo: fmt.Sprintf("stream %d to %s", s.ID, to)}
+	if err := l.hold(h); err != nil {
+		return nil, err
+	}
+	l.nextHold++
+	l.streams[s.ID] = s
+	l.nextStream++
+	return &s, nil
+}
+
+// Flow pays what stream id has accrued as one journal entry for ritual.
+// The entry is nil when nothing is due. A fully paid stream is closed.
+func (l *Ledger) Flow(ritual string, id uint64) (*Stream, *Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.streams[id]
+	if !ok {
+		return nil, nil, fmt.Errorf("no open stream %d", id)
+	}
+	due := s.Due(l.Now().Unix())
+	if due <= 0 {
+		return &s, nil, nil
+	}
+
+	// The payment comes out of the hold
+	h := l.holds[s.Hold]
+	if h.Amount == due {
+		delete(l.holds, h.ID)
+	} else {
+		l.holds[h.ID] = Hold{ID: h.ID, Account: h.Account, Amount: h.Amount - due, Ritual: h.Ritual, Memo: h.Memo}
+	}
+	postings := []Posting{{From: s.From, To: s.To, Amount: due, Memo: fmt.Sprintf("stream %d", s.ID)}}
+	if err := l.apply(postings); err != nil {
+		l.holds[h.ID] = h
+		return nil, nil, err
+	}
+	e := Entry{Seq: uint64(len(l.journal)) + 1, Ritual: ritual, Timestamp: l.Now().Unix(), Postings: postings}
+	l.journal = append(l.journal, e)
+
+	s.Paid += due
+	if s.Paid == s.Amount {
+		delete(l.streams, id)
+	} else {
+		l.streams[id] = s
+	}
+	return &s, &e, nil
+}
+
+// Streams returns every open stream, oldest first
+func (l *Ledger) Streams() []Stream {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sortedStreams()
+}
+
+// sortedStreams lists the streams by ID; the caller holds mu
+func (l *Ledger) sortedStreams() []Stream {
+	list := make([]Stream, 0, len(l.streams))
+	for _, s := range l.streams {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// ========== Checkpoints ==========
+
+// Checkpoint - The ledger's state at a point in time, to roll back to
+type Checkpoint struct {
+	entries    int
+	holds      map[uint64]Hold
+	nextHold   uint64
+	streams    map[uint64]Stream
+	nextStream uint64
+}
+
+// Checkpoint records the current state
+func (l *Ledger) Checkpoint() Checkpoint {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	holds := make(map[uint64]Hold, len(l.holds))
+	for id, h := range l.holds {
+		holds[id] = h
+	}
+	streams := make(map[uint64]Stream, len(l.streams))
+	for id, s := range l.streams {
+		streams[id] = s
+	}
+	return Checkpoint{entries: len(l.journal), holds: holds, nextHold: l.nextHold, streams: streams, nextStream: l.nextStream}
+}
+
+// Rollback undoes every entry posted, every hold placed or released and
+// every stream opened or paid since cp, returning how many entries were
+// discarded
+func (l *Ledger) Rollback(cp Checkpoint) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	discarded := len(l.journal) - cp.entries
+	for _, e := range l.journal[cp.entries:] {
+		for _, p := range e.Postings {
+			l.balances[p.From] += p.Amount
+			l.balances[p.To] -= p.Amount
+		}
+	}
+	l.journal = l.journal[:cp.entries]
+	// Accounts the discarded entries opened are gone again
+	opened := make(map[string]bool)
+	for _, e := range l.journal {
+		for _, p := range e.Postings {
+			opened[p.From], opened[p.To] = true, true
+		}
+	}
+	for account := range l.balances {
+		if !opened[account] {
+			delete(l.balances, account)
+		}
+	}
+	l.holds, l.nextHold = cp.holds, cp.nextHold
+	l.streams, l.nextStream = cp.streams, cp.nextStream
+	return discarded
+}
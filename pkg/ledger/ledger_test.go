@@ -0,0 +1,245 @@
+package ledger
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// checkBalanced fails unless every balance sums to zero and each one is
+// what replaying the journal gives
+func checkBalanced(t *testing.T, l *Ledger) {
+	t.Helper()
+	replayed := make(map[string]int64)
+	for _, e := range l.Journal() {
+		for _, p := range e.Postings {
+			replayed[p.From] -= p.Amount
+			replayed[p.To] += p.Amount
+		}
+	}
+	var sum int64
+	balances := l.Balances()
+	for account, b := range balances {
+		sum += b
+		if b != replayed[account] {
+			t.Errorf("%s: balance %d, journal gives %d", account, b, replayed[account])
+		}
+		if b < 0 && account != Equity {
+			t.Errorf("%s is overdrawn: %d", account, b)
+		}
+	}
+	if sum != 0 || len(balances) != len(replayed) {
+		t.Errorf("balances sum to %d over %d accounts; the journal has %d", sum, len(balances), len(replayed))
+	}
+}
+
+func TestPostIsAllOrNothing(t *testing.T) {
+	l := New()
+	if _, err := l.Deposit("alice", 100, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second posting overdraws alice, so neither is applied
+	_, err := l.Post("pay",
+		Posting{From: "alice", To: "bob", Amount: 60},
+		Posting{From: "alice", To: "carol", Amount: 50},
+	)
+	var insufficient *InsufficientFundsError
+	if !errors.As(err, &insufficient) || *insufficient != (InsufficientFundsError{Account: "alice", Available: 100, Needed: 110}) {
+		t.Fatalf("got %v", err)
+	}
+	if l.Balance("alice") != 100 || l.Opened("bob") || len(l.Journal()) != 1 {
+		t.Errorf("a failed entry was applied: %v", l.Balances())
+	}
+
+	entry, err := l.Post("pay",
+		Posting{From: "alice", To: "bob", Amount: 60},
+		Posting{From: "bob", To: "carol", Amount: 60},
+	)
+	if err != nil || entry.Seq != 2 {
+		t.Fatalf("chained postings: %v", err)
+	}
+	if l.Balance("alice") != 40 || l.Balance("bob") != 0 || l.Balance("carol") != 60 {
+		t.Errorf("balances %v", l.Balances())
+	}
+	checkBalanced(t, l)
+}
+
+func TestPostRejects(t *testing.T) {
+	l := New()
+	if _, err := l.Deposit("alice", math.MaxInt64-10, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		p    Posting
+		err  string
+	}{
+		{"no account", Posting{From: "alice", Amount: 1}, "account required"},
+		{"self", Posting{From: "alice", To: "alice", Amount: 1}, "cannot pay itself"},
+		{"zero", Posting{From: "alice", To: "bob", Amount: 0}, "amount must be positive"},
+		{"negative", Posting{From: "alice", To: "bob", Amount: -5}, "amount must be positive"},
+		{"credit overflow", Posting{From: Equity, To: "alice", Amount: 11}, "out of range"},
+		{"equity overflow", Posting{From: Equity, To: "bob", Amount: math.MaxInt64}, "out of range"},
+	} {
+		if _, err := l.Post("bad", tc.p); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.err)
+		}
+	}
+	if _, err := l.Post("empty"); err == nil {
+		t.Error("posted an empty entry")
+	}
+	if l.Balance("alice") != math.MaxInt64-10 || len(l.Journal()) != 1 {
+		t.Errorf("a rejected posting was applied: %v", l.Balances())
+	}
+	checkBalanced(t, l)
+}
+
+func TestHoldsLimitWhatCanBePosted(t *testing.T) {
+	l := New()
+	if _, err := l.Deposit("vault", 100, ""); err != nil {
+		t.Fatal(err)
+	}
+	h, err := l.Hold("escrow", "vault", 70, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Available("vault") != 30 || l.Balance("vault") != 100 {
+		t.Errorf("available %d of %d", l.Available("vault"), l.Balance("vault"))
+	}
+	var insufficient *InsufficientFundsError
+	if _, err := l.Post("pay", Posting{From: "vault", To: "bob", Amount: 31}); !errors.As(err, &insufficient) || insufficient.Available != 30 {
+		t.Errorf("spent held funds: %v", err)
+	}
+	if _, err := l.Hold("escrow", "vault", 31, ""); !errors.As(err, &insufficient) {
+		t.Errorf("held funds twice: %v", err)
+	}
+	if _, err := l.Hold("escrow", Equity, 1, ""); err == nil {
+		t.Error("held equity")
+	}
+
+	if _, err := l.Release(h.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Release(h.ID); err == nil {
+		t.Error("released a hold twice")
+	}
+	if _, err := l.Post("pay", Posting{From: "vault", To: "bob", Amount: 100}); err != nil {
+		t.Errorf("released funds: %v", err)
+	}
+	checkBalanced(t, l)
+}
+
+func TestRollbackRestoresTheCheckpoint(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := New()
+	l.Now = func() time.Time { return now }
+	if _, err := l.Deposit("alice", 1000, ""); err != nil {
+		t.Fatal(err)
+	}
+	kept, err := l.Hold("keep", "alice", 100, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := l.Balances()
+	cp := l.Checkpoint()
+
+	// Everything an execution might do
+	if _, err := l.Post("run", Posting{From: "alice", To: "bob", Amount: 300}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Hold("run", "bob", 50, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Release(kept.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.OpenStream("run", "alice", "carol", 200, 10); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(5 * time.Second)
+	if _, _, err := l.Flow("run", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := l.Rollback(cp); n != 2 {
+		t.Errorf("discarded %d entries, want 2", n)
+	}
+	after := l.Balances()
+	if len(after) != len(before) || after["alice"] != 1000 || after[Equity] != -1000 {
+		t.Errorf("balances %v, want %v", after, before)
+	}
+	if holds := l.Holds(); len(holds) != 1 || holds[0] != *kept {
+		t.Errorf("holds %+v", holds)
+	}
+	if len(l.Streams()) != 0 || l.Opened("bob") || l.Opened("carol") {
+		t.Error("rollback left the execution's accounts or stream")
+	}
+	// IDs handed out after the checkpoint are reused
+	if h, _ := l.Hold("next", "alice", 1, ""); h.ID != kept.ID+1 {
+		t.Errorf("next hold %d", h.ID)
+	}
+	checkBalanced(t, l)
+}
+
+func TestStreamPaysInProportion(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := New()
+	l.Now = func() time.Time { return now }
+	if _, err := l.Deposit("alice", math.MaxInt64/2, ""); err != nil {
+		t.Fatal(err)
+	}
+	// Amount*elapsed doesn't fit in 64 bits
+	s, err := l.OpenStream("pay", "alice", "bob", math.MaxInt64/2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if due := s.Due(now.Unix() + 1); due != math.MaxInt64/6 {
+		t.Errorf("due after 1 of 3 seconds: %d", due)
+	}
+	for _, step := range []int64{1, 1, 5} {
+		now = now.Add(time.Duration(step) * time.Second)
+		if _, _, err := l.Flow("pay", s.ID); err != nil {
+			t.Fatal(err)
+		}
+		checkBalanced(t, l)
+	}
+	if l.Balance("bob") != math.MaxInt64/2 || len(l.Holds()) != 0 || len(l.Streams()) != 0 {
+		t.Errorf("bob has %d, %d holds, %d streams", l.Balance("bob"), len(l.Holds()), len(l.Streams()))
+	}
+}
+
+func TestSaveAndOpenReplayTheJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Deposit("alice", 500, "seed"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Post("pay", Posting{From: "alice", To: "bob", Amount: 120}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Hold("pay", "alice", 80, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Balance("alice") != 380 || reopened.Available("alice") != 300 || reopened.Balance("bob") != 120 {
+		t.Errorf("reopened balances %v", reopened.Balances())
+	}
+	if h, _ := reopened.Hold("next", "alice", 1, ""); h == nil || h.ID != 2 {
+		t.Errorf("next hold after reopening: %+v", h)
+	}
+	checkBalanced(t, reopened)
+}
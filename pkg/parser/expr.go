@@ -0,0 +1,184 @@
+// OSOVM Expression Parser
+// Expressions used by JSON statements ("expr", "cond", "times"):
+//
+//   expr    = or
+//   or      = and ("||" and)*
+//   and     = cmp ("&&" cmp)*
+//   cmp     = sum (("==" | "!=" | "<" | "<=" | ">" | ">=") sum)?
+//   sum     = product (("+" | "-") product)*
+//   product = unary (("*" | "/" | "%") unary)*
+//   unary   = ("!" | "-") unary | primary
+//   primary = int_lit | float_lit | str_lit | "true" | "false" | "null"
+//           | ident "(" (expr ("," expr)*)? ")" | ident | "(" expr ")"
+
+package parser
+
+import (
+	"strconv"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+	"github.com/ase-lang/osovm/pkg/diag"
+)
+
+// ParseExpr parses a single-line expression whose first character is at
+// base, so diagnostics point into the enclosing file. It returns nil if
+// errors were reported.
+func ParseExpr(src string, base ast.Pos, diags *diag.List) ast.Expr {
+	mark := len(diags.Diagnostics)
+	tokens := tokenize(src, diags)
+	for i := range tokens {
+		tokens[i].Span = shiftSpan(tokens[i].Span, base)
+	}
+	for _, d := range diags.Diagnostics[mark:] {
+		d.Span = shiftSpan(d.Span, base)
+	}
+	if len(diags.Diagnostics) > mark {
+		return nil
+	}
+
+	p := &parser{tokens: tokens, diags: diags}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil
+	}
+	if tok := p.peek(); tok.Kind != tokEOF {
+		p.errorf(tok, "unexpected %s after expression", tok.describe())
+		return nil
+	}
+	return expr
+}
+
+// shiftSpan moves a span lexed from an expression string to its place in
+// the file. Expression strings never span lines.
+func shiftSpan(s ast.Span, base ast.Pos) ast.Span {
+	shift := func(p ast.Pos) ast.Pos {
+		return ast.Pos{Offset: base.Offset + p.Offset, Line: base.Line, Col: base.Col + p.Col - 1}
+	}
+	return ast.Span{Start: shift(s.Start), End: shift(s.End)}
+}
+
+// ========== Precedence Levels ==========
+
+var (
+	orOps      = []string{"||"}
+	andOps     = []string{"&&"}
+	compareOps = []string{"==", "!=", "<", "<=", ">", ">="}
+	sumOps     = []string{"+", "-"}
+	productOps = []string{"*", "/", "%"}
+)
+
+func (p *parser) parseExpr() (ast.Expr, error) {
+	return p.parseBinary(0)
+}
+
+// parseBinary parses the left-associative level; comparisons don't chain
+func (p *parser) parseBinary(level int) (ast.Expr, error) {
+	levels := [][]string{orOps, andOps, compareOps, sumOps, productOps}
+	if level == len(levels) {
+		return p.parseUnary()
+	}
+
+	x, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.Kind != tokOp || !containsString(levels[level], tok.Text) {
+			return x, nil
+		}
+		p.next()
+		y, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &ast.Binary{
+			Span: ast.Span{Start: x.Extent().Start, End: y.Extent().End},
+			Op:   tok.Text,
+			X:    x,
+			Y:    y,
+		}
+		if levels[level][0] == compareOps[0] {
+			if next := p.peek(); next.Kind == tokOp && containsString(compareOps, next.Text) {
+				return nil, p.errorf(next, "comparisons cannot be chained; use && between them")
+			}
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) parseUnary() (ast.Expr, error) {
+	tok := p.peek()
+	if tok.Kind == tokOp && (tok.Text == "!" || tok.Text == "-") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Unary{Span: ast.Span{Start: tok.Span.Start, End: x.Extent().End}, Op: tok.Text, X: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (ast.Expr, error) {
+	tok := p.peek()
+	lit := &ast.Literal{Span: tok.Span, Value: &ast.Value{Span: tok.Span}}
+
+	switch tok.Kind {
+	case tokInt:
+		lit.Value.Kind, lit.Value.Int = ast.ValueInt, p.parseInt(tok)
+	case tokFloat:
+		lit.Value.Kind = ast.ValueFloat
+		lit.Value.Float, _ = strconv.ParseFloat(tok.Text, 64)
+	case tokString:
+		lit.Value.Kind, lit.Value.Str = ast.ValueString, tok.Text
+	case tokLParen:
+		p.next()
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen); err != nil {
+			return nil, err
+		}
+		return x, nil
+	case tokIdent:
+		switch tok.Text {
+		case "true", "false":
+			lit.Value.Kind, lit.Value.Bool = ast.ValueBool, tok.Text == "true"
+		case "null":
+			lit.Value.Kind = ast.ValueNull
+		default:
+			p.next()
+			name := ast.Ident{Span: tok.Span, Name: tok.Text}
+			if p.peek().Kind == tokLParen {
+				return p.parseFuncCall(name)
+			}
+			return &ast.VarRef{Span: tok.Span, Name: tok.Text}, nil
+		}
+	default:
+		return nil, p.errorf(tok, "expected expression, found %s", tok.describe())
+	}
+	p.next()
+	return lit, nil
+}
+
+func (p *parser) parseFuncCall(name ast.Ident) (ast.Expr, error) {
+	p.next() // '('
+	call := &ast.FuncCall{Func: name}
+	for p.peek().Kind != tokRParen {
+		if len(call.Args) > 0 {
+			if _, err := p.expect(tokComma); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+	}
+	p.next() // ')'
+	call.Span = ast.Span{Start: name.Span.Start, End: p.prevEnd()}
+	return call, nil
+}
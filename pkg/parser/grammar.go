@@ -0,0 +1,252 @@
+// OSOVM Grammar Tables
+// Attribute parameter shapes transcribed from grammar/ORISA_FULL.pest
+
+package parser
+
+import "github.com/ase-lang/osovm/pkg/proofkind"
+
+// ========== Keyword Sets ==========
+
+// Every registered proof kind: proof_kw from ORISA_FULL.pest plus the
+// Phase 1 kinds and aliases (qr_scan, sensor, ...) existing rituals use
+var proofKeywords = proofkind.Names()
+
+var orisaKeywords = []string{
+	"eshu_router", "obatala_guard", "sango_vault",
+	"oya_witness", "yemoja_cache", "ogun_forge",
+	"oshun_river", "osanyin_herb", "orunmila_oracle",
+}
+
+var (
+	gpuKeywords      = []string{"h100", "a100", "v100", "t4", "rtx4090"}
+	providerKeywords = []string{"vast", "runpod", "lambda"}
+	memKindKeywords  = []string{"ephemeral", "durable", "vector", "graph"}
+	memIndexKeywords = []string{"faiss", "pinecone", "weaviate", "none"}
+	idKindKeywords   = []string{"did", "ens", "satname", "npc"}
+	recKindKeywords  = []string{"composite", "simple", "zk"}
+	verRoleKeywords  = []string{"steward", "witness", "council"}
+	counRoleKeywords = []string{"tech", "spirit", "safety"}
+	weekdays         = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+)
+
+// ========== Parameter Specs ==========
+
+type paramKind int
+
+const (
+	paramInt     paramKind = iota // int_lit
+	paramFloat                    // float_lit
+	paramBool                     // bool_kw
+	paramStr                      // str_lit
+	paramIdent                    // ident
+	paramHash                     // hash64 / addr32
+	paramKeyword                  // bare keyword from Options
+	paramQuoted                   // 'quoted' keyword from Options
+)
+
+var paramKindNames = map[paramKind]string{
+	paramInt:     "integer",
+	paramFloat:   "float",
+	paramBool:    "boolean",
+	paramStr:     "string",
+	paramIdent:   "identifier",
+	paramHash:    "64-digit hex hash",
+	paramKeyword: "keyword",
+	paramQuoted:  "quoted keyword",
+}
+
+func (k paramKind) String() string {
+	return paramKindNames[k]
+}
+
+// paramSpec - One attribute parameter. Key is empty for positional
+// parameters, in which case Name gives the field they populate.
+type paramSpec struct {
+	Key     string
+	Name    string
+	Kind    paramKind
+	List    bool
+	Options []string
+}
+
+// field returns the name the parsed value is stored under
+func (p paramSpec) field() string {
+	if p.Key != "" {
+		return p.Key
+	}
+	return p.Name
+}
+
+// attrRule - One grammar alternative for an attribute
+type attrRule struct {
+	Rule      string // pest rule name
+	Params    []paramSpec
+	Bare      bool // no parenthesised parameter list (@nonreentrant)
+	Unordered bool // parameters may appear in any order (ase_params)
+}
+
+func p(key string, kind paramKind) paramSpec {
+	return paramSpec{Key: key, Kind: kind}
+}
+
+func pList(key string, kind paramKind) paramSpec {
+	return paramSpec{Key: key, Kind: kind, List: true}
+}
+
+func pKeyword(key string, options []string) paramSpec {
+	return paramSpec{Key: key, Kind: paramKeyword, Options: options}
+}
+
+func pQuoted(key string, options ...string) paramSpec {
+	return paramSpec{Key: key, Kind: paramQuoted, Options: options}
+}
+
+func pPositional(name string, kind paramKind) paramSpec {
+	return paramSpec{Name: name, Kind: kind}
+}
+
+func rule(name string, params ...paramSpec) attrRule {
+	return attrRule{Rule: name, Params: params}
+}
+
+// ========== Attribute Grammar ==========
+
+// attributeGrammar maps attribute names (without '@') to their grammar
+// alternatives, tried in order like pest's ordered choice
+var attributeGrammar = map[string][]attrRule{
+	// 1-10: Core Proof & Witness
+	"proof":     {rule("proof_attr", pKeyword("kind", proofKeywords), p("receipt", paramHash))},
+	"witness":   {rule("witness_attr", pPositional("count", paramInt))},
+	"receipt":   {rule("receipt_attr", pKeyword("kind", recKindKeywords), p("hash", paramHash), p("pointer", paramStr), p("verifiers", paramInt))},
+	"àṣẹ":       {{Rule: "ase_attr", Unordered: true, Params: []paramSpec{pKeyword("proof", proofKeywords), p("witnesses", paramInt), p("qr", paramBool), p("auto_device", paramBool)}}},
+	"telemetry": {rule("telemetry_attr", p("device", paramStr))},
+
+	// 11-20: SimaaS & AIO Jobs
+	"sim":             {rule("sim_attr", p("episode_id", paramInt), p("steps", paramInt))},
+	"job":             {rule("job_attr", pQuoted("type", "digital", "meta_digital", "physical"), p("title", paramStr), p("budget", paramInt), p("requires", paramIdent))},
+	"hardware":        {rule("hardware_attr", p("estop_check", paramBool))},
+	"collaboration":   {rule("collaboration_attr", pList("actors", paramIdent), pList("roles", paramStr))},
+	"accountability":  {rule("accountability_attr", pQuoted("metric", "who_drove_what"))},
+	"offering":        {rule("offering_attr", p("amount", paramInt), p("shrine", paramStr))},
+	"sim_fund":        {rule("sim_fund_attr", p("user_fee", paramInt), p("rate_limit", paramInt))},
+	"circuit_breaker": {rule("circuit_breaker_attr", p("max_attempts", paramInt), p("cooldown_ms", paramInt))},
+
+	// 21-30: Herb & Regional
+	"herb":     {rule("herb_attr", p("key", paramStr), p("class", paramStr))},
+	"regional": {rule("regional_attr", p("locale", paramStr))},
+
+	// 31-40: Governance & Safety
+	"vote":          {rule("vote_attr", pList("weights", paramFloat), p("quorum", paramInt))},
+	"dispute":       {rule("dispute_attr", p("window", paramInt), p("quorum", paramInt))},
+	"slash":         {rule("slash_attr", p("amount", paramInt), p("reason", paramStr))},
+	"wallet":        {rule("wallet_attr", p("balance", paramInt), p("stake", paramInt), pList("caps", paramIdent), p("policy", paramIdent))},
+	"identity":      {rule("identity_attr", pKeyword("kind", idKindKeywords), p("issuer", paramHash))},
+	"swarm":         {rule("swarm_attr", p("plan", paramStr))},
+	"peer":          {rule("peer_attr", p("target", paramIdent), p("task", paramIdent))},
+	"flow_validate": {rule("flow_validate_attr", p("strict", paramBool))},
+
+	// 41-50: Native VM Extensions
+	"memory":   {rule("memory_attr", pKeyword("kind", memKindKeywords), pKeyword("index", memIndexKeywords), p("pointer", paramStr))},
+	"learn":    {rule("learn_attr", pQuoted("schedule", "daily", "cron"), pQuoted("from", "receipts"), pQuoted("method", "rlhf", "batch"))},
+	"compute":  {rule("compute_attr", pKeyword("gpu", gpuKeywords), p("hours", paramInt), pKeyword("provider", providerKeywords))},
+	"verifier": {rule("verifier_attr", pKeyword("role", verRoleKeywords), p("quorum", paramInt))},
+	"multisig": {rule("multisig_attr", p("quorum", paramInt), pList("signers", paramHash))},
+	"policy":   {rule("policy_attr", pQuoted("rule", "min_stake", "max_spend", "task_caps", "resource_caps", "hardware_safety", "resource_limits"))},
+	"council":  {rule("council_attr", p("quorum", paramInt), pKeyword("role", counRoleKeywords))},
+	"beats": {
+		rule("beats_attr", p("beat_ms", paramInt), p("microbatch", paramInt), p("prefill", paramInt), p("kv_warm_ms", paramInt)),
+		rule("beats_aligned_attr", p("aligned", paramBool)),
+	},
+	"maintenance": {rule("maintenance_attr", pQuoted("day", weekdays...))},
+	"cog":         {rule("cog_attr", pQuoted("memory", "ephemeral", "durable"))},
+	"npc":         {rule("npc_attr", p("wallet", paramStr))},
+
+	// 51-70: Entertainment Sector Extensions
+	"project":       {rule("project_attr", p("id", paramStr), p("sector", paramStr), p("budget", paramInt), pList("milestones", paramStr))},
+	"milestone":     {rule("milestone_attr", p("id", paramStr), p("amount", paramInt), p("due", paramStr), pList("deliverables", paramIdent))},
+	"casting":       {rule("casting_attr", p("id", paramStr), p("role", paramStr), p("dayRate", paramInt))},
+	"callsheet":     {rule("callsheet_attr", p("id", paramStr), p("projectId", paramStr), p("shootDate", paramStr), p("callTimes", paramIdent))},
+	"release":       {rule("release_attr", p("id", paramStr), p("kind", paramStr), p("fileHash", paramHash))},
+	"license":       {rule("license_attr", p("id", paramStr), p("workId", paramStr), pList("rights", paramStr), p("term", paramIdent))},
+	"royalty":       {rule("royalty_attr", p("id", paramStr), pList("triggers", paramStr), p("splits", paramIdent))},
+	"safety":        {rule("safety_attr", p("id", paramStr), p("items", paramIdent), p("signedByVisa", paramStr))},
+	"permit":        {rule("permit_attr", p("id", paramStr), p("authority", paramStr), p("validFrom", paramStr), p("validTo", paramStr))},
+	"delivery":      {rule("delivery_attr", p("id", paramStr), p("uri", paramStr), p("checksum", paramHash), p("state", paramStr))},
+	"compliance":    {rule("compliance_attr", p("id", paramStr), p("union", paramIdent), p("minorsPresent", paramBool))},
+	"asset_rental":  {rule("asset_rental_attr", p("id", paramStr), p("assetId", paramStr), p("projectId", paramStr), p("rate", paramInt))},
+	"asset_lineage": {rule("asset_lineage_attr", p("assetId", paramStr), pList("history", paramStr))},
+
+	// 71-90: Flow/Workflow
+	"flow":     {rule("flow_attr", p("id", paramStr))},
+	"retry":    {rule("retry_attr", pPositional("policy", paramStr))},
+	"saga":     {rule("saga_attr", p("compensate", paramStr))},
+	"ai":       {rule("ai_attr", p("policy", paramStr))},
+	"review":   {rule("review_attr", p("witness", paramInt))},
+	"oracle":   {rule("oracle_attr", pPositional("query", paramStr))},
+	"presence": {rule("presence_attr", p("nexus", paramStr))},
+
+	// 91-100: Interop/Anchoring
+	"import": {rule("import_attr", pQuoted("chain", "btc", "eth", "sol", "ar", "sui"), p("finality", paramStr))},
+	"abi":    {rule("abi_attr", p("cbor_deterministic", paramBool), p("version", paramStr))},
+	"bridge": {rule("bridge_attr", p("target", paramStr))},
+
+	// 101-110: Astral-Telluric Grid (beats_aligned_attr is listed under "beats")
+	"astro":    {rule("astro_attr", p("cycle", paramStr))},
+	"ley":      {rule("ley_attr", p("location", paramStr))},
+	"nexus":    {rule("nexus_attr", p("anchor", paramStr))},
+	"geofence": {rule("geofence_attr", p("bounds", paramStr))},
+	"boost":    {rule("boost_attr", p("multiplier", paramFloat))},
+
+	// 111-120: SatKey/Ordinals
+	"ordinal_guard": {rule("ordinal_guard_attr", p("sat", paramInt))},
+	"inscription":   {rule("inscription_attr", p("id", paramStr))},
+	"satname":       {rule("satname_attr", p("name", paramStr))},
+	"rights":        {rule("rights_attr", p("permissions", paramStr))},
+	"playback":      {rule("playback_attr", p("replay", paramBool))},
+	"psbt_policy":   {rule("psbt_policy_attr", p("rule", paramStr))},
+	"bundle":        {rule("bundle_attr", pList("rituals", paramStr))},
+	"manifest":      {rule("manifest_attr", p("metadata", paramStr))},
+
+	// 121-130: ZK & Numerology
+	"zk":     {rule("zk_attr", p("verify", paramStr), p("circuit", paramStr))},
+	"zkbind": {rule("zkbind_attr", p("ctx", paramBool))},
+	"veil":   {rule("veil_attr", p("constant", paramStr))},
+
+	// 131-143: Economic/Treasury
+	"tithe":          {rule("tithe_attr", p("rate", paramInt), p("wallet", paramIdent))},
+	"treasury_split": {rule("treasury_split_attr", p("shares", paramStr))},
+	"shrineSplit":    {rule("shrine_split_attr", p("distribution", paramStr))},
+	"fixed_math":     {rule("fixed_math_attr", p("precision", paramInt))},
+	"resource":       {rule("resource_attr", pQuoted("type", "linear", "acquire"))},
+	"burst":          {rule("burst_attr", p("threads", paramInt))},
+	"toc":            {rule("toc_attr", p("reward", paramInt), p("stake", paramInt), p("spend", paramInt))},
+
+	// 143+: Governance Extensions
+	"nonreentrant":   {{Rule: "nonreentrant_attr", Bare: true}},
+	"requires":       {rule("requires_attr", p("condition", paramStr))},
+	"ensures":        {rule("ensures_attr", p("postcondition", paramStr))},
+	"audit":          {rule("audit_attr", p("level", paramStr))},
+	"sabbath":        {rule("sabbath_attr", p("day", paramStr))},
+	"limits":         {rule("limits_attr", p("constraints", paramStr))},
+	"align":          {rule("align_attr", p("spec", paramStr))},
+	"temporal":       {rule("temporal_attr", p("rule", paramStr))},
+	"trusted_caller": {rule("trusted_caller_attr", p("address", paramHash))},
+	"sync_guard":     {{Rule: "sync_guard_attr", Bare: true}},
+	"gas_limit":      {rule("gas_limit_attr", p("max", paramInt))},
+	"whitegate":      {rule("whitegate_attr", p("quorum", paramInt))},
+	"oracle_bond":    {rule("oracle_bond_attr", p("amount", paramInt))},
+	"sector":         {rule("sector_attr", p("name", paramStr))},
+}
+
+func isOrisaKeyword(s string) bool {
+	return containsString(orisaKeywords, s)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
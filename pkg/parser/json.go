@@ -0,0 +1,737 @@
+// OSOVM JSON Front End
+// Parses JSON rituals into the same AST as the textual syntax, keeping
+// source positions so semantic errors point at the offending key or value
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+	"github.com/ase-lang/osovm/pkg/diag"
+)
+
+// ========== JSON Scanner ==========
+
+type jsonKind int
+
+const (
+	jsonNull jsonKind = iota
+	jsonBool
+	jsonNumber
+	jsonString
+	jsonArray
+	jsonObject
+)
+
+var jsonKindNames = map[jsonKind]string{
+	jsonNull:   "null",
+	jsonBool:   "boolean",
+	jsonNumber: "number",
+	jsonString: "string",
+	jsonArray:  "array",
+	jsonObject: "object",
+}
+
+func (k jsonKind) String() string {
+	return jsonKindNames[k]
+}
+
+type jsonMember struct {
+	Key     string
+	KeySpan ast.Span
+	Value   *jsonNode
+}
+
+type jsonNode struct {
+	Kind    jsonKind
+	Span    ast.Span
+	Text    string // string contents or number literal
+	Bool    bool
+	Items   []*jsonNode
+	Members []jsonMember
+}
+
+// jsonScanner is a small JSON reader that tracks line/column positions.
+// Unlike encoding/json it keeps member order and source spans.
+type jsonScanner struct {
+	src   string
+	pos   int
+	line  int
+	col   int
+	diags *diag.List
+}
+
+func (s *jsonScanner) here() ast.Pos {
+	return ast.Pos{Offset: s.pos, Line: s.line, Col: s.col}
+}
+
+func (s *jsonScanner) peek() byte {
+	if s.pos >= len(s.src) {
+		return 0
+	}
+	return s.src[s.pos]
+}
+
+func (s *jsonScanner) advance() {
+	r, size := utf8.DecodeRuneInString(s.src[s.pos:])
+	s.pos += size
+	if r == '\n' {
+		s.line++
+		s.col = 1
+	} else {
+		s.col++
+	}
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.src) {
+		switch s.peek() {
+		case ' ', '\t', '\r', '\n':
+			s.advance()
+		default:
+			if strings.HasPrefix(s.src[s.pos:], "\ufeff") {
+				s.advance()
+				continue
+			}
+			return
+		}
+	}
+}
+
+func (s *jsonScanner) fail(start ast.Pos, format string, args ...interface{}) *jsonNode {
+	end := s.here()
+	if end.Offset == start.Offset && s.pos < len(s.src) {
+		s.advance()
+		end = s.here()
+	}
+	s.diags.Errorf(ast.Span{Start: start, End: end}, format, args...)
+	return nil
+}
+
+func (s *jsonScanner) found() string {
+	if s.pos >= len(s.src) {
+		return "end of file"
+	}
+	r, _ := utf8.DecodeRuneInString(s.src[s.pos:])
+	return strconv.QuoteRune(r)
+}
+
+// parseValue reads one JSON value; nil means a syntax error was reported
+func (s *jsonScanner) parseValue() *jsonNode {
+	s.skipSpace()
+	start := s.here()
+
+	switch c := s.peek(); {
+	case c == '{':
+		return s.parseObject()
+	case c == '[':
+		return s.parseArray()
+	case c == '"':
+		text, ok := s.parseString()
+		if !ok {
+			return nil
+		}
+		return &jsonNode{Kind: jsonString, Text: text, Span: ast.Span{Start: start, End: s.here()}}
+	case c == '-' || (c >= '0' && c <= '9'):
+		return s.parseNumber()
+	case strings.HasPrefix(s.src[s.pos:], "true"), strings.HasPrefix(s.src[s.pos:], "false"):
+		value := c == 't'
+		n := len("false")
+		if value {
+			n = len("true")
+		}
+		for i := 0; i < n; i++ {
+			s.advance()
+		}
+		return &jsonNode{Kind: jsonBool, Bool: value, Span: ast.Span{Start: start, End: s.here()}}
+	case strings.HasPrefix(s.src[s.pos:], "null"):
+		for i := 0; i < len("null"); i++ {
+			s.advance()
+		}
+		return &jsonNode{Kind: jsonNull, Span: ast.Span{Start: start, End: s.here()}}
+	}
+	return s.fail(start, "expected JSON value, found %s", s.found())
+}
+
+func (s *jsonScanner) parseObject() *jsonNode {
+	node := &jsonNode{Kind: jsonObject, Span: ast.Span{Start: s.here()}}
+	s.advance() // '{'
+
+	s.skipSpace()
+	if s.peek() == '}' {
+		s.advance()
+		node.Span.End = s.here()
+		return node
+	}
+
+	for {
+		s.skipSpace()
+		keyStart := s.here()
+		if s.peek() != '"' {
+			return s.fail(keyStart, "expected object key, found %s", s.found())
+		}
+		key, ok := s.parseString()
+		if !ok {
+			return nil
+		}
+		keySpan := ast.Span{Start: keyStart, End: s.here()}
+
+		s.skipSpace()
+		if s.peek() != ':' {
+			return s.fail(s.here(), "expected ':' after object key, found %s", s.found())
+		}
+		s.advance()
+
+		value := s.parseValue()
+		if value == nil {
+			return nil
+		}
+		node.Members = append(node.Members, jsonMember{Key: key, KeySpan: keySpan, Value: value})
+
+		s.skipSpace()
+		switch s.peek() {
+		case ',':
+			s.advance()
+		case '}':
+			s.advance()
+			node.Span.End = s.here()
+			return node
+		default:
+			return s.fail(s.here(), "expected ',' or '}' in object, found %s", s.found())
+		}
+	}
+}
+
+func (s *jsonScanner) parseArray() *jsonNode {
+	node := &jsonNode{Kind: jsonArray, Span: ast.Span{Start: s.here()}}
+	s.advance() // '['
+
+	s.skipSpace()
+	if s.peek() == ']' {
+		s.advance()
+		node.Span.End = s.here()
+		return node
+	}
+
+	for {
+		item := s.parseValue()
+		if item == nil {
+			return nil
+		}
+		node.Items = append(node.Items, item)
+
+		s.skipSpace()
+		switch s.peek() {
+		case ',':
+			s.advance()
+		case ']':
+			s.advance()
+			node.Span.End = s.here()
+			return node
+		default:
+			return s.fail(s.here(), "expected ',' or ']' in array, found %s", s.found())
+		}
+	}
+}
+
+func (s *jsonScanner) parseString() (string, bool) {
+	start := s.here()
+	s.advance() // opening quote
+
+	var sb strings.Builder
+	for {
+		if s.pos >= len(s.src) || s.peek() == '\n' {
+			s.fail(start, "unterminated string")
+			return "", false
+		}
+		c := s.peek()
+		if c == '"' {
+			s.advance()
+			return sb.String(), true
+		}
+		if c != '\\' {
+			r, _ := utf8.DecodeRuneInString(s.src[s.pos:])
+			sb.WriteRune(r)
+			s.advance()
+			continue
+		}
+
+		escStart := s.here()
+		s.advance()
+		switch s.peek() {
+		case '"', '\\', '/':
+			sb.WriteByte(s.peek())
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'u':
+			if s.pos+5 > len(s.src) {
+				s.fail(escStart, "invalid unicode escape")
+				return "", false
+			}
+			code, err := strconv.ParseUint(s.src[s.pos+1:s.pos+5], 16, 32)
+			if err != nil {
+				s.fail(escStart, "invalid unicode escape")
+				return "", false
+			}
+			sb.WriteRune(rune(code))
+			for i := 0; i < 4; i++ {
+				s.advance()
+			}
+		default:
+			s.fail(escStart, "invalid escape sequence")
+			return "", false
+		}
+		s.advance()
+	}
+}
+
+func (s *jsonScanner) parseNumber() *jsonNode {
+	start := s.here()
+	for s.pos < len(s.src) && strings.IndexByte("+-0123456789.eE", s.peek()) >= 0 {
+		s.advance()
+	}
+	text := s.src[start.Offset:s.pos]
+	if _, err := strconv.ParseFloat(text, 64); err != nil {
+		s.diags.Errorf(ast.Span{Start: start, End: s.here()}, "malformed number %q", text)
+		return nil
+	}
+	return &jsonNode{Kind: jsonNumber, Text: text, Span: ast.Span{Start: start, End: s.here()}}
+}
+
+// ========== Ritual Builder ==========
+
+// parseJSONModule parses a single JSON ritual. Syntax errors stop at the
+// first problem; semantic errors are all reported.
+func parseJSONModule(filename string, src []byte, diags *diag.List) *ast.Module {
+	s := &jsonScanner{src: string(src), line: 1, col: 1, diags: diags}
+	mod := &ast.Module{File: filename, Format: ast.FormatJSON}
+
+	root := s.parseValue()
+	if root == nil {
+		return mod
+	}
+	s.skipSpace()
+	if s.pos < len(s.src) {
+		s.fail(s.here(), "unexpected %s after ritual object", s.found())
+		return mod
+	}
+	mod.Span = root.Span
+
+	b := &jsonBuilder{diags: diags}
+	if ritual := b.ritual(root); ritual != nil {
+		mod.Rituals = append(mod.Rituals, ritual)
+	}
+	return mod
+}
+
+type jsonBuilder struct {
+	diags *diag.List
+}
+
+// members indexes an object's members, reporting duplicate and unknown keys.
+// A nil known set accepts any key.
+func (b *jsonBuilder) members(node *jsonNode, what string, known ...string) map[string]jsonMember {
+	out := make(map[string]jsonMember, len(node.Members))
+	for _, m := range node.Members {
+		if _, dup := out[m.Key]; dup {
+			b.diags.Errorf(m.KeySpan, "duplicate key %q in %s", m.Key, what)
+			continue
+		}
+		if len(known) > 0 && !containsString(known, m.Key) {
+			b.diags.Errorf(m.KeySpan, "unknown key %q in %s (expected %s)", m.Key, what, strings.Join(known, ", "))
+			continue
+		}
+		out[m.Key] = m
+	}
+	return out
+}
+
+func (b *jsonBuilder) expectKind(node *jsonNode, kind jsonKind, what string) bool {
+	if node.Kind != kind {
+		b.diags.Errorf(node.Span, "%s must be a %s, found %s", what, kind, node.Kind)
+		return false
+	}
+	return true
+}
+
+func (b *jsonBuilder) ident(m jsonMember, what string) (ast.Ident, bool) {
+	if !b.expectKind(m.Value, jsonString, what) {
+		return ast.Ident{}, false
+	}
+	if m.Value.Text == "" {
+		b.diags.Errorf(m.Value.Span, "%s must not be empty", what)
+		return ast.Ident{}, false
+	}
+	return ast.Ident{Span: m.Value.Span, Name: m.Value.Text}, true
+}
+
+// ritualKeys are the top-level keys of a JSON ritual. "device" and
+// "delivery" are the Phase 2 top-level attributes.
+var ritualKeys = []string{"name", "orisa", "ase", "device", "delivery", "attributes", "args", "statements"}
+
+func (b *jsonBuilder) ritual(root *jsonNode) *ast.Ritual {
+	if !b.expectKind(root, jsonObject, "ritual") {
+		return nil
+	}
+	ritual := &ast.Ritual{Span: root.Span}
+	fields := b.members(root, "ritual", ritualKeys...)
+
+	if m, ok := fields["name"]; ok {
+		ritual.Name, _ = b.ident(m, `"name"`)
+	} else {
+		b.diags.Errorf(root.Span, `ritual is missing "name"`)
+	}
+
+	if m, ok := fields["orisa"]; ok {
+		if orisa, ok := b.ident(m, `"orisa"`); ok {
+			if !isOrisaKeyword(orisa.Name) {
+				b.diags.Errorf(orisa.Span, "unknown Òrìṣà %q (expected %s)", orisa.Name, strings.Join(orisaKeywords, ", "))
+			}
+			ritual.Orisa = orisa
+		}
+	} else {
+		b.diags.Errorf(root.Span, `ritual is missing "orisa"`)
+	}
+
+	// Top-level attributes (Phase 1 "ase", Phase 2 "device"/"delivery")
+	for _, key := range []string{"ase", "device", "delivery"} {
+		if m, ok := fields[key]; ok {
+			if attr := b.attribute(m); attr != nil {
+				ritual.Attributes = append(ritual.Attributes, attr)
+			}
+		}
+	}
+
+	if m, ok := fields["attributes"]; ok && b.expectKind(m.Value, jsonObject, `"attributes"`) {
+		for _, am := range m.Value.Members {
+			if attr := b.attribute(am); attr != nil {
+				if ritual.Attribute(attr.Name.Name) != nil {
+					b.diags.Errorf(am.KeySpan, "duplicate attribute %q", am.Key)
+					continue
+				}
+				ritual.Attributes = append(ritual.Attributes, attr)
+			}
+		}
+	}
+
+	if m, ok := fields["args"]; ok && b.expectKind(m.Value, jsonObject, `"args"`) {
+		for _, am := range b.membersInOrder(m.Value, `"args"`) {
+			value := b.value(am.Value)
+			if !value.IsScalar() {
+				b.diags.Errorf(am.Value.Span, "argument %q must be a scalar, found %s", am.Key, value.Kind)
+				continue
+			}
+			ritual.Args = append(ritual.Args, &ast.Arg{
+				Span:  ast.Span{Start: am.KeySpan.Start, End: am.Value.Span.End},
+				Name:  ast.Ident{Span: am.KeySpan, Name: am.Key},
+				Value: value,
+			})
+		}
+	}
+
+	if m, ok := fields["statements"]; ok && b.expectKind(m.Value, jsonArray, `"statements"`) {
+		for _, item := range m.Value.Items {
+			if stmt := b.statement(item); stmt != nil {
+				ritual.Body = append(ritual.Body, stmt)
+			}
+		}
+	}
+
+	return ritual
+}
+
+// membersInOrder returns an object's members in source order, dropping duplicates
+func (b *jsonBuilder) membersInOrder(node *jsonNode, what string) []jsonMember {
+	seen := make(map[string]bool, len(node.Members))
+	var out []jsonMember
+	for _, m := range node.Members {
+		if seen[m.Key] {
+			b.diags.Errorf(m.KeySpan, "duplicate key %q in %s", m.Key, what)
+			continue
+		}
+		seen[m.Key] = true
+		out = append(out, m)
+	}
+	return out
+}
+
+// attribute builds an attribute from a `"name": {params}` member
+func (b *jsonBuilder) attribute(m jsonMember) *ast.Attribute {
+	name := m.Key
+	if name == "ase" {
+		name = "àṣẹ"
+	}
+	if !b.expectKind(m.Value, jsonObject, "attribute "+strconv.Quote(m.Key)) {
+		return nil
+	}
+
+	attr := &ast.Attribute{
+		Span: ast.Span{Start: m.KeySpan.Start, End: m.Value.Span.End},
+		Name: ast.Ident{Span: m.KeySpan, Name: name},
+	}
+	for _, pm := range b.membersInOrder(m.Value, "attribute "+strconv.Quote(m.Key)) {
+		attr.Params = append(attr.Params, &ast.Arg{
+			Span:  ast.Span{Start: pm.KeySpan.Start, End: pm.Value.Span.End},
+			Name:  ast.Ident{Span: pm.KeySpan, Name: pm.Key},
+			Value: b.value(pm.Value),
+		})
+	}
+	return attr
+}
+
+var statementTypes = []string{"call", "assign", "return", "if", "repeat", "while"}
+
+func (b *jsonBuilder) statement(node *jsonNode) ast.Stmt {
+	if !b.expectKind(node, jsonObject, "statement") {
+		return nil
+	}
+	fields := b.members(node, "statement", "type", "data")
+
+	typeMember, ok := fields["type"]
+	if !ok {
+		b.diags.Errorf(node.Span, `statement is missing "type"`)
+		return nil
+	}
+	if !b.expectKind(typeMember.Value, jsonString, `statement "type"`) {
+		return nil
+	}
+
+	var data map[string]jsonMember
+	dataMember, hasData := fields["data"]
+	if hasData && b.expectKind(dataMember.Value, jsonObject, `statement "data"`) {
+		data = b.members(dataMember.Value, typeMember.Value.Text+" data", statementDataKeys[typeMember.Value.Text]...)
+	}
+
+	switch typeMember.Value.Text {
+	case "call":
+		m, ok := data["function"]
+		if !ok {
+			b.diags.Errorf(node.Span, `call statement is missing "function"`)
+			return nil
+		}
+		fn, ok := b.ident(m, `"function"`)
+		if !ok {
+			return nil
+		}
+		call := &ast.Call{Span: node.Span, Func: fn}
+		if am, ok := data["args"]; ok {
+			switch am.Value.Kind {
+			case jsonArray:
+				for _, item := range am.Value.Items {
+					call.Args = append(call.Args, &ast.Arg{Span: item.Span, Value: b.value(item)})
+				}
+			case jsonObject:
+				for _, pm := range b.membersInOrder(am.Value, `call "args"`) {
+					call.Args = append(call.Args, &ast.Arg{
+						Span:  ast.Span{Start: pm.KeySpan.Start, End: pm.Value.Span.End},
+						Name:  ast.Ident{Span: pm.KeySpan, Name: pm.Key},
+						Value: b.value(pm.Value),
+					})
+				}
+			default:
+				b.diags.Errorf(am.Value.Span, `call "args" must be an array or object, found %s`, am.Value.Kind)
+			}
+		}
+		if m, ok := data["result"]; ok {
+			if call.Result, ok = b.ident(m, `"result"`); !ok {
+				return nil
+			}
+		}
+		return call
+
+	case "assign":
+		m, ok := data["variable"]
+		if !ok {
+			b.diags.Errorf(node.Span, `assign statement is missing "variable"`)
+			return nil
+		}
+		variable, ok := b.ident(m, `"variable"`)
+		if !ok {
+			return nil
+		}
+		value, ok := b.valueOrExpr(data)
+		if !ok {
+			return nil
+		}
+		if value == nil {
+			b.diags.Errorf(node.Span, `assign statement is missing "value" or "expr"`)
+			return nil
+		}
+		return &ast.Assign{Span: node.Span, Variable: variable, Value: value}
+
+	case "return":
+		value, ok := b.valueOrExpr(data)
+		if !ok {
+			return nil
+		}
+		return &ast.Return{Span: node.Span, Value: value}
+
+	case "if":
+		m, ok := data["cond"]
+		if !ok {
+			b.diags.Errorf(node.Span, `if statement is missing "cond"`)
+			return nil
+		}
+		stmt := &ast.If{Span: node.Span, Cond: b.expr(m.Value)}
+		if m, ok := data["then"]; ok {
+			stmt.Then = b.block(m, `"then"`)
+		}
+		if m, ok := data["else"]; ok {
+			stmt.Else = b.block(m, `"else"`)
+		}
+		return stmt
+
+	case "repeat":
+		m, ok := data["times"]
+		if !ok {
+			b.diags.Errorf(node.Span, `repeat statement is missing "times"`)
+			return nil
+		}
+		loop := &ast.Loop{Span: node.Span, Count: b.expr(m.Value)}
+		if m, ok := data["var"]; ok {
+			loop.Var, _ = b.ident(m, `"var"`)
+		}
+		if m, ok := data["body"]; ok {
+			loop.Body = b.block(m, `"body"`)
+		}
+		return loop
+
+	case "while":
+		m, ok := data["cond"]
+		if !ok {
+			b.diags.Errorf(node.Span, `while statement is missing "cond"`)
+			return nil
+		}
+		loop := &ast.Loop{Span: node.Span, Cond: b.expr(m.Value)}
+		max, ok := data["max"]
+		if !ok {
+			b.diags.Errorf(node.Span, `while statement is missing "max" (loops must be bounded)`)
+			return nil
+		}
+		if v := b.value(max.Value); v.Kind != ast.ValueInt || v.Int <= 0 {
+			b.diags.Errorf(max.Value.Span, `"max" must be a positive integer`)
+		} else {
+			loop.Max = v.Int
+		}
+		if m, ok := data["body"]; ok {
+			loop.Body = b.block(m, `"body"`)
+		}
+		return loop
+	}
+
+	b.diags.Errorf(typeMember.Value.Span, "unknown statement type %q (expected %s)", typeMember.Value.Text, strings.Join(statementTypes, ", "))
+	return nil
+}
+
+// statementDataKeys lists the "data" keys accepted for each statement type
+var statementDataKeys = map[string][]string{
+	"call":   {"function", "args", "result"},
+	"assign": {"variable", "value", "expr"},
+	"return": {"value", "expr"},
+	"if":     {"cond", "then", "else"},
+	"repeat": {"times", "var", "body"},
+	"while":  {"cond", "max", "body"},
+}
+
+// block builds a nested statement list
+func (b *jsonBuilder) block(m jsonMember, what string) []ast.Stmt {
+	if !b.expectKind(m.Value, jsonArray, what) {
+		return nil
+	}
+	var body []ast.Stmt
+	for _, item := range m.Value.Items {
+		if stmt := b.statement(item); stmt != nil {
+			body = append(body, stmt)
+		}
+	}
+	return body
+}
+
+// valueOrExpr reads a literal "value" or an "expr" expression; both is an
+// error. It returns nil, true when neither is present.
+func (b *jsonBuilder) valueOrExpr(data map[string]jsonMember) (ast.Expr, bool) {
+	vm, hasValue := data["value"]
+	em, hasExpr := data["expr"]
+	switch {
+	case hasValue && hasExpr:
+		b.diags.Errorf(em.KeySpan, `"value" and "expr" are mutually exclusive`)
+		return nil, false
+	case hasValue:
+		value := b.value(vm.Value)
+		return &ast.Literal{Span: value.Span, Value: value}, true
+	case hasExpr:
+		if !b.expectKind(em.Value, jsonString, `"expr"`) {
+			return nil, false
+		}
+		expr := b.expr(em.Value)
+		return expr, expr != nil
+	}
+	return nil, true
+}
+
+// expr parses an expression string; numbers, booleans and null are literals
+func (b *jsonBuilder) expr(node *jsonNode) ast.Expr {
+	switch node.Kind {
+	case jsonString:
+		// The expression starts after the opening quote
+		base := node.Span.Start
+		base.Offset++
+		base.Col++
+		return ParseExpr(node.Text, base, b.diags)
+	case jsonNumber, jsonBool, jsonNull:
+		value := b.value(node)
+		return &ast.Literal{Span: value.Span, Value: value}
+	}
+	b.diags.Errorf(node.Span, "expression must be a string, number or boolean, found %s", node.Kind)
+	return nil
+}
+
+// value converts a JSON node into an AST value
+func (b *jsonBuilder) value(node *jsonNode) *ast.Value {
+	v := &ast.Value{Span: node.Span}
+	switch node.Kind {
+	case jsonNull:
+		v.Kind = ast.ValueNull
+	case jsonBool:
+		v.Kind, v.Bool = ast.ValueBool, node.Bool
+	case jsonString:
+		v.Kind, v.Str = ast.ValueString, node.Text
+	case jsonNumber:
+		if strings.ContainsAny(node.Text, ".eE") {
+			v.Kind = ast.ValueFloat
+			v.Float, _ = strconv.ParseFloat(node.Text, 64)
+		} else {
+			n, err := strconv.ParseInt(node.Text, 10, 64)
+			if err != nil {
+				b.diags.Errorf(node.Span, "integer %s out of range", node.Text)
+			}
+			v.Kind, v.Int = ast.ValueInt, n
+		}
+	case jsonArray:
+		v.Kind = ast.ValueList
+		for _, item := range node.Items {
+			v.List = append(v.List, b.value(item))
+		}
+	case jsonObject:
+		v.Kind = ast.ValueObject
+		for _, m := range b.membersInOrder(node, "object") {
+			v.Fields = append(v.Fields, &ast.Arg{
+				Span:  ast.Span{Start: m.KeySpan.Start, End: m.Value.Span.End},
+				Name:  ast.Ident{Span: m.KeySpan, Name: m.Key},
+				Value: b.value(m.Value),
+			})
+		}
+	}
+	return v
+}
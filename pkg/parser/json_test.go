@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ase-lang/osovm/pkg/diag"
+)
+
+func sortDiags(l *diag.List) {
+	sort.SliceStable(l.Diagnostics, func(i, j int) bool {
+		return l.Diagnostics[i].Span.Start.Offset < l.Diagnostics[j].Span.Start.Offset
+	})
+}
+
+func TestJSONReportsEveryErrorWithPositions(t *testing.T) {
+	_, diags := parse(`{
+  "name": "pay",
+  "orisa": "eshu_routr",
+  "args": {"amount": [1], "amount": 2},
+  "statements": [
+    {"type": "assign", "data": {"variable": "x"}},
+    {"type": "jump"},
+    {"type": "while", "data": {"cond": "x < 1", "max": 0}},
+    {"type": "assign", "data": {"variable": "y", "expr": "1 +"}}
+  ]
+}`)
+	want := []string{
+		`3:12 unknown Òrìṣà "eshu_routr"`,
+		`4:22 argument "amount" must be a scalar, found list`,
+		`4:27 duplicate key "amount" in "args"`,
+		`6:5 assign statement is missing "value" or "expr"`,
+		`7:14 unknown statement type "jump"`,
+		`8:56 "max" must be a positive integer`,
+		`9:62 expected expression, found end of file`,
+	}
+	sortDiags(diags)
+	if len(diags.Diagnostics) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d:\n%v", len(diags.Diagnostics), len(want), diags)
+	}
+	for i, d := range diags.Diagnostics {
+		got := d.Span.Start.String() + " " + d.Message
+		if !strings.HasPrefix(got, want[i]) {
+			t.Errorf("diagnostic %d: got %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestJSONSyntaxErrors(t *testing.T) {
+	for _, tc := range []struct {
+		src  string
+		pos  string
+		want string
+	}{
+		{`{"name": "a", "orisa": "eshu_router",}`, "1:38", "expected object key, found '}'"},
+		{`{"name": "a" "orisa": "eshu_router"}`, "1:14", "expected ',' or '}'"},
+		{"{\"name\": \"a\",\n \"orisa\": \"eshu_router\"} x", "2:26", "unexpected 'x' after ritual object"},
+		{`{"name": "a", "args": {"n": 1e999}}`, "1:29", "malformed number"},
+		{`{"orisa": "eshu_router"}`, "1:1", `ritual is missing "name"`},
+	} {
+		_, diags := parse(tc.src)
+		if !diags.HasErrors() {
+			t.Errorf("%s parsed", tc.src)
+			continue
+		}
+		sortDiags(diags)
+		d := diags.Diagnostics[0]
+		if d.Span.Start.String() != tc.pos || !strings.Contains(d.Message, tc.want) {
+			t.Errorf("%s: got %s: %s, want %s: %s", tc.src, d.Span.Start, d.Message, tc.pos, tc.want)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+// OSOVM Lexer
+// Tokenizes the textual Ọ̀ṢỌ́ syntax defined in grammar/ORISA_FULL.pest
+
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+	"github.com/ase-lang/osovm/pkg/diag"
+)
+
+// ========== Tokens ==========
+
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokAttr             // @name
+	tokIdent            // ident
+	tokInt              // int_lit
+	tokFloat            // float_lit
+	tokHex              // hex run starting with a digit (hash64 / addr32)
+	tokString           // "str_lit"
+	tokQuoted           // 'literal' (quoted keywords such as 'physical')
+	tokLParen
+	tokRParen
+	tokLBrace
+	tokRBrace
+	tokLBracket
+	tokRBracket
+	tokComma
+	tokColon
+	tokSemi
+	tokOp // expression operator: + - * / % == != < <= > >= && || !
+)
+
+var tokenNames = map[tokenKind]string{
+	tokEOF:      "end of file",
+	tokAttr:     "attribute",
+	tokIdent:    "identifier",
+	tokInt:      "integer",
+	tokFloat:    "float",
+	tokHex:      "hex literal",
+	tokString:   "string",
+	tokQuoted:   "quoted literal",
+	tokLParen:   "'('",
+	tokRParen:   "')'",
+	tokLBrace:   "'{'",
+	tokRBrace:   "'}'",
+	tokLBracket: "'['",
+	tokRBracket: "']'",
+	tokComma:    "','",
+	tokColon:    "':'",
+	tokSemi:     "';'",
+	tokOp:       "operator",
+}
+
+func (k tokenKind) String() string {
+	return tokenNames[k]
+}
+
+// token - A lexeme with its source span
+type token struct {
+	Kind tokenKind
+	Text string // Raw text; attribute names without '@', strings unquoted
+	Span ast.Span
+}
+
+func (t token) describe() string {
+	switch t.Kind {
+	case tokEOF:
+		return t.Kind.String()
+	case tokString:
+		return fmt.Sprintf("string %q", t.Text)
+	case tokAttr:
+		return "@" + t.Text
+	default:
+		return fmt.Sprintf("%q", t.Text)
+	}
+}
+
+// ========== Lexer ==========
+
+type lexer struct {
+	src   string
+	pos   int
+	line  int
+	col   int
+	prev  tokenKind // kind of the last token returned
+	diags *diag.List
+}
+
+// tokenize splits the whole source into tokens. Lexical errors are reported
+// to diags and the offending input is skipped, so parsing can continue.
+func tokenize(src string, diags *diag.List) []token {
+	lx := &lexer{src: src, line: 1, col: 1, diags: diags}
+	var tokens []token
+	for {
+		tok, ok := lx.next()
+		if !ok {
+			continue
+		}
+		tokens = append(tokens, tok)
+		lx.prev = tok.Kind
+		if tok.Kind == tokEOF {
+			return tokens
+		}
+	}
+}
+
+func (lx *lexer) here() ast.Pos {
+	return ast.Pos{Offset: lx.pos, Line: lx.line, Col: lx.col}
+}
+
+func (lx *lexer) peekRune() rune {
+	if lx.pos >= len(lx.src) {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(lx.src[lx.pos:])
+	return r
+}
+
+func (lx *lexer) advance() rune {
+	r, size := utf8.DecodeRuneInString(lx.src[lx.pos:])
+	lx.pos += size
+	if r == '\n' {
+		lx.line++
+		lx.col = 1
+	} else {
+		lx.col++
+	}
+	return r
+}
+
+func (lx *lexer) errorf(start ast.Pos, format string, args ...interface{}) {
+	lx.diags.Errorf(ast.Span{Start: start, End: lx.here()}, format, args...)
+}
+
+// skipTrivia consumes WHITESPACE and COMMENT
+func (lx *lexer) skipTrivia() {
+	for lx.pos < len(lx.src) {
+		r := lx.peekRune()
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\ufeff':
+			lx.advance()
+		case strings.HasPrefix(lx.src[lx.pos:], "//"):
+			for lx.pos < len(lx.src) && lx.peekRune() != '\n' {
+				lx.advance()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// next scans one token. ok is false when the input was skipped after an error.
+func (lx *lexer) next() (tok token, ok bool) {
+	lx.skipTrivia()
+
+	start := lx.here()
+	defer func() {
+		tok.Span = ast.Span{Start: start, End: lx.here()}
+	}()
+
+	if lx.pos >= len(lx.src) {
+		return token{Kind: tokEOF}, true
+	}
+
+	r := lx.peekRune()
+	if kind, isPunct := punctuation[r]; isPunct {
+		lx.advance()
+		return token{Kind: kind, Text: string(r)}, true
+	}
+
+	// A '-' directly before a digit signs an int_lit or float_lit, unless
+	// it follows a value, where it is subtraction (x-1)
+	if r == '-' && lx.pos+1 < len(lx.src) && isDigit(lx.src[lx.pos+1]) && !lx.prev.endsValue() {
+		lx.advance()
+		return lx.readWord(start)
+	}
+
+	if op := lx.readOperator(); op != "" {
+		return token{Kind: tokOp, Text: op}, true
+	}
+
+	switch {
+	case r == '@':
+		lx.advance()
+		nameStart := lx.pos
+		for lx.pos < len(lx.src) {
+			c := lx.peekRune()
+			if !(c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) || unicode.IsMark(c)) {
+				break
+			}
+			lx.advance()
+		}
+		if lx.pos == nameStart {
+			lx.errorf(start, "expected attribute name after '@'")
+			return token{}, false
+		}
+		return token{Kind: tokAttr, Text: lx.src[nameStart:lx.pos]}, true
+
+	case r == '"':
+		// An unterminated string is still returned so the parser sees a value
+		return token{Kind: tokString, Text: lx.readString(start)}, true
+
+	case r == '\'':
+		lx.advance()
+		textStart := lx.pos
+		for lx.pos < len(lx.src) && lx.peekRune() != '\'' && lx.peekRune() != '\n' {
+			lx.advance()
+		}
+		if lx.peekRune() != '\'' {
+			lx.errorf(start, "unterminated quoted literal")
+			return token{}, false
+		}
+		text := lx.src[textStart:lx.pos]
+		lx.advance()
+		return token{Kind: tokQuoted, Text: text}, true
+
+	case r < utf8.RuneSelf && isWordChar(byte(r)):
+		return lx.readWord(start)
+	}
+
+	lx.advance()
+	lx.errorf(start, "unexpected character %q", r)
+	return token{}, false
+}
+
+var punctuation = map[rune]tokenKind{
+	'(': tokLParen,
+	')': tokRParen,
+	'{': tokLBrace,
+	'}': tokRBrace,
+	'[': tokLBracket,
+	']': tokRBracket,
+	',': tokComma,
+	':': tokColon,
+	';': tokSemi,
+}
+
+// operators, longest first so "<=" wins over "<"
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!"}
+
+// readOperator consumes an expression operator, or returns ""
+func (lx *lexer) readOperator() string {
+	for _, op := range operators {
+		if strings.HasPrefix(lx.src[lx.pos:], op) {
+			for range op {
+				lx.advance()
+			}
+			return op
+		}
+	}
+	return ""
+}
+
+// readString reads a str_lit, resolving backslash escapes
+func (lx *lexer) readString(start ast.Pos) string {
+	lx.advance() // opening quote
+
+	var sb strings.Builder
+	for {
+		if lx.pos >= len(lx.src) || lx.peekRune() == '\n' {
+			lx.errorf(start, "unterminated string literal")
+			return sb.String()
+		}
+		r := lx.advance()
+		switch r {
+		case '"':
+			return sb.String()
+		case '\\':
+			if lx.pos >= len(lx.src) {
+				continue
+			}
+			esc := lx.advance()
+			switch esc {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			default:
+				sb.WriteRune(esc)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// readWord reads identifiers and numeric/hex literals. A signed literal
+// starts at the '-' the caller consumed.
+func (lx *lexer) readWord(start ast.Pos) (token, bool) {
+	for lx.pos < len(lx.src) && isWordChar(lx.src[lx.pos]) {
+		lx.advance()
+	}
+	word := lx.src[start.Offset:lx.pos]
+	digits := strings.TrimPrefix(word, "-")
+
+	if !isDigit(digits[0]) {
+		return token{Kind: tokIdent, Text: word}, true
+	}
+
+	if isAllDigits(digits) {
+		// float_lit = "-"? ~ ASCII_DIGIT+ ~ "." ~ ASCII_DIGIT+
+		if lx.peekRune() == '.' && lx.pos+1 < len(lx.src) && isDigit(lx.src[lx.pos+1]) {
+			lx.advance()
+			for lx.pos < len(lx.src) && isDigit(lx.src[lx.pos]) {
+				lx.advance()
+			}
+			return token{Kind: tokFloat, Text: lx.src[start.Offset:lx.pos]}, true
+		}
+		return token{Kind: tokInt, Text: word}, true
+	}
+
+	if isHexString(word) {
+		return token{Kind: tokHex, Text: word}, true
+	}
+
+	lx.errorf(start, "malformed literal %q", word)
+	return token{}, false
+}
+
+// endsValue reports whether a '-' after this token is subtraction
+func (k tokenKind) endsValue() bool {
+	switch k {
+	case tokIdent, tokInt, tokFloat, tokHex, tokString, tokQuoted, tokRParen, tokRBracket:
+		return true
+	}
+	return false
+}
+
+// ========== Character Classes ==========
+
+func isWordChar(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAllDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isHexString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,537 @@
+// OSOVM Parser
+// Recursive-descent parser for the textual Ọ̀ṢỌ́ syntax (grammar/ORISA_FULL.pest)
+//
+//   module      = ritual+
+//   ritual      = attribute* orisa_kw "(" args? ")" "{" ritual_call* "}"
+//   ritual_call = orisa_kw "(" args? ")" ";"
+//
+// Syntax errors are collected rather than returned: after each error the
+// parser resynchronises at the next attribute, ritual header or statement.
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+	"github.com/ase-lang/osovm/pkg/diag"
+)
+
+// ParseFile parses a .oso module in either the JSON ritual format or the
+// textual ORISA syntax, reporting every problem found to diags. The
+// returned module is always non-nil but may be partial when diags has errors.
+func ParseFile(filename string, src []byte, diags *diag.List) *ast.Module {
+	if IsJSON(src) {
+		return parseJSONModule(filename, src, diags)
+	}
+	return parseTextModule(filename, string(src), diags)
+}
+
+// IsJSON reports whether src uses the JSON ritual format
+func IsJSON(src []byte) bool {
+	trimmed := strings.TrimLeft(string(src), " \t\r\n\ufeff")
+	return strings.HasPrefix(trimmed, "{")
+}
+
+// ModuleName derives the module (and first ritual) name from a file path
+func ModuleName(filename string) string {
+	return strings.TrimSuffix(filepath.Base(filename), ".oso")
+}
+
+// errSync aborts the current production after a syntax error was reported
+var errSync = errors.New("syntax error")
+
+type parser struct {
+	tokens []token
+	pos    int
+	diags  *diag.List
+}
+
+// parseTextModule parses a textual module. Rituals carry no name in the text
+// syntax, so the first is named after the file and later ones get a numeric
+// suffix (name_2, name_3, ...).
+func parseTextModule(filename, src string, diags *diag.List) *ast.Module {
+	p := &parser{tokens: tokenize(src, diags), diags: diags}
+	name := ModuleName(filename)
+
+	mod := &ast.Module{File: filename, Format: ast.FormatText}
+	for p.peek().Kind != tokEOF {
+		before := p.pos
+		ritual := p.parseRitual()
+		if ritual != nil {
+			ritual.Name = ast.Ident{Span: ritual.Orisa.Span, Name: name}
+			if len(mod.Rituals) > 0 {
+				ritual.Name.Name = fmt.Sprintf("%s_%d", name, len(mod.Rituals)+1)
+			}
+			mod.Rituals = append(mod.Rituals, ritual)
+		}
+		if p.pos == before {
+			p.next() // guarantee progress
+		}
+	}
+
+	eof := p.peek()
+	mod.Span = ast.Span{End: eof.Span.End}
+	if len(mod.Rituals) == 0 && !diags.HasErrors() {
+		diags.Errorf(eof.Span, "expected at least one ritual")
+	}
+	return mod
+}
+
+// ========== Token Helpers ==========
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// prevEnd returns the end of the last consumed token
+func (p *parser) prevEnd() ast.Pos {
+	if p.pos == 0 {
+		return p.tokens[0].Span.Start
+	}
+	return p.tokens[p.pos-1].Span.End
+}
+
+func (p *parser) expect(kind tokenKind) (token, error) {
+	tok := p.peek()
+	if tok.Kind != kind {
+		return tok, p.errorf(tok, "expected %s, found %s", kind, tok.describe())
+	}
+	return p.next(), nil
+}
+
+func (p *parser) expectKeyword(word string) error {
+	tok := p.peek()
+	if tok.Kind != tokIdent || tok.Text != word {
+		return p.errorf(tok, "expected %q, found %s", word, tok.describe())
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	p.diags.Errorf(tok.Span, format, args...)
+	return errSync
+}
+
+// atRitualStart reports whether the next tokens begin an attribute or ritual header
+func (p *parser) atRitualStart() bool {
+	tok := p.peek()
+	if tok.Kind == tokAttr {
+		return true
+	}
+	return tok.Kind == tokIdent && isOrisaKeyword(tok.Text) && p.peekAt(1).Kind == tokLParen
+}
+
+// syncTo skips tokens until one of kinds (not consumed), a ritual start, or EOF
+func (p *parser) syncTo(kinds ...tokenKind) {
+	for {
+		tok := p.peek()
+		if tok.Kind == tokEOF || p.atRitualStart() {
+			return
+		}
+		for _, k := range kinds {
+			if tok.Kind == k {
+				return
+			}
+		}
+		p.next()
+	}
+}
+
+// ========== Rituals ==========
+
+func (p *parser) parseRitual() *ast.Ritual {
+	start := p.peek().Span.Start
+	ritual := &ast.Ritual{}
+
+	seenRules := make(map[string]bool)
+	for p.peek().Kind == tokAttr {
+		attr, err := p.parseAttribute()
+		if err != nil {
+			p.syncTo()
+			continue
+		}
+		if seenRules[attr.Rule] {
+			p.diags.Errorf(attr.Span, "duplicate attribute @%s", attr.Name.Name)
+		}
+		seenRules[attr.Rule] = true
+		ritual.Attributes = append(ritual.Attributes, attr)
+	}
+
+	orisa, err := p.parseOrisa()
+	if err != nil {
+		p.syncTo(tokLBrace)
+		if p.peek().Kind != tokLBrace {
+			return nil
+		}
+	} else {
+		ritual.Orisa = orisa
+		args, err := p.parseArgs()
+		ritual.Args = args
+		if err != nil {
+			p.syncTo(tokLBrace)
+		}
+	}
+
+	if _, err := p.expect(tokLBrace); err != nil {
+		p.syncTo()
+		ritual.Span = ast.Span{Start: start, End: p.prevEnd()}
+		return ritual
+	}
+	for {
+		tok := p.peek()
+		if tok.Kind == tokRBrace {
+			p.next()
+			break
+		}
+		if tok.Kind == tokEOF || tok.Kind == tokAttr {
+			p.errorf(tok, "expected %s to close ritual body, found %s", tokRBrace, tok.describe())
+			break
+		}
+		call, err := p.parseRitualCall()
+		if err != nil {
+			p.syncTo(tokSemi, tokRBrace)
+			if p.peek().Kind == tokSemi {
+				p.next()
+			}
+			continue
+		}
+		ritual.Body = append(ritual.Body, call)
+	}
+
+	ritual.Span = ast.Span{Start: start, End: p.prevEnd()}
+	if ritual.Orisa.Name == "" {
+		return nil
+	}
+	return ritual
+}
+
+func (p *parser) parseOrisa() (ast.Ident, error) {
+	tok := p.peek()
+	if tok.Kind != tokIdent || !isOrisaKeyword(tok.Text) {
+		return ast.Ident{}, p.errorf(tok, "expected Òrìṣà keyword (%s), found %s", strings.Join(orisaKeywords, ", "), tok.describe())
+	}
+	p.next()
+	return ast.Ident{Span: tok.Span, Name: tok.Text}, nil
+}
+
+// parseRitualCall parses `orisa_kw(args?);`
+func (p *parser) parseRitualCall() (*ast.Call, error) {
+	orisa, err := p.parseOrisa()
+	if err != nil {
+		return nil, err
+	}
+	args, err := p.parseArgs()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokSemi); err != nil {
+		return nil, err
+	}
+	return &ast.Call{
+		Span: ast.Span{Start: orisa.Span.Start, End: p.prevEnd()},
+		Func: orisa,
+		Args: args,
+	}, nil
+}
+
+// ========== Args ==========
+
+// parseArgs parses `"(" (arg ("," arg)*)? ")"`
+func (p *parser) parseArgs() ([]*ast.Arg, error) {
+	if _, err := p.expect(tokLParen); err != nil {
+		return nil, err
+	}
+
+	var args []*ast.Arg
+	seen := make(map[string]bool)
+	for p.peek().Kind != tokRParen {
+		if len(args) > 0 {
+			if tok := p.peek(); tok.Kind != tokComma {
+				return args, p.errorf(tok, "expected %s or %s, found %s", tokComma, tokRParen, tok.describe())
+			}
+			p.next()
+		}
+		name, err := p.expect(tokIdent)
+		if err != nil {
+			return args, err
+		}
+		if seen[name.Text] {
+			p.diags.Errorf(name.Span, "duplicate argument %q", name.Text)
+		}
+		seen[name.Text] = true
+		if _, err := p.expect(tokColon); err != nil {
+			return args, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return args, err
+		}
+		args = append(args, &ast.Arg{
+			Span:  ast.Span{Start: name.Span.Start, End: value.Span.End},
+			Name:  ast.Ident{Span: name.Span, Name: name.Text},
+			Value: value,
+		})
+	}
+	p.next() // ')'
+
+	return args, nil
+}
+
+// parseValue parses `str_lit | float_lit | int_lit | bool_lit | ident`
+func (p *parser) parseValue() (*ast.Value, error) {
+	tok := p.peek()
+	v := &ast.Value{Span: tok.Span}
+	switch tok.Kind {
+	case tokString:
+		v.Kind, v.Str = ast.ValueString, tok.Text
+	case tokFloat:
+		v.Kind = ast.ValueFloat
+		v.Float, _ = strconv.ParseFloat(tok.Text, 64)
+	case tokInt:
+		v.Kind = ast.ValueInt
+		v.Int = p.parseInt(tok)
+	case tokIdent:
+		switch tok.Text {
+		case "true", "false":
+			v.Kind, v.Bool = ast.ValueBool, tok.Text == "true"
+		default:
+			v.Kind, v.Str = ast.ValueIdent, tok.Text
+		}
+	default:
+		return nil, p.errorf(tok, "expected value, found %s", tok.describe())
+	}
+	p.next()
+	return v, nil
+}
+
+func (p *parser) parseInt(tok token) int64 {
+	n, err := strconv.ParseInt(tok.Text, 10, 64)
+	if err != nil {
+		p.diags.Errorf(tok.Span, "integer %s out of range", tok.Text)
+	}
+	return n
+}
+
+// ========== Attributes ==========
+
+func (p *parser) parseAttribute() (*ast.Attribute, error) {
+	tok := p.next()
+	rules, ok := attributeGrammar[tok.Text]
+	if !ok {
+		return nil, p.errorf(tok, "unknown attribute @%s", tok.Text)
+	}
+
+	// Try each alternative in order. Diagnostics from failed alternatives
+	// are discarded except for the one that got furthest.
+	start := p.pos
+	mark := len(p.diags.Diagnostics)
+	var best []*diag.Diagnostic
+	bestPos := -1
+	for _, r := range rules {
+		p.pos = start
+		params, err := p.parseAttrParams(r)
+		if err == nil {
+			p.diags.Diagnostics = p.diags.Diagnostics[:mark]
+			return &ast.Attribute{
+				Span:   ast.Span{Start: tok.Span.Start, End: p.prevEnd()},
+				Name:   ast.Ident{Span: tok.Span, Name: tok.Text},
+				Rule:   r.Rule,
+				Params: params,
+			}, nil
+		}
+		if p.pos > bestPos {
+			bestPos = p.pos
+			best = append([]*diag.Diagnostic(nil), p.diags.Diagnostics[mark:]...)
+		}
+		p.diags.Diagnostics = p.diags.Diagnostics[:mark]
+	}
+	p.diags.Diagnostics = append(p.diags.Diagnostics, best...)
+	p.pos = bestPos
+	return nil, errSync
+}
+
+func (p *parser) parseAttrParams(r attrRule) ([]*ast.Arg, error) {
+	if r.Bare {
+		return nil, nil
+	}
+
+	if _, err := p.expect(tokLParen); err != nil {
+		return nil, err
+	}
+
+	var params []*ast.Arg
+	if r.Unordered {
+		seen := make(map[string]bool)
+		for {
+			key, err := p.expect(tokIdent)
+			if err != nil {
+				return nil, err
+			}
+			spec, ok := findParam(r.Params, key.Text)
+			if !ok {
+				return nil, p.errorf(key, "unknown parameter %q for %s", key.Text, r.Rule)
+			}
+			if seen[key.Text] {
+				p.diags.Errorf(key.Span, "duplicate parameter %q", key.Text)
+			}
+			seen[key.Text] = true
+			if _, err := p.expect(tokColon); err != nil {
+				return nil, err
+			}
+			value, err := p.parseParamValue(spec)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, &ast.Arg{
+				Span:  ast.Span{Start: key.Span.Start, End: value.Span.End},
+				Name:  ast.Ident{Span: key.Span, Name: key.Text},
+				Value: value,
+			})
+			if p.peek().Kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	} else {
+		for i, spec := range r.Params {
+			if i > 0 {
+				if _, err := p.expect(tokComma); err != nil {
+					return nil, err
+				}
+			}
+			start := p.peek()
+			if spec.Key != "" {
+				if err := p.expectKeyword(spec.Key); err != nil {
+					return nil, err
+				}
+				if _, err := p.expect(tokColon); err != nil {
+					return nil, err
+				}
+			}
+			value, err := p.parseParamValue(spec)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, &ast.Arg{
+				Span:  ast.Span{Start: start.Span.Start, End: value.Span.End},
+				Name:  ast.Ident{Span: start.Span, Name: spec.field()},
+				Value: value,
+			})
+		}
+	}
+
+	if _, err := p.expect(tokRParen); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func findParam(specs []paramSpec, key string) (paramSpec, bool) {
+	for _, spec := range specs {
+		if spec.Key == key {
+			return spec, true
+		}
+	}
+	return paramSpec{}, false
+}
+
+func (p *parser) parseParamValue(spec paramSpec) (*ast.Value, error) {
+	if !spec.List {
+		return p.parseScalar(spec)
+	}
+
+	open, err := p.expect(tokLBracket)
+	if err != nil {
+		return nil, err
+	}
+	list := &ast.Value{Kind: ast.ValueList}
+	for {
+		item, err := p.parseScalar(spec)
+		if err != nil {
+			return nil, err
+		}
+		list.List = append(list.List, item)
+		if p.peek().Kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(tokRBracket); err != nil {
+		return nil, err
+	}
+	list.Span = ast.Span{Start: open.Span.Start, End: p.prevEnd()}
+	return list, nil
+}
+
+func (p *parser) parseScalar(spec paramSpec) (*ast.Value, error) {
+	tok := p.peek()
+	v := &ast.Value{Span: tok.Span}
+	matched := false
+
+	switch spec.Kind {
+	case paramInt:
+		if tok.Kind == tokInt {
+			v.Kind, v.Int, matched = ast.ValueInt, p.parseInt(tok), true
+		}
+	case paramFloat:
+		if tok.Kind == tokFloat {
+			v.Kind, matched = ast.ValueFloat, true
+			v.Float, _ = strconv.ParseFloat(tok.Text, 64)
+		}
+	case paramBool:
+		if tok.Kind == tokIdent && (tok.Text == "true" || tok.Text == "false") {
+			v.Kind, v.Bool, matched = ast.ValueBool, tok.Text == "true", true
+		}
+	case paramStr:
+		if tok.Kind == tokString {
+			v.Kind, v.Str, matched = ast.ValueString, tok.Text, true
+		}
+	case paramIdent:
+		if tok.Kind == tokIdent {
+			v.Kind, v.Str, matched = ast.ValueIdent, tok.Text, true
+		}
+	case paramHash:
+		if (tok.Kind == tokHex || tok.Kind == tokIdent) && len(tok.Text) == 64 && isHexString(tok.Text) {
+			v.Kind, v.Str, matched = ast.ValueString, strings.ToLower(tok.Text), true
+		}
+	case paramKeyword:
+		if tok.Kind == tokIdent && containsString(spec.Options, tok.Text) {
+			v.Kind, v.Str, matched = ast.ValueIdent, tok.Text, true
+		} else {
+			return nil, p.errorf(tok, "expected one of %s for %q, found %s", strings.Join(spec.Options, ", "), spec.field(), tok.describe())
+		}
+	case paramQuoted:
+		if tok.Kind == tokQuoted && containsString(spec.Options, tok.Text) {
+			v.Kind, v.Str, matched = ast.ValueString, tok.Text, true
+		} else {
+			return nil, p.errorf(tok, "expected one of '%s' for %q, found %s", strings.Join(spec.Options, "', '"), spec.field(), tok.describe())
+		}
+	}
+
+	if !matched {
+		return nil, p.errorf(tok, "expected %s for %q, found %s", spec.Kind, spec.field(), tok.describe())
+	}
+	p.next()
+	return v, nil
+}
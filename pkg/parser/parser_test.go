@@ -0,0 +1,271 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+	"github.com/ase-lang/osovm/pkg/diag"
+)
+
+func parse(src string) (*ast.Module, *diag.List) {
+	diags := diag.NewList("test.oso", []byte(src))
+	return ParseFile("test.oso", []byte(src), diags), diags
+}
+
+// args renders a ritual's args or an attribute's params as name=value
+func args(list []*ast.Arg) string {
+	out := make([]string, len(list))
+	for i, a := range list {
+		out[i] = a.Name.Name + "=" + a.Value.String()
+	}
+	return strings.Join(out, ", ")
+}
+
+func TestNegativeLiterals(t *testing.T) {
+	mod, diags := parse(`@slash(amount:-5, reason:"late")
+eshu_router(delta:-5, rate:-0.25, big:-9223372036854775808) {
+  obatala_guard(quorum:3);
+}`)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	r := mod.Rituals[0]
+	if got := args(r.Args); got != "delta=-5, rate=-0.25, big=-9223372036854775808" {
+		t.Errorf("args: %s", got)
+	}
+	if got := args(r.Attributes[0].Params); got != "amount=-5, reason=late" {
+		t.Errorf("@slash: %s", got)
+	}
+	if r.Args[0].Value.Kind != ast.ValueInt || r.Args[1].Value.Kind != ast.ValueFloat {
+		t.Errorf("kinds: %s, %s", r.Args[0].Value.Kind, r.Args[1].Value.Kind)
+	}
+	if span := r.Args[0].Value.Span; span.Start.String() != "2:19" || span.End.String() != "2:21" {
+		t.Errorf("-5 spans %s-%s", span.Start, span.End)
+	}
+}
+
+func TestMinusAfterValueIsSubtraction(t *testing.T) {
+	for src, want := range map[string]string{
+		"x-1":     "(x - 1)",
+		"x - 1":   "(x - 1)",
+		"f(x)-1":  "(f(x) - 1)",
+		"2 * -3":  "(2 * -3)",
+		"-x":      "(-x)",
+		"-2.5":    "-2.5",
+		"(1)-2":   "(1 - 2)",
+		"-1 - -1": "(-1 - -1)",
+	} {
+		diags := diag.NewList("expr", []byte(src))
+		x := ParseExpr(src, ast.Pos{Line: 1, Col: 1}, diags)
+		if diags.HasErrors() {
+			t.Errorf("%s: %v", src, diags)
+			continue
+		}
+		if got := render(x); got != want {
+			t.Errorf("%s parsed as %s, want %s", src, got, want)
+		}
+	}
+}
+
+func render(x ast.Expr) string {
+	switch x := x.(type) {
+	case *ast.Literal:
+		return x.Value.String()
+	case *ast.VarRef:
+		return x.Name
+	case *ast.Unary:
+		return "(" + x.Op + render(x.X) + ")"
+	case *ast.Binary:
+		return "(" + render(x.X) + " " + x.Op + " " + render(x.Y) + ")"
+	case *ast.FuncCall:
+		args := make([]string, len(x.Args))
+		for i, a := range x.Args {
+			args[i] = render(a)
+		}
+		return x.Func.Name + "(" + strings.Join(args, ", ") + ")"
+	}
+	return "?"
+}
+
+func TestSyntaxErrorPositions(t *testing.T) {
+	for _, tc := range []struct {
+		src  string
+		pos  string
+		want string
+	}{
+		{"eshu_router(delta:-) {}", "1:19", `expected value, found "-"`},
+		{"eshu_router() {\n  obatala_guard(quorum 3);\n}", "2:24", `expected ':', found "3"`},
+		{"@nope(1)\neshu_router() {}", "1:1", "unknown attribute @nope"},
+		{"@witness(\"3\")\neshu_router() {}", "1:10", `expected integer for "count", found string "3"`},
+		{"@witness(3)\n@witness(4)\neshu_router() {}", "2:1", "duplicate attribute @witness"},
+		{"eshu_router(to:\"alice) {}", "1:16", "unterminated string literal"},
+		{"eshu_router(a:1, a:2) {}", "1:18", `duplicate argument "a"`},
+		{"@compute(gpu:k80, hours:1, provider:vast)\neshu_router() {}", "1:14", "expected one of h100"},
+		{"@àṣẹ(proof:qr, witnesses:2, bogus:1)\neshu_router() {}", "1:29", `unknown parameter "bogus"`},
+		{"eshu_router() {\n  obatala_guard();", "2:19", "expected '}' to close ritual body"},
+		{"eshu_router(x:1.5.2) {}", "1:18", "unexpected character '.'"},
+		{"// nothing here\n", "2:1", "expected at least one ritual"},
+	} {
+		_, diags := parse(tc.src)
+		if !diags.HasErrors() {
+			t.Errorf("%q parsed", tc.src)
+			continue
+		}
+		d := diags.Diagnostics[0]
+		if d.Span.Start.String() != tc.pos || !strings.Contains(d.Message, tc.want) {
+			t.Errorf("%q: got %s: %s, want %s: %s", tc.src, d.Span.Start, d.Message, tc.pos, tc.want)
+		}
+	}
+}
+
+func TestRecoveryReportsEveryError(t *testing.T) {
+	mod, diags := parse(`@witness(x)
+eshu_router(action:delivery) {
+  obatala_guard(quorum 3);
+  sango_vault();
+  oya_witness(;
+}
+
+@sim(episode_id:1, steps:10)
+ogun_forge() {
+  yemoja_cache();
+}`)
+	var got []string
+	for _, d := range diags.Diagnostics {
+		got = append(got, d.Span.Start.String())
+	}
+	if want := "1:10 3:24 5:15"; strings.Join(got, " ") != want {
+		t.Errorf("errors at %s, want %s\n%v", strings.Join(got, " "), want, diags)
+	}
+
+	// Both rituals survive, keeping the statements that parsed
+	if len(mod.Rituals) != 2 {
+		t.Fatalf("%d rituals", len(mod.Rituals))
+	}
+	first, second := mod.Rituals[0], mod.Rituals[1]
+	if first.Name.Name != "test" || len(first.Body) != 1 || len(first.Attributes) != 0 {
+		t.Errorf("first ritual: %s, %d statements, %d attributes", first.Name.Name, len(first.Body), len(first.Attributes))
+	}
+	if second.Name.Name != "test_2" || second.Orisa.Name != "ogun_forge" || len(second.Body) != 1 || second.Attributes[0].Rule != "sim_attr" {
+		t.Errorf("second ritual: %+v", second)
+	}
+
+	// Rendering sorts by position and counts the errors
+	if out := diags.Error(); !strings.HasPrefix(out, "test.oso:1:10: error:") || !strings.HasSuffix(out, "3 errors") {
+		t.Errorf("rendered:\n%s", out)
+	}
+}
+
+// sample writes a value that satisfies spec
+func sample(spec paramSpec) string {
+	var v string
+	switch spec.Kind {
+	case paramInt:
+		v = "-7"
+	case paramFloat:
+		v = "0.5"
+	case paramBool:
+		v = "true"
+	case paramStr:
+		v = `"s"`
+	case paramIdent:
+		v = "x"
+	case paramHash:
+		v = strings.Repeat("Ab", 32)
+	case paramKeyword:
+		v = spec.Options[0]
+	case paramQuoted:
+		v = "'" + spec.Options[0] + "'"
+	}
+	if spec.List {
+		return "[" + v + ", " + v + "]"
+	}
+	return v
+}
+
+func TestEveryAttributeRuleParses(t *testing.T) {
+	names := make([]string, 0, len(attributeGrammar))
+	for name := range attributeGrammar {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, r := range attributeGrammar[name] {
+			src := "@" + name
+			if !r.Bare {
+				params := make([]string, len(r.Params))
+				for i, spec := range r.Params {
+					params[i] = sample(spec)
+					if spec.Key != "" {
+						params[i] = spec.Key + ":" + params[i]
+					}
+				}
+				// Unordered parameters are written back to front
+				if r.Unordered {
+					for i, j := 0, len(params)-1; i < j; i, j = i+1, j-1 {
+						params[i], params[j] = params[j], params[i]
+					}
+				}
+				src += "(" + strings.Join(params, ", ") + ")"
+			}
+
+			mod, diags := parse(src + "\neshu_router() {}")
+			if diags.HasErrors() {
+				t.Errorf("%s: %v", src, diags)
+				continue
+			}
+			attr := mod.Rituals[0].Attributes[0]
+			if attr.Rule != r.Rule || len(attr.Params) != len(r.Params) {
+				t.Errorf("%s matched %s with %d params, want %s with %d", src, attr.Rule, len(attr.Params), r.Rule, len(r.Params))
+			}
+			for _, spec := range r.Params {
+				param := attr.Param(spec.field())
+				if param == nil {
+					t.Errorf("%s: no %s param", src, spec.field())
+				} else if spec.Kind == paramHash && !spec.List && param.Value.Str != strings.Repeat("ab", 32) {
+					t.Errorf("%s: hash not lowercased: %s", src, param.Value.Str)
+				}
+			}
+		}
+	}
+}
+
+func TestAttributeShapes(t *testing.T) {
+	for _, tc := range []struct {
+		src    string
+		rule   string
+		params string
+	}{
+		{"@witness(3)", "witness_attr", "count=3"},
+		{"@sim(episode_id:1, steps:100)", "sim_attr", "episode_id=1, steps=100"},
+		{"@àṣẹ(witnesses:2, proof:qr)", "ase_attr", "witnesses=2, proof=qr"},
+		{"@vote(weights:[0.5, -0.25], quorum:2)", "vote_attr", "weights=[0.5, -0.25], quorum=2"},
+		{"@job(type:'physical', title:\"move\", budget:10, requires:drone)", "job_attr", "type=physical, title=move, budget=10, requires=drone"},
+		{"@beats(aligned:true)", "beats_aligned_attr", "aligned=true"},
+		{"@beats(beat_ms:1, microbatch:2, prefill:3, kv_warm_ms:4)", "beats_attr", "beat_ms=1, microbatch=2, prefill=3, kv_warm_ms=4"},
+		{"@nonreentrant", "nonreentrant_attr", ""},
+	} {
+		mod, diags := parse(tc.src + "\neshu_router() {}")
+		if diags.HasErrors() {
+			t.Errorf("%s: %v", tc.src, diags)
+			continue
+		}
+		attr := mod.Rituals[0].Attributes[0]
+		if got := args(attr.Params); attr.Rule != tc.rule || got != tc.params {
+			t.Errorf("%s: %s(%s), want %s(%s)", tc.src, attr.Rule, got, tc.rule, tc.params)
+		}
+		if end := attr.Span.End.Col; end != len([]rune(tc.src))+1 {
+			t.Errorf("%s: span ends at column %d", tc.src, end)
+		}
+	}
+
+	// A failed alternative reports the error of the one that got furthest
+	_, diags := parse("@beats(beat_ms:1, microbatch:x)\neshu_router() {}")
+	if d := diags.Diagnostics; len(d) != 1 || !strings.Contains(d[0].Message, fmt.Sprintf("expected integer for %q", "microbatch")) {
+		t.Errorf("@beats: %v", diags)
+	}
+}
@@ -0,0 +1,181 @@
+// OSOVM Proof Kinds
+// Canonical registry of proof kinds: names, aliases, hierarchy, receipt
+// schemas and verifiers. The parser, Àṣẹ validation and @àṣẹ/@proof
+// attribute checks all resolve kinds here.
+
+package proofkind
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Schema - What a receipt of this kind commits to
+type Schema struct {
+	Digest string   // receipt digest algorithm
+	Size   int      // digest length in bytes
+	Fields []string // payload fields the digest covers, in canonical order
+
+	payload reflect.Type // payload struct, decoded by Decode
+	index   []int        // struct field index of each entry in Fields
+}
+
+// Verifier checks a receipt against its kind's schema
+type Verifier func(k *Kind, receipt string) error
+
+// Kind - One proof kind. A kind satisfies its Parent, transitively, so a
+// gps proof meets a telemetry requirement.
+type Kind struct {
+	Name    string
+	Parent  string
+	Aliases []string
+	Schema  Schema
+	Verify  Verifier
+	Encode  func(p Payload) []byte // overrides the canonical encoding
+}
+
+// ========== Schemas ==========
+
+// sha256Schema describes a SHA-256 receipt over the payload struct proto;
+// its fields are the struct's json names in declaration order
+func sha256Schema(proto Payload) Schema {
+	t := reflect.TypeOf(proto).Elem()
+	schema := Schema{Digest: "sha256", Size: sha256.Size, payload: t}
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		schema.Fields = append(schema.Fields, name)
+		schema.index = append(schema.index, i)
+	}
+	return schema
+}
+
+var (
+	telemetrySchema = sha256Schema(&Telemetry{})
+	sensorSchema    = sha256Schema(&SensorReading{})
+	rangeSchema     = sha256Schema(&Range{})
+	mediaSchema     = sha256Schema(&Media{})
+	tagSchema       = sha256Schema(&Tag{})
+	beaconSchema    = sha256Schema(&Beacon{})
+)
+
+// ========== Registry ==========
+
+// kinds in registration order: the ORISA_FULL.pest proof_kw set, then the
+// Phase 1 kinds
+var kinds = []*Kind{
+	{Name: "telemetry", Schema: telemetrySchema},
+	{Name: "qr", Aliases: []string{"qr_scan"}, Schema: sha256Schema(&QRCode{}), Encode: encodeQR},
+	{Name: "gps", Parent: "telemetry", Schema: sha256Schema(&GPSFix{})},
+	{Name: "imu", Parent: "telemetry", Schema: sha256Schema(&IMUSamples{})},
+	{Name: "video", Schema: mediaSchema},
+	{Name: "audio", Schema: mediaSchema},
+	{Name: "weight", Parent: "sensor", Schema: sensorSchema},
+	{Name: "temp", Parent: "sensor", Schema: sensorSchema},
+	{Name: "humidity", Parent: "sensor", Schema: sensorSchema},
+	{Name: "pressure", Parent: "sensor", Schema: sensorSchema},
+	{Name: "light", Parent: "sensor", Schema: sensorSchema},
+	{Name: "motion", Parent: "imu", Schema: sha256Schema(&Motion{})},
+	{Name: "biometric", Schema: sha256Schema(&Biometric{})},
+	{Name: "nfc", Schema: tagSchema},
+	{Name: "rfid", Schema: tagSchema},
+	{Name: "ble", Schema: beaconSchema},
+	{Name: "wifi", Schema: beaconSchema},
+	{Name: "lidar", Parent: "telemetry", Schema: rangeSchema},
+	{Name: "radar", Parent: "telemetry", Schema: rangeSchema},
+	{Name: "ultrasonic", Parent: "telemetry", Schema: rangeSchema},
+	{Name: "camera", Parent: "video", Schema: mediaSchema},
+	{Name: "microphone", Parent: "audio", Schema: mediaSchema},
+
+	// Phase 1 (grammar/ORISA.pest and the ProofType enum)
+	{Name: "sensor", Parent: "telemetry", Schema: sensorSchema},
+	{Name: "cognition", Schema: sha256Schema(&Cognition{})},
+	{Name: "hardware", Schema: sha256Schema(&Hardware{})},
+}
+
+var byName = index(kinds)
+
+func index(list []*Kind) map[string]*Kind {
+	m := make(map[string]*Kind)
+	for _, k := range list {
+		if k.Verify == nil {
+			k.Verify = verifyDigest
+		}
+		for _, name := range append([]string{k.Name}, k.Aliases...) {
+			if _, dup := m[name]; dup {
+				panic("proofkind: duplicate kind " + name)
+			}
+			m[name] = k
+		}
+	}
+	for _, k := range list {
+		if k.Parent != "" && m[k.Parent] == nil {
+			panic("proofkind: " + k.Name + " has unknown parent " + k.Parent)
+		}
+	}
+	return m
+}
+
+// Lookup returns the kind registered under name or one of its aliases
+func Lookup(name string) (*Kind, bool) {
+	k, ok := byName[name]
+	return k, ok
+}
+
+// All returns every kind in registration order
+func All() []*Kind {
+	return kinds
+}
+
+// Names returns every accepted kind name, aliases included
+func Names() []string {
+	var names []string
+	for _, k := range kinds {
+		names = append(names, k.Name)
+		names = append(names, k.Aliases...)
+	}
+	return names
+}
+
+// Known reports whether name is a registered kind or alias
+func Known(name string) bool {
+	_, ok := byName[name]
+	return ok
+}
+
+// Satisfies reports whether a proof of kind have meets a requirement for
+// kind want: the same kind (aliases included) or one of its descendants
+func Satisfies(have, want string) bool {
+	target, ok := byName[want]
+	if !ok {
+		return false
+	}
+	for k, ok := byName[have]; ok; k, ok = byName[k.Parent] {
+		if k == target {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyReceipt checks receipt against the schema of kind name
+func VerifyReceipt(name, receipt string) error {
+	k, ok := byName[name]
+	if !ok {
+		return fmt.Errorf("unknown proof kind: %s", name)
+	}
+	return k.Verify(k, receipt)
+}
+
+// ========== Verifiers ==========
+
+// verifyDigest checks the receipt is a hex digest of the schema's size
+func verifyDigest(k *Kind, receipt string) error {
+	raw, err := hex.DecodeString(receipt)
+	if err != nil || len(raw) != k.Schema.Size {
+		return fmt.Errorf("%s receipt must be a %d-character hex %s digest", k.Name, 2*k.Schema.Size, k.Schema.Digest)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package proofkind
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSatisfiesFollowsTheHierarchy(t *testing.T) {
+	for _, tc := range []struct {
+		have, want string
+		ok         bool
+	}{
+		{"telemetry", "telemetry", true},
+		{"gps", "telemetry", true},
+		{"motion", "telemetry", true}, // motion → imu → telemetry
+		{"weight", "sensor", true},
+		{"weight", "telemetry", true}, // weight → sensor → telemetry
+		{"camera", "video", true},
+		{"qr_scan", "qr", true},
+		{"qr", "qr_scan", true},
+		{"telemetry", "gps", false}, // a parent does not satisfy its child
+		{"video", "audio", false},
+		{"camera", "microphone", false},
+		{"bogus", "telemetry", false},
+		{"telemetry", "bogus", false},
+	} {
+		if got := Satisfies(tc.have, tc.want); got != tc.ok {
+			t.Errorf("Satisfies(%s, %s) = %v", tc.have, tc.want, got)
+		}
+	}
+}
+
+func TestEveryKindIsWellFormed(t *testing.T) {
+	for _, k := range All() {
+		// The parent chain ends at a root
+		seen := map[*Kind]bool{}
+		for p := k; p.Parent != ""; p, _ = Lookup(p.Parent) {
+			if seen[p] {
+				t.Fatalf("%s: parent cycle", k.Name)
+			}
+			seen[p] = true
+		}
+
+		if got, ok := Lookup(k.Name); !ok || got != k {
+			t.Errorf("%s: lookup returned %v", k.Name, got)
+		}
+		for _, alias := range k.Aliases {
+			if got, _ := Lookup(alias); got != k {
+				t.Errorf("%s: alias %s resolves to %v", k.Name, alias, got)
+			}
+		}
+		if len(k.Schema.Fields) == 0 || len(k.Schema.Fields) != len(k.Schema.index) {
+			t.Errorf("%s: schema fields %v", k.Name, k.Schema.Fields)
+		}
+	}
+
+	if n := len(Names()); n != len(All())+1 {
+		t.Errorf("%d names for %d kinds and one alias", n, len(All()))
+	}
+}
+
+func TestVerifyReceipt(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	for _, tc := range []struct {
+		kind, receipt string
+		err           string
+	}{
+		{"telemetry", digest, ""},
+		{"qr_scan", strings.ToUpper(digest), ""},
+		{"telemetry", digest[:62], "must be a 64-character hex sha256 digest"},
+		{"telemetry", digest + "ab", "must be a 64-character hex sha256 digest"},
+		{"gps", strings.Repeat("zz", 32), "gps receipt must be"},
+		{"bogus", digest, "unknown proof kind: bogus"},
+	} {
+		err := VerifyReceipt(tc.kind, tc.receipt)
+		if tc.err == "" && err != nil {
+			t.Errorf("%s %s: %v", tc.kind, tc.receipt, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%s %s: got %v, want %q", tc.kind, tc.receipt, err, tc.err)
+		}
+	}
+}
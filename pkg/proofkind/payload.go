@@ -0,0 +1,356 @@
+// OSOVM Proof Payloads
+// Per-kind payload decoders and the canonical encoding receipts are
+// recomputed from
+
+package proofkind
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/ase-lang/osovm/internal/tagged"
+)
+
+// Payload - The raw data behind a proof receipt, decoded for its kind
+type Payload interface {
+	Validate() error
+}
+
+// payloadDomain prefixes every canonical encoding
+const payloadDomain = "osovm/proof-payload/v1"
+
+// ========== Payload Types ==========
+
+// Telemetry - Named readings from one device
+type Telemetry struct {
+	DeviceID  string             `json:"device_id"`
+	Timestamp int64              `json:"timestamp"`
+	Readings  map[string]float64 `json:"readings"`
+}
+
+// GPSFix - A position fix
+type GPSFix struct {
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	Alt       float64 `json:"alt"`
+	AccuracyM float64 `json:"accuracy_m"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// IMUSample - One accelerometer (m/s²) and gyroscope (rad/s) sample
+type IMUSample struct {
+	Accel [3]float64 `json:"accel"`
+	Gyro  [3]float64 `json:"gyro"`
+}
+
+// IMUSamples - A burst of IMU samples
+type IMUSamples struct {
+	Samples   []IMUSample `json:"samples"`
+	RateHz    float64     `json:"rate_hz"`
+	Timestamp int64       `json:"timestamp"`
+}
+
+// Motion - A motion event
+type Motion struct {
+	Magnitude float64 `json:"magnitude"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// SensorReading - One scalar sensor value (weight, temp, humidity, ...)
+type SensorReading struct {
+	Sensor    string  `json:"sensor"`
+	Value     float64 `json:"value"`
+	Unit      string  `json:"unit"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// Range - A lidar, radar or ultrasonic distance measurement
+type Range struct {
+	RangeM     float64 `json:"range_m"`
+	BearingDeg float64 `json:"bearing_deg"`
+	Timestamp  int64   `json:"timestamp"`
+}
+
+// Media - A video or audio capture, referenced by its content hash
+type Media struct {
+	MediaType     string `json:"media_type"`
+	ContentSHA256 string `json:"content_sha256"`
+	DurationMS    int64  `json:"duration_ms"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+// QRCode - The decoded text of a QR scan
+type QRCode struct {
+	RawData string `json:"raw_data"`
+}
+
+// Biometric - A biometric match against an enrolled template
+type Biometric struct {
+	TemplateSHA256 string  `json:"template_sha256"`
+	MatchScore     float64 `json:"match_score"`
+	Timestamp      int64   `json:"timestamp"`
+}
+
+// Tag - An NFC or RFID tag read
+type Tag struct {
+	TagID     string `json:"tag_id"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Beacon - A BLE or Wi-Fi beacon sighting
+type Beacon struct {
+	BeaconID  string `json:"beacon_id"`
+	RSSI      int    `json:"rssi"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Cognition - An AI decision: model plus input and output hashes
+type Cognition struct {
+	Model        string `json:"model"`
+	InputSHA256  string `json:"input_sha256"`
+	OutputSHA256 string `json:"output_sha256"`
+}
+
+// Hardware - A device state attestation
+type Hardware struct {
+	DeviceID    string `json:"device_id"`
+	Attestation string `json:"attestation"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// ========== Validation ==========
+
+func (p *Telemetry) Validate() error {
+	if p.DeviceID == "" {
+		return fmt.Errorf("device_id is required")
+	}
+	if len(p.Readings) == 0 {
+		return fmt.Errorf("readings cannot be empty")
+	}
+	return checkTimestamp(p.Timestamp)
+}
+
+func (p *GPSFix) Validate() error {
+	if p.Lat < -90 || p.Lat > 90 {
+		return fmt.Errorf("lat must be within [-90, 90]")
+	}
+	if p.Lon < -180 || p.Lon > 180 {
+		return fmt.Errorf("lon must be within [-180, 180]")
+	}
+	if p.AccuracyM < 0 {
+		return fmt.Errorf("accuracy_m cannot be negative")
+	}
+	return checkTimestamp(p.Timestamp)
+}
+
+func (p *IMUSamples) Validate() error {
+	if len(p.Samples) == 0 {
+		return fmt.Errorf("samples cannot be empty")
+	}
+	if p.RateHz <= 0 {
+		return fmt.Errorf("rate_hz must be positive")
+	}
+	return checkTimestamp(p.Timestamp)
+}
+
+func (p *Motion) Validate() error {
+	if p.Magnitude < 0 {
+		return fmt.Errorf("magnitude cannot be negative")
+	}
+	return checkTimestamp(p.Timestamp)
+}
+
+func (p *SensorReading) Validate() error {
+	if p.Sensor == "" || p.Unit == "" {
+		return fmt.Errorf("sensor and unit are required")
+	}
+	return checkTimestamp(p.Timestamp)
+}
+
+func (p *Range) Validate() error {
+	if p.RangeM < 0 {
+		return fmt.Errorf("range_m cannot be negative")
+	}
+	if p.BearingDeg < 0 || p.BearingDeg >= 360 {
+		return fmt.Errorf("bearing_deg must be within [0, 360)")
+	}
+	return checkTimestamp(p.Timestamp)
+}
+
+func (p *Media) Validate() error {
+	if p.MediaType == "" {
+		return fmt.Errorf("media_type is required")
+	}
+	if err := checkSHA256("content_sha256", p.ContentSHA256); err != nil {
+		return err
+	}
+	if p.DurationMS < 0 {
+		return fmt.Errorf("duration_ms cannot be negative")
+	}
+	return checkTimestamp(p.Timestamp)
+}
+
+func (p *QRCode) Validate() error {
+	if p.RawData == "" {
+		return fmt.Errorf("raw_data is required")
+	}
+	return nil
+}
+
+func (p *Biometric) Validate() error {
+	if err := checkSHA256("template_sha256", p.TemplateSHA256); err != nil {
+		return err
+	}
+	if p.MatchScore < 0 || p.MatchScore > 1 {
+		return fmt.Errorf("match_score must be within [0, 1]")
+	}
+	return checkTimestamp(p.Timestamp)
+}
+
+func (p *Tag) Validate() error {
+	if p.TagID == "" {
+		return fmt.Errorf("tag_id is required")
+	}
+	return checkTimestamp(p.Timestamp)
+}
+
+func (p *Beacon) Validate() error {
+	if p.BeaconID == "" {
+		return fmt.Errorf("beacon_id is required")
+	}
+	return checkTimestamp(p.Timestamp)
+}
+
+func (p *Cognition) Validate() error {
+	if p.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if err := checkSHA256("input_sha256", p.InputSHA256); err != nil {
+		return err
+	}
+	return checkSHA256("output_sha256", p.OutputSHA256)
+}
+
+func (p *Hardware) Validate() error {
+	if p.DeviceID == "" || p.Attestation == "" {
+		return fmt.Errorf("device_id and attestation are required")
+	}
+	return checkTimestamp(p.Timestamp)
+}
+
+func checkTimestamp(ts int64) error {
+	if ts <= 0 {
+		return fmt.Errorf("timestamp is required")
+	}
+	return nil
+}
+
+func checkSHA256(field, value string) error {
+	raw, err := hex.DecodeString(value)
+	if err != nil || len(raw) != sha256.Size {
+		return fmt.Errorf("%s must be a 64-character hex hash", field)
+	}
+	return nil
+}
+
+// ========== Decoding & Encoding ==========
+
+// Decode decodes raw as a payload of kind name. Unknown fields and
+// trailing data are rejected, then the payload is validated.
+func Decode(name string, raw []byte) (Payload, error) {
+	k, ok := byName[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown proof kind: %s", name)
+	}
+	payload := reflect.New(k.Schema.payload).Interface().(Payload)
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(payload); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", k.Name, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid %s payload: unexpected data after JSON value", k.Name)
+	}
+	if err := payload.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", k.Name, err)
+	}
+	return payload, nil
+}
+
+// Encode returns the canonical encoding of a payload of kind name: the
+// domain tag, the kind, then each schema field's name and compact JSON
+// value, every part prefixed with its uint32 big-endian length. Kinds with
+// their own encoding (qr hashes the scanned text) use that instead.
+func Encode(name string, payload Payload) ([]byte, error) {
+	k, ok := byName[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown proof kind: %s", name)
+	}
+	if k.Encode != nil {
+		return k.Encode(payload), nil
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(payload))
+	if v.Type() != k.Schema.payload {
+		return nil, fmt.Errorf("%s payload must be %s, got %s", k.Name, k.Schema.payload.Name(), v.Type().Name())
+	}
+
+	parts := []string{k.Name}
+	for i, field := range k.Schema.Fields {
+		value, err := canonicalJSON(v.Field(k.Schema.index[i]).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("encode %s.%s: %w", k.Name, field, err)
+		}
+		parts = append(parts, field, string(value))
+	}
+	return tagged.Message(payloadDomain, parts...), nil
+}
+
+// Receipt returns the hex SHA-256 receipt of a payload of kind name
+func Receipt(name string, payload Payload) (string, error) {
+	encoded, err := Encode(name, payload)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// VerifyPayload decodes raw for kind name and checks that it recomputes
+// to receipt
+func VerifyPayload(name, receipt string, raw []byte) (Payload, error) {
+	payload, err := Decode(name, raw)
+	if err != nil {
+		return nil, err
+	}
+	computed, err := Receipt(name, payload)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(computed, receipt) {
+		return nil, fmt.Errorf("receipt mismatch: %s payload hashes to %s", name, computed)
+	}
+	return payload, nil
+}
+
+// canonicalJSON is compact JSON with map keys sorted (encoding/json's
+// default). NaN and infinities have no JSON form and are rejected.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return nil, fmt.Errorf("non-finite number")
+	}
+	return json.Marshal(v)
+}
+
+// encodeQR - A QR receipt is the SHA-256 of the scanned text itself, as
+// computed by pkg/camera scanners
+func encodeQR(p Payload) []byte {
+	return []byte(p.(*QRCode).RawData)
+}
@@ -0,0 +1,118 @@
+package proofkind
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+)
+
+// part is one length-prefixed piece of the canonical encoding
+func part(s string) []byte {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(s)))
+	return append(n[:], s...)
+}
+
+func TestEncodeIsTheDocumentedLayout(t *testing.T) {
+	payload, err := Decode("telemetry", []byte(`{"readings":{"speed":12.5,"alt":3},"timestamp":1700000000,"device_id":"drone_001"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Encode("telemetry", payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fields in declaration order, map keys sorted, whatever the input order
+	want := bytes.Join([][]byte{
+		part("osovm/proof-payload/v1"), part("telemetry"),
+		part("device_id"), part(`"drone_001"`),
+		part("timestamp"), part("1700000000"),
+		part("readings"), part(`{"alt":3,"speed":12.5}`),
+	}, nil)
+	if !bytes.Equal(got, want) {
+		t.Errorf("encoding\n%q\nwant\n%q", got, want)
+	}
+
+	sum := sha256.Sum256(want)
+	receipt, _ := Receipt("telemetry", payload)
+	if receipt != hex.EncodeToString(sum[:]) {
+		t.Errorf("receipt %s", receipt)
+	}
+}
+
+func TestReceiptsAreBoundToTheirKind(t *testing.T) {
+	raw := []byte(`{"sensor":"scale","value":2.5,"unit":"kg","timestamp":1700000000}`)
+	weight, _ := Decode("weight", raw)
+	temp, _ := Decode("temp", raw)
+	a, _ := Receipt("weight", weight)
+	b, _ := Receipt("temp", temp)
+	if a == b {
+		t.Error("weight and temp share a receipt for the same payload")
+	}
+
+	// Aliases resolve to the kind, so they hash alike
+	qr, _ := Decode("qr", []byte(`{"raw_data":"PKG-42"}`))
+	c, _ := Receipt("qr", qr)
+	d, _ := Receipt("qr_scan", qr)
+	sum := sha256.Sum256([]byte("PKG-42"))
+	if c != d || c != hex.EncodeToString(sum[:]) {
+		t.Errorf("qr receipts %s, %s", c, d)
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	for _, tc := range []struct {
+		kind, raw, err string
+	}{
+		{"telemetry", `{"device_id":"d","timestamp":1,"readings":{"a":1},"extra":1}`, "unknown field"},
+		{"telemetry", `{"device_id":"d","timestamp":1,"readings":{"a":1}} {}`, "unexpected data after JSON value"},
+		{"telemetry", `{"device_id":"d","timestamp":1,"readings":{}}`, "readings cannot be empty"},
+		{"telemetry", `{"device_id":"d","readings":{"a":1}}`, "timestamp is required"},
+		{"gps", `{"lat":91,"lon":0,"timestamp":1}`, "lat must be within [-90, 90]"},
+		{"gps", `{"lat":0,"lon":-180.5,"timestamp":1}`, "lon must be within [-180, 180]"},
+		{"lidar", `{"range_m":1,"bearing_deg":360,"timestamp":1}`, "bearing_deg must be within [0, 360)"},
+		{"biometric", `{"template_sha256":"` + hash + `","match_score":1.01,"timestamp":1}`, "match_score must be within [0, 1]"},
+		{"video", `{"media_type":"mp4","content_sha256":"abc","timestamp":1}`, "content_sha256 must be a 64-character hex hash"},
+		{"imu", `{"samples":[],"rate_hz":100,"timestamp":1}`, "samples cannot be empty"},
+		{"qr", `{"raw_data":""}`, "raw_data is required"},
+		{"bogus", `{}`, "unknown proof kind: bogus"},
+	} {
+		_, err := Decode(tc.kind, []byte(tc.raw))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s %s: got %v, want %q", tc.kind, tc.raw, err, tc.err)
+		}
+	}
+}
+
+func TestVerifyPayload(t *testing.T) {
+	raw := []byte(`{"tag_id":"04:A2:2B","timestamp":1700000000}`)
+	payload, _ := Decode("nfc", raw)
+	receipt, _ := Receipt("nfc", payload)
+
+	if _, err := VerifyPayload("nfc", strings.ToUpper(receipt), raw); err != nil {
+		t.Errorf("matching receipt: %v", err)
+	}
+	// The same payload under a sibling kind recomputes differently
+	if _, err := VerifyPayload("rfid", receipt, raw); err == nil || !strings.Contains(err.Error(), "receipt mismatch") {
+		t.Errorf("rfid: %v", err)
+	}
+	tampered := []byte(`{"tag_id":"04:A2:2C","timestamp":1700000000}`)
+	if _, err := VerifyPayload("nfc", receipt, tampered); err == nil {
+		t.Error("a tampered payload verified")
+	}
+}
+
+func TestEncodeRejectsMismatchedAndNonFinitePayloads(t *testing.T) {
+	if _, err := Encode("gps", &Telemetry{}); err == nil || !strings.Contains(err.Error(), "gps payload must be GPSFix") {
+		t.Errorf("wrong payload type: %v", err)
+	}
+	if _, err := Encode("gps", &GPSFix{Lat: math.NaN(), Timestamp: 1}); err == nil || !strings.Contains(err.Error(), "non-finite") {
+		t.Errorf("NaN: %v", err)
+	}
+}
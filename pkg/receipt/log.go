@@ -0,0 +1,251 @@
+// OSOVM Receipt Log
+// The local append-only log execution receipts are sealed into: a hash
+// chain of receipts, organised as a Merkle tree for proofs
+
+package receipt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrNotFound is returned when no receipt matches a lookup
+var ErrNotFound = errors.New("receipt not found")
+
+// Log - An append-only chain of receipts. Sequence numbers start at 1;
+// receipt seq is leaf seq-1 of the Merkle tree.
+type Log interface {
+	// Head returns the sequence number and hash of the last receipt, or
+	// 0 and "" for an empty log
+	Head() (uint64, string)
+	// Append adds a signed receipt that links to the current head
+	Append(r *Receipt) error
+	// Receipts returns every receipt, oldest first
+	Receipts() []*Receipt
+	// Get returns receipt seq
+	Get(seq uint64) (*Receipt, error)
+	// Find returns the receipt with the given receipt hash or, failing
+	// that, the first receipt with the given execution receipt
+	Find(hash string) (*Receipt, error)
+	// Root returns the Merkle tree hash of the first size receipts
+	Root(size uint64) ([]byte, error)
+	// InclusionProof returns the audit path of receipt seq in the tree of
+	// the first size receipts
+	InclusionProof(seq, size uint64) ([][]byte, error)
+	// ConsistencyProof proves the tree of the first first receipts is a
+	// prefix of the tree of the first second receipts
+	ConsistencyProof(first, second uint64) ([][]byte, error)
+}
+
+// chain - The receipts of a log with their tree leaves and lookup index
+type chain struct {
+	mu       sync.Mutex
+	receipts []*Receipt
+	leaves   [][]byte
+	index    map[string]uint64 // receipt hash and execution receipt → seq
+}
+
+func newChain() chain {
+	return chain{index: make(map[string]uint64)}
+}
+
+// add appends r after checking it; the caller holds mu
+func (c *chain) add(r *Receipt) {
+	c.receipts = append(c.receipts, r)
+	c.leaves = append(c.leaves, LeafHash(r))
+	c.index[r.Hash] = r.Seq
+	// Identical executions share an execution receipt; the first one wins
+	if _, seen := c.index[r.ExecutionReceipt]; r.ExecutionReceipt != "" && !seen {
+		c.index[r.ExecutionReceipt] = r.Seq
+	}
+}
+
+// check verifies r and that it links to the last receipt; the caller
+// holds mu
+func (c *chain) check(r *Receipt) error {
+	if err := r.Verify(); err != nil {
+		return err
+	}
+	var prev *Receipt
+	if len(c.receipts) > 0 {
+		prev = c.receipts[len(c.receipts)-1]
+	}
+	return r.VerifyLink(prev)
+}
+
+func (c *chain) Head() (uint64, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.receipts) == 0 {
+		return 0, ""
+	}
+	last := c.receipts[len(c.receipts)-1]
+	return last.Seq, last.Hash
+}
+
+func (c *chain) Receipts() []*Receipt {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Receipt(nil), c.receipts...)
+}
+
+func (c *chain) Get(seq uint64) (*Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if seq == 0 || seq > uint64(len(c.receipts)) {
+		return nil, fmt.Errorf("%w: #%d (log has %d)", ErrNotFound, seq, len(c.receipts))
+	}
+	return c.receipts[seq-1], nil
+}
+
+func (c *chain) Find(hash string) (*Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	seq, ok := c.index[hash]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, hash)
+	}
+	return c.receipts[seq-1], nil
+}
+
+// size checks a tree size against the log; the caller holds mu
+func (c *chain) size(size uint64) error {
+	if size > uint64(len(c.leaves)) {
+		return fmt.Errorf("tree size %d exceeds the log's %d receipts", size, len(c.leaves))
+	}
+	return nil
+}
+
+func (c *chain) Root(size uint64) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.size(size); err != nil {
+		return nil, err
+	}
+	return rootHash(c.leaves[:size]), nil
+}
+
+func (c *chain) InclusionProof(seq, size uint64) ([][]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.size(size); err != nil {
+		return nil, err
+	}
+	if seq == 0 || seq > size {
+		return nil, fmt.Errorf("receipt #%d is not in a tree of size %d", seq, size)
+	}
+	return inclusionPath(seq-1, c.leaves[:size]), nil
+}
+
+func (c *chain) ConsistencyProof(first, second uint64) ([][]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.size(second); err != nil {
+		return nil, err
+	}
+	if first > second {
+		return nil, fmt.Errorf("tree size %d is larger than %d", first, second)
+	}
+	if first == 0 || first == second {
+		return nil, nil
+	}
+	return consistencyPath(first, c.leaves[:second], true), nil
+}
+
+// ========== In-Memory Log ==========
+
+// MemoryLog - A Log that lasts as long as the process
+type MemoryLog struct {
+	chain
+}
+
+func NewMemoryLog() *MemoryLog {
+	return &MemoryLog{chain: newChain()}
+}
+
+func (l *MemoryLog) Append(r *Receipt) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.check(r); err != nil {
+		return err
+	}
+	l.add(r)
+	return nil
+}
+
+// ========== File Log ==========
+
+// FileLog - A Log persisted as JSON lines, one receipt per line. Opening
+// the file verifies the whole chain; appends are synced before returning.
+type FileLog struct {
+	chain
+	path string
+}
+
+// OpenFileLog loads and verifies the log at path. A missing file is an
+// empty log.
+func OpenFileLog(path string) (*FileLog, error) {
+	l := &FileLog{chain: newChain(), path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipt log: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var r Receipt
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("receipt log %s:%d: %w", path, line, err)
+		}
+		if err := l.check(&r); err != nil {
+			return nil, fmt.Errorf("receipt log %s:%d: %w", path, line, err)
+		}
+		l.add(&r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read receipt log: %w", err)
+	}
+	return l, nil
+}
+
+func (l *FileLog) Append(r *Receipt) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.check(r); err != nil {
+		return err
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to append receipt: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to append receipt: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to append receipt: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to append receipt: %w", err)
+	}
+	l.add(r)
+	return nil
+}
@@ -0,0 +1,204 @@
+// OSOVM Receipt Merkle Tree
+// Transparency-log hashing over the receipt log (RFC 9162): tree roots,
+// inclusion proofs and consistency proofs between tree sizes
+
+package receipt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+)
+
+// Domain-separation prefixes of leaf and interior node hashes
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// LeafHash is the tree leaf of a receipt: SHA-256 over 0x00 and its
+// canonical message
+func LeafHash(r *Receipt) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(r.Message())
+	return h.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// split returns the largest power of two smaller than n (n > 1)
+func split(n uint64) uint64 {
+	return 1 << (bits.Len64(n-1) - 1)
+}
+
+// rootHash is the Merkle tree hash of leaves
+func rootHash(leaves [][]byte) []byte {
+	switch n := uint64(len(leaves)); n {
+	case 0:
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	case 1:
+		return leaves[0]
+	default:
+		k := split(n)
+		return nodeHash(rootHash(leaves[:k]), rootHash(leaves[k:]))
+	}
+}
+
+// inclusionPath is the audit path of leaf m in leaves
+func inclusionPath(m uint64, leaves [][]byte) [][]byte {
+	n := uint64(len(leaves))
+	if n <= 1 {
+		return nil
+	}
+	k := split(n)
+	if m < k {
+		return append(inclusionPath(m, leaves[:k]), rootHash(leaves[k:]))
+	}
+	return append(inclusionPath(m-k, leaves[k:]), rootHash(leaves[:k]))
+}
+
+// consistencyPath proves the tree of the first m leaves is a prefix of leaves
+func consistencyPath(m uint64, leaves [][]byte, complete bool) [][]byte {
+	n := uint64(len(leaves))
+	if m == n {
+		if complete {
+			return nil
+		}
+		return [][]byte{rootHash(leaves)}
+	}
+	k := split(n)
+	if m <= k {
+		return append(consistencyPath(m, leaves[:k], complete), rootHash(leaves[k:]))
+	}
+	return append(consistencyPath(m-k, leaves[k:], false), rootHash(leaves[:k]))
+}
+
+// ========== Proof Verification ==========
+
+// verifyInclusion checks that leaf is at index in the tree of size with root
+func verifyInclusion(index, size uint64, leaf []byte, path [][]byte, root []byte) error {
+	if index >= size {
+		return fmt.Errorf("leaf index %d is outside a tree of size %d", index, size)
+	}
+	fn, sn, r := index, size-1, leaf
+	for _, p := range path {
+		if sn == 0 {
+			return fmt.Errorf("inclusion proof is too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return fmt.Errorf("inclusion proof is too short")
+	}
+	if !bytes.Equal(r, root) {
+		return fmt.Errorf("inclusion proof does not lead to the tree root")
+	}
+	return nil
+}
+
+// verifyConsistency checks that the tree of size first with root firstRoot
+// is a prefix of the tree of size second with root secondRoot
+func verifyConsistency(first, second uint64, path [][]byte, firstRoot, secondRoot []byte) error {
+	switch {
+	case first > second:
+		return fmt.Errorf("tree shrank from %d to %d", first, second)
+	case first == second:
+		if len(path) != 0 || !bytes.Equal(firstRoot, secondRoot) {
+			return fmt.Errorf("trees of equal size %d differ", first)
+		}
+		return nil
+	case first == 0:
+		// Every tree extends the empty tree
+		if len(path) != 0 {
+			return fmt.Errorf("consistency proof from an empty tree must be empty")
+		}
+		return nil
+	}
+
+	if first&(first-1) == 0 {
+		path = append([][]byte{firstRoot}, path...)
+	}
+	if len(path) == 0 {
+		return fmt.Errorf("consistency proof is empty")
+	}
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := path[0], path[0]
+	for _, c := range path[1:] {
+		if sn == 0 {
+			return fmt.Errorf("consistency proof is too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = nodeHash(c, fr)
+			sr = nodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = nodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return fmt.Errorf("consistency proof is too short")
+	}
+	if !bytes.Equal(fr, firstRoot) || !bytes.Equal(sr, secondRoot) {
+		return fmt.Errorf("consistency proof does not match the tree roots")
+	}
+	return nil
+}
+
+// ========== Hex Helpers ==========
+
+func encodePath(path [][]byte) []string {
+	out := make([]string, len(path))
+	for i, p := range path {
+		out[i] = hex.EncodeToString(p)
+	}
+	return out
+}
+
+func decodePath(path []string) ([][]byte, error) {
+	out := make([][]byte, len(path))
+	for i, p := range path {
+		b, err := decodeHash(p)
+		if err != nil {
+			return nil, fmt.Errorf("proof node %d: %w", i, err)
+		}
+		out[i] = b
+	}
+	return out, nil
+}
+
+func decodeHash(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != sha256.Size {
+		return nil, fmt.Errorf("invalid hash %q", s)
+	}
+	return b, nil
+}
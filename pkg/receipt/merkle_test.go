@@ -0,0 +1,201 @@
+package receipt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// The RFC 6962/9162 reference tree: eight leaves of raw data, as used by
+// the Certificate Transparency test suites
+var rfcLeafData = []string{
+	"",
+	"00",
+	"10",
+	"2021",
+	"3031",
+	"40414243",
+	"5051525354555657",
+	"606162636465666768696a6b6c6d6e6f",
+}
+
+// rfcRoots[n-1] is the root of the first n leaves
+var rfcRoots = []string{
+	"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+	"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+	"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+	"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+	"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+	"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+	"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+}
+
+func rfcLeaves(t *testing.T) [][]byte {
+	t.Helper()
+	leaves := make([][]byte, len(rfcLeafData))
+	for i, s := range rfcLeafData {
+		data, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(append([]byte{leafPrefix}, data...))
+		leaves[i] = sum[:]
+	}
+	return leaves
+}
+
+func hashes(t *testing.T, list ...string) [][]byte {
+	t.Helper()
+	path, err := decodePath(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRFCRootHashes(t *testing.T) {
+	leaves := rfcLeaves(t)
+	if got := hex.EncodeToString(rootHash(nil)); got != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("empty tree: %s", got)
+	}
+	for n, want := range rfcRoots {
+		if got := hex.EncodeToString(rootHash(leaves[:n+1])); got != want {
+			t.Errorf("size %d: root %s, want %s", n+1, got, want)
+		}
+	}
+}
+
+func TestRFCInclusionProofs(t *testing.T) {
+	leaves := rfcLeaves(t)
+	for _, tc := range []struct {
+		index, size uint64
+		path        []string
+	}{
+		{0, 1, nil},
+		{0, 8, []string{
+			"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+		}},
+		{5, 8, []string{
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+			"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+			"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		}},
+		{2, 3, []string{
+			"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		}},
+		{1, 5, []string{
+			"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+		}},
+	} {
+		want := hashes(t, tc.path...)
+		got := inclusionPath(tc.index, leaves[:tc.size])
+		if len(got) != len(want) {
+			t.Errorf("leaf %d of %d: path %v", tc.index, tc.size, encodePath(got))
+			continue
+		}
+		for i := range want {
+			if !bytes.Equal(got[i], want[i]) {
+				t.Errorf("leaf %d of %d: node %d is %x", tc.index, tc.size, i, got[i])
+			}
+		}
+
+		root := hashes(t, rfcRoots[tc.size-1])[0]
+		if err := verifyInclusion(tc.index, tc.size, leaves[tc.index], want, root); err != nil {
+			t.Errorf("leaf %d of %d: %v", tc.index, tc.size, err)
+		}
+		// The same path proves nothing for a neighbouring leaf, nor with a
+		// node altered or dropped
+		if tc.size == 1 {
+			continue
+		}
+		other := (tc.index + 1) % tc.size
+		if verifyInclusion(other, tc.size, leaves[tc.index], want, root) == nil {
+			t.Errorf("leaf %d of %d verified at index %d", tc.index, tc.size, other)
+		}
+		tampered := append([][]byte{append([]byte{want[0][0] ^ 1}, want[0][1:]...)}, want[1:]...)
+		if verifyInclusion(tc.index, tc.size, leaves[tc.index], tampered, root) == nil {
+			t.Errorf("leaf %d of %d verified with an altered node", tc.index, tc.size)
+		}
+		if verifyInclusion(tc.index, tc.size, leaves[tc.index], want[:len(want)-1], root) == nil {
+			t.Errorf("leaf %d of %d verified with a node missing", tc.index, tc.size)
+		}
+	}
+}
+
+func TestRFCConsistencyProofs(t *testing.T) {
+	leaves := rfcLeaves(t)
+	for _, tc := range []struct {
+		first, second uint64
+		path          []string
+	}{
+		{1, 1, nil},
+		{1, 8, []string{
+			"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+		}},
+		{6, 8, []string{
+			"0ebc5d3437fbe2db158b9f126a1d118e308181031d0a949f8dededebc558ef6a",
+			"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+			"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		}},
+		{2, 5, []string{
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+		}},
+	} {
+		want := hashes(t, tc.path...)
+		var got [][]byte
+		if tc.first < tc.second {
+			got = consistencyPath(tc.first, leaves[:tc.second], true)
+		}
+		if len(got) != len(want) {
+			t.Errorf("%d → %d: path %v", tc.first, tc.second, encodePath(got))
+			continue
+		}
+		for i := range want {
+			if !bytes.Equal(got[i], want[i]) {
+				t.Errorf("%d → %d: node %d is %x", tc.first, tc.second, i, got[i])
+			}
+		}
+
+		firstRoot := hashes(t, rfcRoots[tc.first-1])[0]
+		secondRoot := hashes(t, rfcRoots[tc.second-1])[0]
+		if err := verifyConsistency(tc.first, tc.second, want, firstRoot, secondRoot); err != nil {
+			t.Errorf("%d → %d: %v", tc.first, tc.second, err)
+		}
+		// Swapped roots or a dropped node break the proof
+		if tc.first != tc.second && verifyConsistency(tc.first, tc.second, want, secondRoot, firstRoot) == nil {
+			t.Errorf("%d → %d verified with swapped roots", tc.first, tc.second)
+		}
+		if len(want) > 0 && verifyConsistency(tc.first, tc.second, want[1:], firstRoot, secondRoot) == nil {
+			t.Errorf("%d → %d verified with a node missing", tc.first, tc.second)
+		}
+	}
+}
+
+// Every inclusion and consistency proof the tree produces verifies, up to
+// the reference size
+func TestEveryProofVerifies(t *testing.T) {
+	leaves := rfcLeaves(t)
+	for n := uint64(1); n <= uint64(len(leaves)); n++ {
+		root := rootHash(leaves[:n])
+		for m := uint64(0); m < n; m++ {
+			if err := verifyInclusion(m, n, leaves[m], inclusionPath(m, leaves[:n]), root); err != nil {
+				t.Errorf("leaf %d of %d: %v", m, n, err)
+			}
+			if m == 0 {
+				continue
+			}
+			if err := verifyConsistency(m, n, consistencyPath(m, leaves[:n], true), rootHash(leaves[:m]), root); err != nil {
+				t.Errorf("%d → %d: %v", m, n, err)
+			}
+		}
+	}
+}
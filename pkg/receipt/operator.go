@@ -0,0 +1,85 @@
+// OSOVM Operator Keys
+// The Ed25519 key a VM operator signs execution receipts with
+
+package receipt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Operator - The signing identity of a VM operator
+type Operator struct {
+	PublicKey string // hex Ed25519 public key
+
+	privateKey ed25519.PrivateKey
+}
+
+// operatorFile is the on-disk format. Only the 32-byte seed is stored.
+type operatorFile struct {
+	PublicKey string `json:"public_key"`
+	Seed      string `json:"seed"`
+}
+
+// GenerateOperator creates an operator with a fresh keypair
+func GenerateOperator() (*Operator, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate operator key: %w", err)
+	}
+	return NewOperator(priv), nil
+}
+
+// NewOperator wraps an existing private key
+func NewOperator(priv ed25519.PrivateKey) *Operator {
+	return &Operator{
+		PublicKey:  hex.EncodeToString(priv.Public().(ed25519.PublicKey)),
+		privateKey: priv,
+	}
+}
+
+// Save writes the operator key to path (mode 0600)
+func (op *Operator) Save(path string) error {
+	data, err := json.MarshalIndent(operatorFile{
+		PublicKey: op.PublicKey,
+		Seed:      hex.EncodeToString(op.privateKey.Seed()),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// LoadOperator restores an operator from a key file written by Save
+func LoadOperator(path string) (*Operator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operator key: %w", err)
+	}
+	var f operatorFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse operator key %s: %w", path, err)
+	}
+	seed, err := hex.DecodeString(f.Seed)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("operator key %s: invalid seed", path)
+	}
+	op := NewOperator(ed25519.NewKeyFromSeed(seed))
+	if f.PublicKey != "" && f.PublicKey != op.PublicKey {
+		return nil, fmt.Errorf("operator key %s: public key does not match seed", path)
+	}
+	return op, nil
+}
+
+// ParsePublicKey decodes a hex Ed25519 public key
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid operator public key %q", s)
+	}
+	return ed25519.PublicKey(b), nil
+}
@@ -0,0 +1,161 @@
+// OSOVM Execution Receipts
+// Canonical, operator-signed records of completed rituals, each linked to
+// the hash of the receipt before it
+
+package receipt
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/ase-lang/osovm/internal/tagged"
+)
+
+// Version of the receipt format
+const Version = 1
+
+// receiptDomain tags the receipt message the operator hashes and signs
+const receiptDomain = "osovm/execution-receipt/v1"
+
+// WitnessRef - A witness counted toward the ritual's quorum, with what an
+// auditor needs to rebuild the attestation it signed: the proof receipt,
+// the payer and the ritual come from the receipt, Timestamp and Network
+// from here
+type WitnessRef struct {
+	DeviceID  string `json:"device_id"`
+	Timestamp int64  `json:"timestamp"`
+	Network   string `json:"network"`
+	Signature string `json:"signature"`
+}
+
+// Receipt - What the VM sealed for one successful execution. Kind, Anchor,
+// Pointer and Verifiers mirror the ritual's @receipt(kind, hash, pointer,
+// verifiers) attribute; Kind is "simple" for rituals without one.
+type Receipt struct {
+	Version          int             `json:"version"`
+	Seq              uint64          `json:"seq"` // position in the chain, from 1
+	Ritual           string          `json:"ritual"`
+	ProgramHash      string          `json:"program_hash"`
+	Inputs           json.RawMessage `json:"inputs"` // the ritual's args
+	ProofType        string          `json:"proof_type,omitempty"`
+	ProofReceipt     string          `json:"proof_receipt,omitempty"`
+	Payer            string          `json:"payer,omitempty"` // the proving device, which pays for anything the ritual moves
+	Witnesses        []WitnessRef    `json:"witnesses"`
+	Result           json.RawMessage `json:"result"`
+	ExecutionReceipt string          `json:"execution_receipt"` // committed to by EMIT_RECEIPT
+	GasUsed          int64           `json:"gas_used"`
+	Kind             string          `json:"kind"`
+	Anchor           string          `json:"anchor,omitempty"`
+	Pointer          string          `json:"pointer,omitempty"`
+	Verifiers        int             `json:"verifiers,omitempty"`
+	Timestamp        int64           `json:"timestamp"`
+	Operator         string          `json:"operator"` // hex Ed25519 public key
+	Prev             string          `json:"prev"`     // hash of the previous receipt; empty for the first
+	Hash             string          `json:"hash"`
+	Signature        string          `json:"signature"`
+}
+
+// Message returns the canonical bytes that are hashed and signed: the
+// domain tag and every field except Hash and Signature, each prefixed with
+// its big-endian uint32 length. Witnesses contribute their count followed
+// by each device ID, timestamp, network and signature.
+func (r *Receipt) Message() []byte {
+	fields := []string{
+		strconv.Itoa(r.Version),
+		strconv.FormatUint(r.Seq, 10),
+		r.Ritual,
+		r.ProgramHash,
+		string(r.Inputs),
+		r.ProofType,
+		r.ProofReceipt,
+		r.Payer,
+		strconv.Itoa(len(r.Witnesses)),
+	}
+	for _, w := range r.Witnesses {
+		fields = append(fields, w.DeviceID, strconv.FormatInt(w.Timestamp, 10), w.Network, w.Signature)
+	}
+	fields = append(fields,
+		string(r.Result),
+		r.ExecutionReceipt,
+		strconv.FormatInt(r.GasUsed, 10),
+		r.Kind,
+		r.Anchor,
+		r.Pointer,
+		strconv.Itoa(r.Verifiers),
+		strconv.FormatInt(r.Timestamp, 10),
+		r.Operator,
+		r.Prev,
+	)
+	return tagged.Message(receiptDomain, fields...)
+}
+
+// ComputeHash returns the hex SHA-256 of the canonical message
+func (r *Receipt) ComputeHash() string {
+	sum := sha256.Sum256(r.Message())
+	return hex.EncodeToString(sum[:])
+}
+
+// Sign sets Operator, Hash and Signature using the operator's key
+func (r *Receipt) Sign(op *Operator) {
+	r.Operator = op.PublicKey
+	r.Hash = r.ComputeHash()
+	r.Signature = hex.EncodeToString(ed25519.Sign(op.privateKey, r.Message()))
+}
+
+// Verify checks the receipt's hash and its signature by Operator. Whether
+// Operator is a trusted key is up to the caller.
+func (r *Receipt) Verify() error {
+	if r.Version != Version {
+		return fmt.Errorf("receipt %d: unsupported version %d", r.Seq, r.Version)
+	}
+	if got := r.ComputeHash(); got != r.Hash {
+		return fmt.Errorf("receipt %d: hash mismatch (recorded %s, computed %s)", r.Seq, r.Hash, got)
+	}
+	pub, err := ParsePublicKey(r.Operator)
+	if err != nil {
+		return fmt.Errorf("receipt %d: %w", r.Seq, err)
+	}
+	sig, err := hex.DecodeString(r.Signature)
+	if err != nil || !ed25519.Verify(pub, r.Message(), sig) {
+		return fmt.Errorf("receipt %d: invalid operator signature", r.Seq)
+	}
+	return nil
+}
+
+// VerifyLink checks that r directly follows prev (nil for the first receipt)
+func (r *Receipt) VerifyLink(prev *Receipt) error {
+	wantSeq, wantPrev := uint64(1), ""
+	if prev != nil {
+		wantSeq, wantPrev = prev.Seq+1, prev.Hash
+	}
+	if r.Seq != wantSeq {
+		return fmt.Errorf("receipt %d: expected sequence %d", r.Seq, wantSeq)
+	}
+	if r.Prev != wantPrev {
+		return fmt.Errorf("receipt %d: prev %q does not link to %q", r.Seq, r.Prev, wantPrev)
+	}
+	return nil
+}
+
+// VerifyChain checks every receipt, that each links to the one before,
+// and, if operator is non-empty, that all were signed by that key
+func VerifyChain(receipts []*Receipt, operator string) error {
+	var prev *Receipt
+	for _, r := range receipts {
+		if err := r.Verify(); err != nil {
+			return err
+		}
+		if err := r.VerifyLink(prev); err != nil {
+			return err
+		}
+		if operator != "" && r.Operator != operator {
+			return fmt.Errorf("receipt %d: signed by %s, not the trusted operator", r.Seq, r.Operator)
+		}
+		prev = r
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package receipt
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rfc8032Operator holds the key of RFC 8032 section 7.1, test 1
+func rfc8032Operator(t *testing.T) *Operator {
+	t.Helper()
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	op := NewOperator(ed25519.NewKeyFromSeed(seed))
+	if op.PublicKey != "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a" {
+		t.Fatalf("public key %s", op.PublicKey)
+	}
+	return op
+}
+
+// signedLog returns a log of n receipts signed by op
+func signedLog(t *testing.T, op *Operator, n int) *MemoryLog {
+	t.Helper()
+	log := NewMemoryLog()
+	prev := ""
+	for i := 1; i <= n; i++ {
+		r := &Receipt{
+			Version:   Version,
+			Seq:       uint64(i),
+			Ritual:    "drone_delivery",
+			Inputs:    json.RawMessage(`{}`),
+			Result:    json.RawMessage(`"ok"`),
+			Kind:      "simple",
+			Timestamp: 1700000000 + int64(i),
+			Prev:      prev,
+		}
+		r.Sign(op)
+		if err := log.Append(r); err != nil {
+			t.Fatal(err)
+		}
+		prev = r.Hash
+	}
+	return log
+}
+
+// witnessedReceipt returns receipt seq of a witnessed ritual, linked to
+// prev and signed by op
+func witnessedReceipt(op *Operator, seq uint64, prev string) *Receipt {
+	r := &Receipt{
+		Version:      Version,
+		Seq:          seq,
+		Ritual:       "drone_delivery",
+		ProgramHash:  strings.Repeat("01", 32),
+		Inputs:       json.RawMessage(`{"amount":5}`),
+		ProofType:    "ed25519",
+		ProofReceipt: strings.Repeat("ab", 32),
+		Payer:        "drone_001",
+		Witnesses: []WitnessRef{
+			{DeviceID: "witness_001", Timestamp: 1700000000, Network: "lora", Signature: strings.Repeat("cd", 64)},
+			{DeviceID: "witness_002", Timestamp: 1700000001, Network: "ble", Signature: strings.Repeat("ef", 64)},
+		},
+		Result:           json.RawMessage(`"ok"`),
+		ExecutionReceipt: strings.Repeat("02", 32),
+		GasUsed:          42,
+		Kind:             "simple",
+		Timestamp:        1700000000 + int64(seq),
+		Prev:             prev,
+	}
+	r.Sign(op)
+	return r
+}
+
+func TestReceiptChain(t *testing.T) {
+	op := rfc8032Operator(t)
+	log := NewMemoryLog()
+	prev := ""
+	for seq := uint64(1); seq <= 3; seq++ {
+		r := witnessedReceipt(op, seq, prev)
+		if err := r.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		if err := log.Append(r); err != nil {
+			t.Fatalf("append %d: %v", seq, err)
+		}
+		prev = r.Hash
+	}
+	receipts := log.Receipts()
+	if err := VerifyChain(receipts, op.PublicKey); err != nil {
+		t.Fatalf("chain: %v", err)
+	}
+	if receipts[1].Prev != receipts[0].Hash || receipts[2].Prev != receipts[1].Hash {
+		t.Error("receipts do not link to their predecessors")
+	}
+	if head, hash := log.Head(); head != 3 || hash != receipts[2].Hash {
+		t.Errorf("head %d %s", head, hash)
+	}
+
+	other, err := GenerateOperator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyChain(receipts, other.PublicKey); err == nil || !strings.Contains(err.Error(), "not the trusted operator") {
+		t.Errorf("untrusted operator: %v", err)
+	}
+}
+
+func TestBrokenLinksAreRejected(t *testing.T) {
+	op := rfc8032Operator(t)
+	first := witnessedReceipt(op, 1, "")
+	second := witnessedReceipt(op, 2, first.Hash)
+
+	for name, tc := range map[string]struct {
+		chain  []*Receipt
+		errMsg string
+	}{
+		"broken prev":    {[]*Receipt{first, witnessedReceipt(op, 2, strings.Repeat("00", 32))}, "does not link"},
+		"first has prev": {[]*Receipt{witnessedReceipt(op, 1, first.Hash)}, "does not link"},
+		"skipped seq":    {[]*Receipt{first, witnessedReceipt(op, 3, first.Hash)}, "expected sequence 2"},
+		"reordered":      {[]*Receipt{second, first}, "expected sequence 1"},
+		"replayed":       {[]*Receipt{first, second, second}, "expected sequence 3"},
+	} {
+		if err := VerifyChain(tc.chain, ""); err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+			t.Errorf("%s: chain verified with %v, want %q", name, err, tc.errMsg)
+		}
+		log := NewMemoryLog()
+		var err error
+		for _, r := range tc.chain {
+			if err = log.Append(r); err != nil {
+				break
+			}
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+			t.Errorf("%s: appended with %v, want %q", name, err, tc.errMsg)
+		}
+	}
+}
+
+func TestTamperedReceiptsAreRejected(t *testing.T) {
+	op := rfc8032Operator(t)
+
+	// Every field is covered by the hash, witness timestamps, networks and
+	// the payer included
+	for _, tc := range []struct {
+		field  string
+		mutate func(r *Receipt)
+	}{
+		{"inputs", func(r *Receipt) { r.Inputs = json.RawMessage(`{"amount":500}`) }},
+		{"payer", func(r *Receipt) { r.Payer = "equity" }},
+		{"witness device", func(r *Receipt) { r.Witnesses[0].DeviceID = "witness_003" }},
+		{"witness timestamp", func(r *Receipt) { r.Witnesses[1].Timestamp++ }},
+		{"witness network", func(r *Receipt) { r.Witnesses[0].Network = "ble" }},
+		{"witness signature", func(r *Receipt) { r.Witnesses[1].Signature = strings.Repeat("00", 64) }},
+		{"witness dropped", func(r *Receipt) { r.Witnesses = r.Witnesses[:1] }},
+		{"result", func(r *Receipt) { r.Result = json.RawMessage(`"failed"`) }},
+		{"gas", func(r *Receipt) { r.GasUsed++ }},
+		{"prev", func(r *Receipt) { r.Prev = strings.Repeat("00", 32) }},
+		// Shifting bytes between fields is a different message too
+		{"boundary", func(r *Receipt) { r.Ritual, r.ProgramHash = "drone_delivery0", r.ProgramHash[1:] }},
+	} {
+		r := witnessedReceipt(op, 1, "")
+		tc.mutate(r)
+		if err := r.Verify(); err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+			t.Errorf("tampered %s: got %v", tc.field, err)
+		}
+	}
+
+	// Rehashing a tampered receipt does not make the signature fit
+	r := witnessedReceipt(op, 1, "")
+	r.Payer = "equity"
+	r.Hash = r.ComputeHash()
+	if err := r.Verify(); err == nil || !strings.Contains(err.Error(), "invalid operator signature") {
+		t.Errorf("rehashed receipt: %v", err)
+	}
+
+	for name, sig := range map[string]string{
+		"flipped":   strings.Repeat("0", 128),
+		"malformed": "zz",
+		"truncated": r.Signature[:64],
+	} {
+		r := witnessedReceipt(op, 1, "")
+		r.Signature = sig
+		if err := r.Verify(); err == nil || !strings.Contains(err.Error(), "invalid operator signature") {
+			t.Errorf("%s signature: %v", name, err)
+		}
+	}
+
+	// Claiming another operator's key breaks both the hash and the signature
+	other, _ := GenerateOperator()
+	forged := witnessedReceipt(op, 1, "")
+	forged.Operator = other.PublicKey
+	forged.Hash = forged.ComputeHash()
+	if err := forged.Verify(); err == nil || !strings.Contains(err.Error(), "invalid operator signature") {
+		t.Errorf("forged operator: %v", err)
+	}
+}
+
+func TestFileLogRejectsATamperedLine(t *testing.T) {
+	op := rfc8032Operator(t)
+	path := filepath.Join(t.TempDir(), "receipts.jsonl")
+	log, err := OpenFileLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := witnessedReceipt(op, 1, "")
+	for _, r := range []*Receipt{first, witnessedReceipt(op, 2, first.Hash)} {
+		if err := log.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if reopened, err := OpenFileLog(path); err != nil || len(reopened.Receipts()) != 2 {
+		t.Fatalf("reopened: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), `"network":"ble"`, `"network":"lora"`, 1)
+	if tampered == string(data) {
+		t.Fatal("nothing to tamper with")
+	}
+	if err := os.WriteFile(path, []byte(tampered), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFileLog(path); err == nil || !strings.Contains(err.Error(), ":1: receipt 1: hash mismatch") {
+		t.Errorf("tampered log: %v", err)
+	}
+}
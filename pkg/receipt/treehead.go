@@ -0,0 +1,154 @@
+// OSOVM Signed Tree Heads
+// Operator-signed commitments to the receipt log, and the self-contained
+// proofs auditors check against them without trusting the VM host
+
+package receipt
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/ase-lang/osovm/internal/tagged"
+)
+
+// treeHeadDomain separates tree head signatures from receipt signatures
+const treeHeadDomain = "osovm/tree-head/v1"
+
+// TreeHead - The root of the first Size receipts, signed by the operator
+type TreeHead struct {
+	Size      uint64 `json:"size"`
+	Root      string `json:"root"` // hex Merkle tree hash
+	Timestamp int64  `json:"timestamp"`
+	Operator  string `json:"operator"`
+	Signature string `json:"signature"`
+}
+
+// Message returns the canonical bytes that are signed: the domain tag and
+// each field, every one prefixed with its big-endian uint32 length
+func (h *TreeHead) Message() []byte {
+	return tagged.Message(treeHeadDomain,
+		strconv.FormatUint(h.Size, 10),
+		h.Root,
+		strconv.FormatInt(h.Timestamp, 10),
+		h.Operator,
+	)
+}
+
+// SignTreeHead signs the root of the first size receipts in log
+func SignTreeHead(log Log, size uint64, op *Operator, timestamp int64) (*TreeHead, error) {
+	root, err := log.Root(size)
+	if err != nil {
+		return nil, err
+	}
+	h := &TreeHead{Size: size, Root: hex.EncodeToString(root), Timestamp: timestamp, Operator: op.PublicKey}
+	h.Signature = hex.EncodeToString(ed25519.Sign(op.privateKey, h.Message()))
+	return h, nil
+}
+
+// Verify checks the signature and, if operator is non-empty, that it is
+// the operator's
+func (h *TreeHead) Verify(operator string) error {
+	if operator != "" && h.Operator != operator {
+		return fmt.Errorf("tree head %d signed by %s, not the trusted operator", h.Size, h.Operator)
+	}
+	pub, err := ParsePublicKey(h.Operator)
+	if err != nil {
+		return err
+	}
+	sig, err := hex.DecodeString(h.Signature)
+	if err != nil || !ed25519.Verify(pub, h.Message(), sig) {
+		return fmt.Errorf("tree head %d: invalid operator signature", h.Size)
+	}
+	if _, err := decodeHash(h.Root); err != nil {
+		return fmt.Errorf("tree head %d: %w", h.Size, err)
+	}
+	return nil
+}
+
+// ========== Proof Bundles ==========
+
+// Inclusion - Proof that Receipt is in the log committed to by Head
+type Inclusion struct {
+	Receipt *Receipt  `json:"receipt"`
+	Head    *TreeHead `json:"tree_head"`
+	Path    []string  `json:"path"`
+}
+
+// ProveInclusion proves receipt seq is in the tree head
+func ProveInclusion(log Log, seq uint64, head *TreeHead) (*Inclusion, error) {
+	r, err := log.Get(seq)
+	if err != nil {
+		return nil, err
+	}
+	path, err := log.InclusionProof(seq, head.Size)
+	if err != nil {
+		return nil, err
+	}
+	return &Inclusion{Receipt: r, Head: head, Path: encodePath(path)}, nil
+}
+
+// Verify checks the receipt, the tree head (against operator, if given)
+// and that the receipt is a leaf of the head's tree
+func (p *Inclusion) Verify(operator string) error {
+	if p.Receipt == nil || p.Head == nil {
+		return fmt.Errorf("inclusion proof needs a receipt and a tree head")
+	}
+	if err := p.Head.Verify(operator); err != nil {
+		return err
+	}
+	if err := p.Receipt.Verify(); err != nil {
+		return err
+	}
+	if p.Receipt.Seq == 0 {
+		return fmt.Errorf("receipt has no sequence number")
+	}
+	path, err := decodePath(p.Path)
+	if err != nil {
+		return err
+	}
+	root, _ := decodeHash(p.Head.Root)
+	if err := verifyInclusion(p.Receipt.Seq-1, p.Head.Size, LeafHash(p.Receipt), path, root); err != nil {
+		return fmt.Errorf("receipt %d: %w", p.Receipt.Seq, err)
+	}
+	return nil
+}
+
+// Consistency - Proof that New's log extends Old's without rewriting it
+type Consistency struct {
+	Old  *TreeHead `json:"old"`
+	New  *TreeHead `json:"new"`
+	Path []string  `json:"path"`
+}
+
+// ProveConsistency proves the newer tree head extends the older one
+func ProveConsistency(log Log, old, new *TreeHead) (*Consistency, error) {
+	path, err := log.ConsistencyProof(old.Size, new.Size)
+	if err != nil {
+		return nil, err
+	}
+	return &Consistency{Old: old, New: new, Path: encodePath(path)}, nil
+}
+
+// Verify checks both tree heads (against operator, if given) and the proof
+func (p *Consistency) Verify(operator string) error {
+	if p.Old == nil || p.New == nil {
+		return fmt.Errorf("consistency proof needs two tree heads")
+	}
+	for _, h := range []*TreeHead{p.Old, p.New} {
+		if err := h.Verify(operator); err != nil {
+			return err
+		}
+	}
+	path, err := decodePath(p.Path)
+	if err != nil {
+		return err
+	}
+	oldRoot, _ := decodeHash(p.Old.Root)
+	newRoot, _ := decodeHash(p.New.Root)
+	if err := verifyConsistency(p.Old.Size, p.New.Size, path, oldRoot, newRoot); err != nil {
+		return fmt.Errorf("tree %d → %d: %w", p.Old.Size, p.New.Size, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package receipt
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// The signed tree head of the RFC 9162 reference tree at size 8
+var rfcTreeHead = TreeHead{
+	Size:      8,
+	Root:      "5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	Timestamp: 1700000000,
+	Operator:  "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+	Signature: "b8c2c31b99b4df633f21d2e480c9cf2e8fb7cd72f5558f26aa6e94eb6553d1a4" +
+		"6e8daf1194190ad4e46496e0a1bc8074b812d699a654c4fd27a8d215cc88d803",
+}
+
+func TestTreeHeadSignatureVector(t *testing.T) {
+	op := rfc8032Operator(t)
+	h := rfcTreeHead
+
+	want := "00000012" + hex.EncodeToString([]byte("osovm/tree-head/v1")) +
+		"00000001" + hex.EncodeToString([]byte("8")) +
+		"00000040" + hex.EncodeToString([]byte(h.Root)) +
+		"0000000a" + hex.EncodeToString([]byte("1700000000")) +
+		"00000040" + hex.EncodeToString([]byte(h.Operator))
+	if got := hex.EncodeToString(h.Message()); got != want {
+		t.Errorf("message\n%s\nwant\n%s", got, want)
+	}
+
+	// Ed25519 is deterministic, so signing reproduces the vector
+	if sig := hex.EncodeToString(ed25519.Sign(op.privateKey, h.Message())); sig != h.Signature {
+		t.Errorf("signature %s", sig)
+	}
+	if err := h.Verify(op.PublicKey); err != nil {
+		t.Errorf("vector: %v", err)
+	}
+	if err := h.Verify(""); err != nil {
+		t.Errorf("without a trusted operator: %v", err)
+	}
+}
+
+func TestTreeHeadVerifyRejects(t *testing.T) {
+	other, _ := GenerateOperator()
+	for _, tc := range []struct {
+		name     string
+		mutate   func(h *TreeHead)
+		operator string
+		err      string
+	}{
+		{"size", func(h *TreeHead) { h.Size = 9 }, "", "tree head 9: invalid operator signature"},
+		{"root", func(h *TreeHead) { h.Root = rfcRoots[6] }, "", "invalid operator signature"},
+		{"timestamp", func(h *TreeHead) { h.Timestamp++ }, "", "invalid operator signature"},
+		{"signature", func(h *TreeHead) { h.Signature = h.Signature[:126] + "00" }, "", "invalid operator signature"},
+		{"signature hex", func(h *TreeHead) { h.Signature = "zz" }, "", "invalid operator signature"},
+		{"operator key", func(h *TreeHead) { h.Operator = other.PublicKey }, "", "invalid operator signature"},
+		{"untrusted operator", func(h *TreeHead) {}, other.PublicKey, "not the trusted operator"},
+	} {
+		h := rfcTreeHead
+		tc.mutate(&h)
+		if err := h.Verify(tc.operator); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.err)
+		}
+	}
+
+	// A well-signed head must still commit to a real hash
+	op := rfc8032Operator(t)
+	h := TreeHead{Size: 1, Root: "abc", Timestamp: 1, Operator: op.PublicKey}
+	h.Signature = hex.EncodeToString(ed25519.Sign(op.privateKey, h.Message()))
+	if err := h.Verify(op.PublicKey); err == nil || !strings.Contains(err.Error(), `invalid hash "abc"`) {
+		t.Errorf("short root: %v", err)
+	}
+}
+
+func TestProofBundlesVerifyAgainstSignedHeads(t *testing.T) {
+	op := rfc8032Operator(t)
+	log := signedLog(t, op, 7)
+
+	old, err := SignTreeHead(log, 3, op, 1700000100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := SignTreeHead(log, 7, op, 1700000200)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for seq := uint64(1); seq <= 7; seq++ {
+		p, err := ProveInclusion(log, seq, head)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Verify(op.PublicKey); err != nil {
+			t.Errorf("receipt %d: %v", seq, err)
+		}
+	}
+	c, err := ProveConsistency(log, old, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Verify(op.PublicKey); err != nil {
+		t.Errorf("consistency: %v", err)
+	}
+
+	// A receipt re-signed with other content is not the one in the tree
+	p, _ := ProveInclusion(log, 2, head)
+	forged := *p.Receipt
+	forged.Result = json.RawMessage(`"forged"`)
+	forged.Sign(op)
+	p.Receipt = &forged
+	if err := p.Verify(op.PublicKey); err == nil || !strings.Contains(err.Error(), "does not lead to the tree root") {
+		t.Errorf("forged receipt: %v", err)
+	}
+
+	// Nor is a signed head over a different root consistent with the old one
+	rewritten := *head
+	rewritten.Root = strings.Repeat("0", 64)
+	rewritten.Signature = hex.EncodeToString(ed25519.Sign(op.privateKey, rewritten.Message()))
+	c.New = &rewritten
+	if err := c.Verify(op.PublicKey); err == nil || !strings.Contains(err.Error(), "tree 3 → 7") {
+		t.Errorf("rewritten log: %v", err)
+	}
+	// And a head from another operator is refused outright
+	other, _ := GenerateOperator()
+	foreign, _ := SignTreeHead(log, 7, other, 1700000200)
+	c.New = foreign
+	if err := c.Verify(op.PublicKey); err == nil || !strings.Contains(err.Error(), "not the trusted operator") {
+		t.Errorf("foreign head: %v", err)
+	}
+}
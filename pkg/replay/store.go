@@ -0,0 +1,189 @@
+// OSOVM Replay Protection
+// Remembers which proof receipts each ritual has already accepted
+
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ase-lang/osovm/internal/atomicfile"
+)
+
+// ErrReplay is returned when a receipt was already used for the ritual
+var ErrReplay = errors.New("proof receipt already used")
+
+// Store records accepted receipts per ritual
+type Store interface {
+	// Claim records receipt for ritual at unix time at, or returns an error
+	// wrapping ErrReplay if it was recorded before
+	Claim(ritual, receipt string, at int64) error
+	// Release forgets a claim, so a receipt whose execution failed can be
+	// used again
+	Release(ritual, receipt string) error
+	// Prune forgets receipts recorded before unix time before
+	Prune(before int64) error
+}
+
+// ========== In-Memory Store ==========
+
+// MemoryStore - A Store that lasts as long as the process
+type MemoryStore struct {
+	mu   sync.Mutex
+	seen map[string]map[string]int64 // ritual → receipt → claimed at
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{seen: make(map[string]map[string]int64)}
+}
+
+func (s *MemoryStore) Claim(ritual, receipt string, at int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return claim(s.seen, ritual, receipt, at)
+}
+
+func (s *MemoryStore) Release(ritual, receipt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	release(s.seen, ritual, receipt)
+	return nil
+}
+
+func (s *MemoryStore) Prune(before int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prune(s.seen, before)
+	return nil
+}
+
+// ========== File Store ==========
+
+// FileStore - A Store persisted as JSON, rewritten atomically on every change
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	seen map[string]map[string]int64
+}
+
+type storeFile struct {
+	Version int                         `json:"version"`
+	Rituals map[string]map[string]int64 `json:"rituals"`
+}
+
+const storeVersion = 1
+
+// OpenFileStore loads the store at path. A missing file is an empty store.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, seen: make(map[string]map[string]int64)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipt store: %w", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse receipt store %s: %w", path, err)
+	}
+	if file.Version != storeVersion {
+		return nil, fmt.Errorf("receipt store %s: unsupported version %d", path, file.Version)
+	}
+	if file.Rituals != nil {
+		s.seen = file.Rituals
+	}
+	return s, nil
+}
+
+func (s *FileStore) Claim(ritual, receipt string, at int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := claim(s.seen, ritual, receipt, at); err != nil {
+		return err
+	}
+	if err := s.save(); err != nil {
+		delete(s.seen[ritual], receipt)
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) Release(ritual, receipt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, claimed := s.seen[ritual][receipt]
+	if !claimed {
+		return nil
+	}
+	release(s.seen, ritual, receipt)
+	if err := s.save(); err != nil {
+		claim(s.seen, ritual, receipt, at)
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) Prune(before int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prune(s.seen, before) == 0 {
+		return nil
+	}
+	return s.save()
+}
+
+func (s *FileStore) save() error {
+	data, err := json.MarshalIndent(storeFile{Version: storeVersion, Rituals: s.seen}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := atomicfile.Write(s.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to save receipt store: %w", err)
+	}
+	return nil
+}
+
+// ========== Helpers ==========
+
+func claim(seen map[string]map[string]int64, ritual, receipt string, at int64) error {
+	receipts := seen[ritual]
+	if receipts == nil {
+		receipts = make(map[string]int64)
+		seen[ritual] = receipts
+	}
+	if _, used := receipts[receipt]; used {
+		return fmt.Errorf("%w for ritual '%s'", ErrReplay, ritual)
+	}
+	receipts[receipt] = at
+	return nil
+}
+
+func release(seen map[string]map[string]int64, ritual, receipt string) {
+	delete(seen[ritual], receipt)
+	if len(seen[ritual]) == 0 {
+		delete(seen, ritual)
+	}
+}
+
+func prune(seen map[string]map[string]int64, before int64) int {
+	removed := 0
+	for ritual, receipts := range seen {
+		for receipt, at := range receipts {
+			if at < before {
+				delete(receipts, receipt)
+				removed++
+			}
+		}
+		if len(receipts) == 0 {
+			delete(seen, ritual)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,63 @@
+package replay
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestClaimReleasePrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "receipts.json")
+	file, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "file": file} {
+		if err := s.Claim("pay", "r1", 100); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := s.Claim("pay", "r1", 101); !errors.Is(err, ErrReplay) {
+			t.Errorf("%s: second claim: %v", name, err)
+		}
+		// Receipts are per ritual
+		if err := s.Claim("ship", "r1", 100); err != nil {
+			t.Errorf("%s: other ritual: %v", name, err)
+		}
+
+		// A released receipt can be claimed again; releasing twice is harmless
+		if err := s.Release("pay", "r1"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := s.Release("pay", "r1"); err != nil {
+			t.Errorf("%s: second release: %v", name, err)
+		}
+		if err := s.Claim("pay", "r1", 102); err != nil {
+			t.Errorf("%s: claim after release: %v", name, err)
+		}
+
+		if err := s.Prune(101); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Claim("ship", "r1", 103); err != nil {
+			t.Errorf("%s: claim after prune: %v", name, err)
+		}
+		if err := s.Claim("pay", "r1", 103); !errors.Is(err, ErrReplay) {
+			t.Errorf("%s: prune forgot a newer claim: %v", name, err)
+		}
+	}
+
+	// Claims and releases persist
+	if err := file.Release("ship", "r1"); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Claim("pay", "r1", 104); !errors.Is(err, ErrReplay) {
+		t.Errorf("claim was not saved: %v", err)
+	}
+	if err := reopened.Claim("ship", "r1", 104); err != nil {
+		t.Errorf("release was not saved: %v", err)
+	}
+}
@@ -0,0 +1,1171 @@
+// OSOVM Phase 2: Attribute System (94 Canon Attributes, 58 Extensions)
+// Universal device support with validators
+
+package vm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ase-lang/osovm/pkg/proofkind"
+)
+
+// Validator - Implemented by every attribute struct. Validate checks the
+// canon constraints the grammar can't express (tithe 369, vote weight 1.0, ...).
+type Validator interface {
+	Validate() error
+}
+
+// noConstraints is embedded by attributes whose grammar rule is the only constraint
+type noConstraints struct{}
+
+func (noConstraints) Validate() error { return nil }
+
+// ========== Core Attribute Structs ==========
+
+// Universal Àṣẹ with auto-device detection
+type UniversalAse struct {
+	ProofType  string `json:"proof"`
+	Witnesses  int    `json:"witnesses"`
+	QR         bool   `json:"qr,omitempty"`
+	AutoDevice bool   `json:"auto_device,omitempty"`
+}
+
+// Device types
+type DeviceAttr struct {
+	Type string `json:"type"` // drone, phone, av, robot, sensor, camera, scanner
+	ID   string `json:"id"`
+}
+
+// Proof & Witness (Full canon uses kind/receipt, Phase 2 type/hash)
+type ProofAttr struct {
+	Kind    string `json:"kind,omitempty"`
+	Receipt string `json:"receipt,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Hash    string `json:"hash,omitempty"`
+}
+
+type WitnessAttr struct {
+	noConstraints
+	Count   int    `json:"count"`
+	Network string `json:"network,omitempty"`
+}
+
+type ReceiptAttr struct {
+	Kind      string `json:"kind"` // composite, simple, zk
+	Hash      string `json:"hash"`
+	Pointer   string `json:"pointer"`
+	Verifiers int    `json:"verifiers"`
+}
+
+type TelemetryAttr struct {
+	noConstraints
+	Device string `json:"device"`
+}
+
+// Governance
+type VoteAttr struct {
+	Weight  float64   `json:"weight,omitempty"`  // Must be 1.0
+	Weights []float64 `json:"weights,omitempty"` // Full canon form; each must be 1.0
+	Quorum  int       `json:"quorum,omitempty"`
+}
+
+type QuorumAttr struct {
+	Required int `json:"required"`
+}
+
+type ConsensusAttr struct {
+	Threshold float64 `json:"threshold"`
+}
+
+type VetoAttr struct {
+	Authority string `json:"authority"`
+}
+
+// Economic
+type TitheAttr struct {
+	Rate   int    `json:"rate"` // Must be 369
+	Wallet string `json:"wallet,omitempty"`
+}
+
+type FeeAttr struct {
+	noConstraints
+	Amount float64 `json:"amount"`
+	Token  string  `json:"token"`
+}
+
+type VaultAttr struct {
+	Balance float64 `json:"balance"`
+}
+
+type SplitAttr struct {
+	noConstraints
+	Shares string `json:"shares"`
+}
+
+// Hardware/Sensors
+type GPSAttr struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type IMUAttr struct {
+	noConstraints
+	Accel string `json:"accel"`
+	Gyro  string `json:"gyro"`
+}
+
+type BatteryAttr struct {
+	Level int `json:"level"`
+}
+
+type SensorAttr struct {
+	noConstraints
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+}
+
+// QR/Scanning
+type QRAttr struct {
+	Hash      string `json:"hash"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type NFCAttr struct {
+	noConstraints
+	TagID string `json:"tag_id"`
+}
+
+type RFIDAttr struct {
+	noConstraints
+	TagID string `json:"tag_id"`
+}
+
+type BarcodeAttr struct {
+	noConstraints
+	Data string `json:"data"`
+}
+
+// Network/Mesh
+type LoRaAttr struct {
+	noConstraints
+	Freq  float64 `json:"freq"`
+	Power int     `json:"power"`
+}
+
+type BLEAttr struct {
+	noConstraints
+	UUID string `json:"uuid"`
+}
+
+type MeshAttr struct {
+	noConstraints
+	Topology string `json:"topology"`
+	Nodes    int    `json:"nodes"`
+}
+
+type P2PAttr struct {
+	noConstraints
+	PeerID string `json:"peer_id"`
+}
+
+// Temporal
+type TimestampAttr struct {
+	noConstraints
+	Unix int64 `json:"unix"`
+}
+
+type DurationAttr struct {
+	noConstraints
+	Seconds int `json:"seconds"`
+}
+
+type DeadlineAttr struct {
+	noConstraints
+	Unix int64 `json:"unix"`
+}
+
+type ScheduleAttr struct {
+	noConstraints
+	Cron string `json:"cron"`
+}
+
+// Security
+type SignatureAttr struct {
+	noConstraints
+	PubKey string `json:"pubkey"`
+	Sig    string `json:"sig"`
+}
+
+type EncryptionAttr struct {
+	noConstraints
+	Algo string `json:"algo"`
+}
+
+type AccessAttr struct {
+	noConstraints
+	Role string `json:"role"`
+}
+
+type MaskAttr struct {
+	noConstraints
+	Fields string `json:"fields"`
+}
+
+// Environmental
+type TempAttr struct {
+	noConstraints
+	Celsius float64 `json:"celsius"`
+}
+
+type HumidityAttr struct {
+	noConstraints
+	Percent float64 `json:"percent"`
+}
+
+type PressureAttr struct {
+	noConstraints
+	KPa float64 `json:"kpa"`
+}
+
+type LightAttr struct {
+	noConstraints
+	Lux float64 `json:"lux"`
+}
+
+// Media
+type VideoAttr struct {
+	noConstraints
+	URI  string `json:"uri"`
+	Hash string `json:"hash"`
+}
+
+type AudioAttr struct {
+	noConstraints
+	URI  string `json:"uri"`
+	Hash string `json:"hash"`
+}
+
+type ImageAttr struct {
+	noConstraints
+	URI  string `json:"uri"`
+	Hash string `json:"hash"`
+}
+
+type StreamAttr struct {
+	noConstraints
+	URL string `json:"url"`
+}
+
+// Storage
+type IPFSAttr struct {
+	CID string `json:"cid"`
+}
+
+type ArweaveAttr struct {
+	noConstraints
+	TxID string `json:"txid"`
+}
+
+type CacheAttr struct {
+	TTL int `json:"ttl"`
+}
+
+type BackupAttr struct {
+	noConstraints
+	URI string `json:"uri"`
+}
+
+// Blockchain
+type BitcoinAttr struct {
+	TxID string `json:"txid"`
+}
+
+type EthereumAttr struct {
+	TxHash string `json:"txhash"`
+}
+
+type SolanaAttr struct {
+	noConstraints
+	Signature string `json:"signature"`
+}
+
+type SuiAttr struct {
+	noConstraints
+	Digest string `json:"digest"`
+}
+
+// Herb/Resource (Òsanyìn). Full canon uses key/class, Phase 2 name/potency.
+type HerbAttr struct {
+	noConstraints
+	Key     string  `json:"key,omitempty"`
+	Class   string  `json:"class,omitempty"`
+	Name    string  `json:"name,omitempty"`
+	Potency float64 `json:"potency,omitempty"`
+}
+
+type RegionalAttr struct {
+	noConstraints
+	Locale string `json:"locale"`
+}
+
+type ResourceAttr struct {
+	noConstraints
+	Type   string  `json:"type"` // linear, acquire
+	Amount float64 `json:"amount,omitempty"`
+}
+
+type InventoryAttr struct {
+	noConstraints
+	Items string `json:"items"`
+}
+
+// Oracle/Divination (Ọrúnmìlà)
+type OracleAttr struct {
+	noConstraints
+	Query string `json:"query"`
+}
+
+type DivinationAttr struct {
+	noConstraints
+	Seed int64 `json:"seed"`
+}
+
+type PredictionAttr struct {
+	noConstraints
+	Model      string  `json:"model"`
+	Confidence float64 `json:"confidence"`
+}
+
+// AI/ML
+type ModelAttr struct {
+	noConstraints
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type InferenceAttr struct {
+	noConstraints
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+type TrainingAttr struct {
+	noConstraints
+	Epochs int     `json:"epochs"`
+	Loss   float64 `json:"loss"`
+}
+
+// Logistics. Full canon tracks id/uri/checksum/state, Phase 2 from/to.
+type DeliveryAttr struct {
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	ID       string `json:"id,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+	State    string `json:"state,omitempty"`
+}
+
+type RouteAttr struct {
+	noConstraints
+	Waypoints string `json:"waypoints"`
+}
+
+type TrackingAttr struct {
+	noConstraints
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type CheckpointAttr struct {
+	noConstraints
+	Location string `json:"location"`
+	Time     int64  `json:"time"`
+}
+
+// Manufacturing
+type AssemblyAttr struct {
+	noConstraints
+	Step      int    `json:"step"`
+	Component string `json:"component"`
+}
+
+type QualityAttr struct {
+	noConstraints
+	Score     float64 `json:"score"`
+	Inspector string  `json:"inspector"`
+}
+
+type BatchAttr struct {
+	noConstraints
+	ID   string `json:"id"`
+	Size int    `json:"size"`
+}
+
+// Identity
+type DIDAttr struct {
+	Identifier string `json:"identifier"`
+}
+
+type CredentialAttr struct {
+	noConstraints
+	Type   string `json:"type"`
+	Issuer string `json:"issuer"`
+}
+
+type BiometricAttr struct {
+	noConstraints
+	Type string `json:"type"`
+	Hash string `json:"hash"`
+}
+
+// ========== Full Canon Attribute Structs (ORISA_FULL.pest) ==========
+
+// SimaaS & AIO Jobs
+type SimAttr struct {
+	noConstraints
+	EpisodeID int `json:"episode_id"`
+	Steps     int `json:"steps"`
+}
+
+type JobAttr struct {
+	Type     string `json:"type"` // digital, meta_digital, physical
+	Title    string `json:"title"`
+	Budget   int    `json:"budget"`
+	Requires string `json:"requires"`
+}
+
+type HardwareAttr struct {
+	noConstraints
+	EstopCheck bool `json:"estop_check"`
+}
+
+type CollaborationAttr struct {
+	noConstraints
+	Actors []string `json:"actors"`
+	Roles  []string `json:"roles"`
+}
+
+type AccountabilityAttr struct {
+	Metric string `json:"metric"`
+}
+
+type OfferingAttr struct {
+	noConstraints
+	Amount int    `json:"amount"`
+	Shrine string `json:"shrine"`
+}
+
+type SimFundAttr struct {
+	noConstraints
+	UserFee   int `json:"user_fee"`
+	RateLimit int `json:"rate_limit"`
+}
+
+type CircuitBreakerAttr struct {
+	noConstraints
+	MaxAttempts int `json:"max_attempts"`
+	CooldownMS  int `json:"cooldown_ms"`
+}
+
+// Governance & Safety
+type DisputeAttr struct {
+	noConstraints
+	Window int `json:"window"`
+	Quorum int `json:"quorum"`
+}
+
+type SlashAttr struct {
+	Amount int    `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+type WalletAttr struct {
+	noConstraints
+	Balance int      `json:"balance"`
+	Stake   int      `json:"stake"`
+	Caps    []string `json:"caps"`
+	Policy  string   `json:"policy"`
+}
+
+type IdentityAttr struct {
+	Kind   string `json:"kind"` // did, ens, satname, npc
+	Issuer string `json:"issuer"`
+}
+
+type SwarmAttr struct {
+	noConstraints
+	Plan string `json:"plan"`
+}
+
+type PeerAttr struct {
+	noConstraints
+	Target string `json:"target"`
+	Task   string `json:"task"`
+}
+
+type FlowValidateAttr struct {
+	noConstraints
+	Strict bool `json:"strict"`
+}
+
+// Native VM Extensions
+type MemoryAttr struct {
+	Kind    string `json:"kind"`  // ephemeral, durable, vector, graph
+	Index   string `json:"index"` // faiss, pinecone, weaviate, none
+	Pointer string `json:"pointer"`
+}
+
+type LearnAttr struct {
+	Schedule string `json:"schedule"`
+	From     string `json:"from"`
+	Method   string `json:"method"`
+}
+
+type ComputeAttr struct {
+	GPU      string `json:"gpu"`
+	Hours    int    `json:"hours"`
+	Provider string `json:"provider"`
+}
+
+type VerifierAttr struct {
+	Role   string `json:"role"` // steward, witness, council
+	Quorum int    `json:"quorum"`
+}
+
+type MultisigAttr struct {
+	noConstraints
+	Quorum  int      `json:"quorum"`
+	Signers []string `json:"signers"`
+}
+
+type PolicyAttr struct {
+	Rule string `json:"rule"`
+}
+
+type CouncilAttr struct {
+	Quorum int    `json:"quorum"`
+	Role   string `json:"role"` // tech, spirit, safety
+}
+
+// Both @beats forms: cadence (beats_attr) and alignment (beats_aligned_attr)
+type BeatsAttr struct {
+	noConstraints
+	BeatMS     int  `json:"beat_ms,omitempty"`
+	Microbatch int  `json:"microbatch,omitempty"`
+	Prefill    int  `json:"prefill,omitempty"`
+	KVWarmMS   int  `json:"kv_warm_ms,omitempty"`
+	Aligned    bool `json:"aligned,omitempty"`
+}
+
+type MaintenanceAttr struct {
+	Day string `json:"day"`
+}
+
+type CogAttr struct {
+	Memory string `json:"memory"`
+}
+
+type NPCAttr struct {
+	noConstraints
+	Wallet string `json:"wallet"`
+}
+
+// Entertainment Sector Extensions
+type ProjectAttr struct {
+	noConstraints
+	ID         string   `json:"id"`
+	Sector     string   `json:"sector"`
+	Budget     int      `json:"budget"`
+	Milestones []string `json:"milestones"`
+}
+
+type MilestoneAttr struct {
+	noConstraints
+	ID           string   `json:"id"`
+	Amount       int      `json:"amount"`
+	Due          string   `json:"due"`
+	Deliverables []string `json:"deliverables"`
+}
+
+type CastingAttr struct {
+	noConstraints
+	ID      string `json:"id"`
+	Role    string `json:"role"`
+	DayRate int    `json:"dayRate"`
+}
+
+type CallsheetAttr struct {
+	noConstraints
+	ID        string `json:"id"`
+	ProjectID string `json:"projectId"`
+	ShootDate string `json:"shootDate"`
+	CallTimes string `json:"callTimes"`
+}
+
+type ReleaseAttr struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"`
+	FileHash string `json:"fileHash"`
+}
+
+type LicenseAttr struct {
+	noConstraints
+	ID     string   `json:"id"`
+	WorkID string   `json:"workId"`
+	Rights []string `json:"rights"`
+	Term   string   `json:"term"`
+}
+
+type RoyaltyAttr struct {
+	noConstraints
+	ID       string   `json:"id"`
+	Triggers []string `json:"triggers"`
+	Splits   string   `json:"splits"`
+}
+
+type SafetyAttr struct {
+	noConstraints
+	ID           string   `json:"id"`
+	Items        []string `json:"items"`
+	SignedByVisa string   `json:"signedByVisa"`
+}
+
+type PermitAttr struct {
+	noConstraints
+	ID        string `json:"id"`
+	Authority string `json:"authority"`
+	ValidFrom string `json:"validFrom"`
+	ValidTo   string `json:"validTo"`
+}
+
+type ComplianceAttr struct {
+	noConstraints
+	ID            string `json:"id"`
+	Union         string `json:"union"`
+	MinorsPresent bool   `json:"minorsPresent"`
+}
+
+type AssetRentalAttr struct {
+	noConstraints
+	ID        string `json:"id"`
+	AssetID   string `json:"assetId"`
+	ProjectID string `json:"projectId"`
+	Rate      int    `json:"rate"`
+}
+
+type AssetLineageAttr struct {
+	noConstraints
+	AssetID string   `json:"assetId"`
+	History []string `json:"history"`
+}
+
+// Flow/Workflow
+type FlowAttr struct {
+	noConstraints
+	ID string `json:"id"`
+}
+
+type RetryAttr struct {
+	noConstraints
+	Policy string `json:"policy"`
+}
+
+type SagaAttr struct {
+	noConstraints
+	Compensate string `json:"compensate"`
+}
+
+type AIAttr struct {
+	noConstraints
+	Policy string `json:"policy"`
+}
+
+type ReviewAttr struct {
+	noConstraints
+	Witness int `json:"witness"`
+}
+
+type PresenceAttr struct {
+	noConstraints
+	Nexus string `json:"nexus"`
+}
+
+// Interop/Anchoring
+type ImportAttr struct {
+	Chain    string `json:"chain"` // btc, eth, sol, ar, sui
+	Finality string `json:"finality"`
+}
+
+type ABIAttr struct {
+	noConstraints
+	CBORDeterministic bool   `json:"cbor_deterministic"`
+	Version           string `json:"version"`
+}
+
+type BridgeAttr struct {
+	noConstraints
+	Target string `json:"target"`
+}
+
+// Astral-Telluric Grid
+type AstroAttr struct {
+	noConstraints
+	Cycle string `json:"cycle"`
+}
+
+type LeyAttr struct {
+	noConstraints
+	Location string `json:"location"`
+}
+
+type NexusAttr struct {
+	noConstraints
+	Anchor string `json:"anchor"`
+}
+
+type GeofenceAttr struct {
+	noConstraints
+	Bounds string `json:"bounds"`
+}
+
+type BoostAttr struct {
+	noConstraints
+	Multiplier float64 `json:"multiplier"`
+}
+
+// SatKey/Ordinals
+type OrdinalGuardAttr struct {
+	noConstraints
+	Sat int64 `json:"sat"`
+}
+
+type InscriptionAttr struct {
+	noConstraints
+	ID string `json:"id"`
+}
+
+type SatnameAttr struct {
+	noConstraints
+	Name string `json:"name"`
+}
+
+type RightsAttr struct {
+	noConstraints
+	Permissions string `json:"permissions"`
+}
+
+type PlaybackAttr struct {
+	noConstraints
+	Replay bool `json:"replay"`
+}
+
+type PSBTPolicyAttr struct {
+	noConstraints
+	Rule string `json:"rule"`
+}
+
+type BundleAttr struct {
+	noConstraints
+	Rituals []string `json:"rituals"`
+}
+
+type ManifestAttr struct {
+	noConstraints
+	Metadata string `json:"metadata"`
+}
+
+// ZK & Numerology
+type ZKAttr struct {
+	noConstraints
+	Verify  string `json:"verify"`
+	Circuit string `json:"circuit"`
+}
+
+type ZKBindAttr struct {
+	noConstraints
+	Ctx bool `json:"ctx"`
+}
+
+type VeilAttr struct {
+	noConstraints
+	Constant string `json:"constant"`
+}
+
+// Economic/Treasury
+type TreasurySplitAttr struct {
+	noConstraints
+	Shares string `json:"shares"`
+}
+
+type ShrineSplitAttr struct {
+	Distribution string `json:"distribution"` // 50/25/15/10
+}
+
+type FixedMathAttr struct {
+	noConstraints
+	Precision int `json:"precision"`
+}
+
+type BurstAttr struct {
+	noConstraints
+	Threads int `json:"threads"`
+}
+
+type TOCAttr struct {
+	noConstraints
+	Reward int `json:"reward"`
+	Stake  int `json:"stake"`
+	Spend  int `json:"spend"`
+}
+
+// Governance Extensions
+type NonReentrantAttr struct{ noConstraints }
+
+type RequiresAttr struct {
+	noConstraints
+	Condition string `json:"condition"`
+}
+
+type EnsuresAttr struct {
+	noConstraints
+	Postcondition string `json:"postcondition"`
+}
+
+type AuditAttr struct {
+	noConstraints
+	Level string `json:"level"`
+}
+
+type SabbathAttr struct {
+	noConstraints
+	Day string `json:"day"`
+}
+
+type LimitsAttr struct {
+	noConstraints
+	Constraints string `json:"constraints"`
+}
+
+type AlignAttr struct {
+	noConstraints
+	Spec string `json:"spec"`
+}
+
+type TemporalAttr struct {
+	noConstraints
+	Rule string `json:"rule"`
+}
+
+type TrustedCallerAttr struct {
+	noConstraints
+	Address string `json:"address"`
+}
+
+type SyncGuardAttr struct{ noConstraints }
+
+type GasLimitAttr struct {
+	Max int64 `json:"max"`
+}
+
+type WhitegateAttr struct {
+	noConstraints
+	Quorum int `json:"quorum"`
+}
+
+type OracleBondAttr struct {
+	noConstraints
+	Amount int `json:"amount"`
+}
+
+type SectorAttr struct {
+	noConstraints
+	Name string `json:"name"`
+}
+
+// ========== Validators ==========
+
+func (a *UniversalAse) Validate() error {
+	if !proofkind.Known(a.ProofType) {
+		return fmt.Errorf("invalid proof type: %s", a.ProofType)
+	}
+
+	if a.Witnesses < 0 {
+		return fmt.Errorf("witnesses must be >= 0")
+	}
+
+	return nil
+}
+
+func (a *DeviceAttr) Validate() error {
+	validTypes := map[string]bool{
+		"drone": true, "phone": true, "av": true, "robot": true,
+		"sensor": true, "camera": true, "scanner": true,
+	}
+
+	if !validTypes[a.Type] {
+		return fmt.Errorf("invalid device type: %s", a.Type)
+	}
+
+	if a.ID == "" {
+		return fmt.Errorf("device ID cannot be empty")
+	}
+
+	return nil
+}
+
+func (a *VoteAttr) Validate() error {
+	if len(a.Weights) > 0 {
+		for _, w := range a.Weights {
+			if w != 1.0 {
+				return fmt.Errorf("vote weight must be exactly 1.0 (got %.2f)", w)
+			}
+		}
+		return nil
+	}
+	if a.Weight != 1.0 {
+		return fmt.Errorf("vote weight must be exactly 1.0 (got %.2f)", a.Weight)
+	}
+	return nil
+}
+
+func (a *TitheAttr) Validate() error {
+	if a.Rate != 369 {
+		return fmt.Errorf("tithe rate must be exactly 369 (got %d)", a.Rate)
+	}
+	return nil
+}
+
+// Units returns the balance in whole ledger units. Fractions, negatives
+// and values too large to hold exactly are refused, never truncated.
+func (a *VaultAttr) Units() (int64, error) {
+	n, err := wholeAmount(a.Balance)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("vault balance must be a whole, non-negative amount (got %g)", a.Balance)
+	}
+	return n, nil
+}
+
+func (a *VaultAttr) Validate() error {
+	_, err := a.Units()
+	return err
+}
+
+func (a *SlashAttr) Validate() error {
+	if a.Amount <= 0 {
+		return fmt.Errorf("slash amount must be positive (got %d)", a.Amount)
+	}
+	return nil
+}
+
+func (a *QuorumAttr) Validate() error {
+	if a.Required < 1 {
+		return fmt.Errorf("quorum must require at least one witness (got %d)", a.Required)
+	}
+	return nil
+}
+
+func (a *VetoAttr) Validate() error {
+	if a.Authority == "" {
+		return fmt.Errorf("veto needs an authority")
+	}
+	return nil
+}
+
+func (a *ConsensusAttr) Validate() error {
+	if a.Threshold <= 0 || a.Threshold > 1 {
+		return fmt.Errorf("consensus threshold must be in (0, 1] (got %g)", a.Threshold)
+	}
+	return nil
+}
+
+func (a *CacheAttr) Validate() error {
+	if a.TTL <= 0 {
+		return fmt.Errorf("cache ttl must be positive (got %d)", a.TTL)
+	}
+	return nil
+}
+
+func (a *GasLimitAttr) Validate() error {
+	if a.Max <= 0 {
+		return fmt.Errorf("gas limit must be positive (got %d)", a.Max)
+	}
+	return nil
+}
+
+func (a *GPSAttr) Validate() error {
+	if a.Lat < -90 || a.Lat > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if a.Lon < -180 || a.Lon > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+func (a *BatteryAttr) Validate() error {
+	if a.Level < 0 || a.Level > 100 {
+		return fmt.Errorf("battery level must be between 0 and 100")
+	}
+	return nil
+}
+
+func (a *QRAttr) Validate() error {
+	if !isValidHash(a.Hash) {
+		return fmt.Errorf("invalid QR hash format")
+	}
+	if a.Timestamp <= 0 {
+		return fmt.Errorf("timestamp must be positive")
+	}
+	return nil
+}
+
+func (a *IPFSAttr) Validate() error {
+	// IPFS CID validation (simplified)
+	matched, _ := regexp.MatchString(`^Qm[1-9A-HJ-NP-Za-km-z]{44}`, a.CID)
+	if !matched {
+		return fmt.Errorf("invalid IPFS CID format")
+	}
+	return nil
+}
+
+func (a *BitcoinAttr) Validate() error {
+	if len(a.TxID) != 64 {
+		return fmt.Errorf("Bitcoin txid must be 64 characters")
+	}
+	return nil
+}
+
+func (a *EthereumAttr) Validate() error {
+	matched, _ := regexp.MatchString(`^0x[0-9a-fA-F]{64}$`, a.TxHash)
+	if !matched {
+		return fmt.Errorf("invalid Ethereum transaction hash")
+	}
+	return nil
+}
+
+func (a *DIDAttr) Validate() error {
+	matched, _ := regexp.MatchString(`^did:[a-z]+:.+`, a.Identifier)
+	if !matched {
+		return fmt.Errorf("invalid DID format (must be did:method:identifier)")
+	}
+	return nil
+}
+
+func (a *ProofAttr) Validate() error {
+	kind := a.Kind
+	if kind == "" {
+		kind = a.Type
+	}
+	if !proofkind.Known(kind) {
+		return fmt.Errorf("invalid proof kind: %s", kind)
+	}
+	if a.Receipt != "" {
+		return proofkind.VerifyReceipt(kind, a.Receipt)
+	}
+	return nil
+}
+
+func (a *ReceiptAttr) Validate() error {
+	if err := oneOf("receipt kind", a.Kind, "composite", "simple", "zk"); err != nil {
+		return err
+	}
+	if !isValidHash(a.Hash) {
+		return fmt.Errorf("receipt hash must be a 64-character hex hash")
+	}
+	return nil
+}
+
+func (a *JobAttr) Validate() error {
+	return oneOf("job type", a.Type, "digital", "meta_digital", "physical")
+}
+
+func (a *AccountabilityAttr) Validate() error {
+	return oneOf("accountability metric", a.Metric, "who_drove_what")
+}
+
+func (a *IdentityAttr) Validate() error {
+	return oneOf("identity kind", a.Kind, "did", "ens", "satname", "npc")
+}
+
+func (a *MemoryAttr) Validate() error {
+	if err := oneOf("memory kind", a.Kind, "ephemeral", "durable", "vector", "graph"); err != nil {
+		return err
+	}
+	return oneOf("memory index", a.Index, "faiss", "pinecone", "weaviate", "none")
+}
+
+func (a *LearnAttr) Validate() error {
+	if err := oneOf("learn schedule", a.Schedule, "daily", "cron"); err != nil {
+		return err
+	}
+	if err := oneOf("learn source", a.From, "receipts"); err != nil {
+		return err
+	}
+	return oneOf("learn method", a.Method, "rlhf", "batch")
+}
+
+func (a *ComputeAttr) Validate() error {
+	if err := oneOf("compute gpu", a.GPU, "h100", "a100", "v100", "t4", "rtx4090"); err != nil {
+		return err
+	}
+	return oneOf("compute provider", a.Provider, "vast", "runpod", "lambda")
+}
+
+func (a *VerifierAttr) Validate() error {
+	return oneOf("verifier role", a.Role, "steward", "witness", "council")
+}
+
+func (a *PolicyAttr) Validate() error {
+	return oneOf("policy rule", a.Rule,
+		"min_stake", "max_spend", "task_caps", "resource_caps", "hardware_safety", "resource_limits")
+}
+
+func (a *CouncilAttr) Validate() error {
+	return oneOf("council role", a.Role, "tech", "spirit", "safety")
+}
+
+func (a *MaintenanceAttr) Validate() error {
+	return oneOf("maintenance day", a.Day,
+		"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday")
+}
+
+func (a *CogAttr) Validate() error {
+	return oneOf("cog memory", a.Memory, "ephemeral", "durable")
+}
+
+func (a *ReleaseAttr) Validate() error {
+	if !isValidHash(a.FileHash) {
+		return fmt.Errorf("release fileHash must be a 64-character hex hash")
+	}
+	return nil
+}
+
+func (a *DeliveryAttr) Validate() error {
+	if a.Checksum != "" && !isValidHash(a.Checksum) {
+		return fmt.Errorf("delivery checksum must be a 64-character hex hash")
+	}
+	return nil
+}
+
+func (a *ImportAttr) Validate() error {
+	return oneOf("import chain", a.Chain, "btc", "eth", "sol", "ar", "sui")
+}
+
+func (a *ShrineSplitAttr) Validate() error {
+	if a.Distribution != "50/25/15/10" {
+		return fmt.Errorf("shrine split must be 50/25/15/10 (got %s)", a.Distribution)
+	}
+	return nil
+}
+
+// oneOf checks value against the keyword set a grammar rule allows
+func oneOf(what, value string, allowed ...string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s: %q (expected one of %s)", what, value, strings.Join(allowed, ", "))
+}
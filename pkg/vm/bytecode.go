@@ -0,0 +1,633 @@
+// OSOVM Bytecode
+// Versioned, hashable ritual programs for the stack machine: opcodes, the
+// binary encoding and the disassembler
+
+package vm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+)
+
+// BytecodeVersion is bumped whenever opcodes or the encoding change
+const BytecodeVersion = 3
+
+// ProgramExt is the file extension of encoded programs
+const ProgramExt = ".osob"
+
+// programMagic starts every encoded program
+var programMagic = []byte("OSOB")
+
+// Opcode - One stack machine instruction
+type Opcode byte
+
+const (
+	OpConst        Opcode = iota + 1 // push Constants[A]
+	OpLoad                           // push variable Constants[A], else the ritual arg
+	OpStore                          // pop into variable Constants[A]
+	OpLoadLocal                      // push local slot A
+	OpStoreLocal                     // pop into local slot A
+	OpPop                            // drop the top value
+	OpDup                            // duplicate the top value
+	OpNeg                            // -x
+	OpNot                            // !x
+	OpAdd                            // x + y
+	OpSub                            // x - y
+	OpMul                            // x * y
+	OpDiv                            // x / y
+	OpMod                            // x % y
+	OpEq                             // x == y
+	OpNe                             // x != y
+	OpLt                             // x < y
+	OpLe                             // x <= y
+	OpGt                             // x > y
+	OpGe                             // x >= y
+	OpBool                           // fail unless the top value is a boolean
+	OpJump                           // jump to A
+	OpJumpIfFalse                    // pop a boolean, jump to A if false
+	OpJumpIfTrue                     // pop a boolean, jump to A if true
+	OpCount                          // fail unless the top value is a loop count within the limit
+	OpLoopGuard                      // count an iteration in slot A, fail past Constants[B] iterations
+	OpBuiltin                        // pop B args, push builtin Constants[A](args)
+	OpCall                           // pop B args, call function Constants[A]
+	OpRequireProof                   // validate Àṣẹ: proof kind Constants[A], B witnesses
+	OpSeal                           // run sealed capabilities and attribute hooks
+	OpPrecompile                     // run Òrìṣà precompile Constants[A]
+	OpEmitReceipt                    // record the execution receipt over the top value
+	OpReturn                         // pop the result and stop; A=1 for an explicit return
+)
+
+// opInfo - Mnemonic and operand count of an opcode
+type opInfo struct {
+	Name     string
+	Operands int
+}
+
+var opcodes = map[Opcode]opInfo{
+	OpConst:        {"CONST", 1},
+	OpLoad:         {"LOAD", 1},
+	OpStore:        {"STORE", 1},
+	OpLoadLocal:    {"LOAD_LOCAL", 1},
+	OpStoreLocal:   {"STORE_LOCAL", 1},
+	OpPop:          {"POP", 0},
+	OpDup:          {"DUP", 0},
+	OpNeg:          {"NEG", 0},
+	OpNot:          {"NOT", 0},
+	OpAdd:          {"ADD", 0},
+	OpSub:          {"SUB", 0},
+	OpMul:          {"MUL", 0},
+	OpDiv:          {"DIV", 0},
+	OpMod:          {"MOD", 0},
+	OpEq:           {"EQ", 0},
+	OpNe:           {"NE", 0},
+	OpLt:           {"LT", 0},
+	OpLe:           {"LE", 0},
+	OpGt:           {"GT", 0},
+	OpGe:           {"GE", 0},
+	OpBool:         {"BOOL", 0},
+	OpJump:         {"JUMP", 1},
+	OpJumpIfFalse:  {"JUMP_IF_FALSE", 1},
+	OpJumpIfTrue:   {"JUMP_IF_TRUE", 1},
+	OpCount:        {"COUNT", 0},
+	OpLoopGuard:    {"LOOP_GUARD", 2},
+	OpBuiltin:      {"BUILTIN", 2},
+	OpCall:         {"CALL", 2},
+	OpRequireProof: {"REQUIRE_PROOF", 2},
+	OpSeal:         {"SEAL", 0},
+	OpPrecompile:   {"PRECOMPILE", 1},
+	OpEmitReceipt:  {"EMIT_RECEIPT", 0},
+	OpReturn:       {"RETURN", 1},
+}
+
+func (op Opcode) String() string {
+	if info, ok := opcodes[op]; ok {
+		return info.Name
+	}
+	return fmt.Sprintf("OP_%d", byte(op))
+}
+
+// binaryOps maps expression operators to their opcodes
+var binaryOps = map[string]Opcode{
+	"+": OpAdd, "-": OpSub, "*": OpMul, "/": OpDiv, "%": OpMod,
+	"==": OpEq, "!=": OpNe, "<": OpLt, "<=": OpLe, ">": OpGt, ">=": OpGe,
+}
+
+// binaryOpNames is the reverse of binaryOps
+var binaryOpNames = func() map[Opcode]string {
+	m := make(map[Opcode]string, len(binaryOps))
+	for name, op := range binaryOps {
+		m[op] = name
+	}
+	return m
+}()
+
+// ========== Programs ==========
+
+// Instr - One decoded instruction; unused operands are zero
+type Instr struct {
+	Op   Opcode
+	A, B int
+}
+
+// Program - A compiled ritual. Spans is debug information from the source
+// and is neither encoded nor hashed.
+type Program struct {
+	Version    int
+	Ritual     string
+	Orisa      string
+	Args       map[string]interface{} // ritual args, read by OpLoad when no variable is set
+	GasLimit   int64                  // @gas_limit(max), 0 if not set
+	Attributes map[string]Validator   // the ritual's attributes, validated and hooked on every run
+	Constants  []interface{}
+	Locals     int
+	Code       []Instr
+	Spans      []ast.Span // source span of each instruction, if known
+}
+
+// Hash returns the hex SHA-256 of the program's encoding
+func (p *Program) Hash() string {
+	sum := sha256.Sum256(p.Encode())
+	return hex.EncodeToString(sum[:])
+}
+
+// Identity returns the hex SHA-256 of the program's encoding without its
+// args: the ritual itself rather than one invocation of it
+func (p *Program) Identity() string {
+	q := *p
+	q.Args = nil
+	return q.Hash()
+}
+
+// span returns the source span of instruction pc, if known
+func (p *Program) span(pc int) ast.Span {
+	if pc < len(p.Spans) {
+		return p.Spans[pc]
+	}
+	return ast.Span{}
+}
+
+// ========== Encoding ==========
+
+// Value tags in the constant pool
+const (
+	tagNull byte = iota
+	tagFalse
+	tagTrue
+	tagInt
+	tagFloat
+	tagString
+	tagList
+	tagObject
+)
+
+// Encode returns the program's binary form: magic, version, header (ritual,
+// Òrìṣà, args, gas limit, attributes), constants, locals and code. Integers
+// are varints and object keys are sorted, so equal programs encode to equal
+// bytes.
+func (p *Program) Encode() []byte {
+	var buf bytes.Buffer
+	buf.Write(programMagic)
+	binary.Write(&buf, binary.BigEndian, uint16(p.Version))
+	writeString(&buf, p.Ritual)
+	writeString(&buf, p.Orisa)
+	writeValue(&buf, map[string]interface{}(p.Args))
+	writeUvarint(&buf, uint64(p.GasLimit))
+	writeValue(&buf, encodeAttributes(p.Attributes))
+
+	writeUvarint(&buf, uint64(len(p.Constants)))
+	for _, c := range p.Constants {
+		writeValue(&buf, c)
+	}
+	writeUvarint(&buf, uint64(p.Locals))
+
+	writeUvarint(&buf, uint64(len(p.Code)))
+	for _, in := range p.Code {
+		buf.WriteByte(byte(in.Op))
+		operands := []int{in.A, in.B}
+		for _, v := range operands[:opcodes[in.Op].Operands] {
+			writeUvarint(&buf, uint64(v))
+		}
+	}
+	return buf.Bytes()
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	writeUvarint(buf, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+// encodeAttributes maps each attribute name to the JSON of its struct
+func encodeAttributes(attrs map[string]Validator) map[string]interface{} {
+	encoded := make(map[string]interface{}, len(attrs))
+	for name, value := range attrs {
+		data, err := json.Marshal(value)
+		if err != nil {
+			panic(fmt.Sprintf("vm: cannot encode @%s: %v", name, err))
+		}
+		encoded[name] = string(data)
+	}
+	return encoded
+}
+
+// encodeValue returns the encoding of one value
+func encodeValue(v interface{}) []byte {
+	var buf bytes.Buffer
+	writeValue(&buf, v)
+	return buf.Bytes()
+}
+
+func writeValue(buf *bytes.Buffer, v interface{}) {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteByte(tagNull)
+	case bool:
+		if x {
+			buf.WriteByte(tagTrue)
+		} else {
+			buf.WriteByte(tagFalse)
+		}
+	case int64:
+		buf.WriteByte(tagInt)
+		buf.Write(binary.AppendVarint(nil, x))
+	case float64:
+		buf.WriteByte(tagFloat)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(x))
+	case string:
+		buf.WriteByte(tagString)
+		writeString(buf, x)
+	case []interface{}:
+		buf.WriteByte(tagList)
+		writeUvarint(buf, uint64(len(x)))
+		for _, item := range x {
+			writeValue(buf, item)
+		}
+	case map[string]interface{}:
+		buf.WriteByte(tagObject)
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		writeUvarint(buf, uint64(len(keys)))
+		for _, k := range keys {
+			writeString(buf, k)
+			writeValue(buf, x[k])
+		}
+	default:
+		panic(fmt.Sprintf("vm: cannot encode %T", v))
+	}
+}
+
+// ========== Decoding ==========
+
+// IsProgram reports whether data is an encoded program
+func IsProgram(data []byte) bool {
+	return bytes.HasPrefix(data, programMagic)
+}
+
+// DecodeProgram decodes and checks an encoded program. Every constant,
+// local and jump target an instruction refers to must exist, so a decoded
+// program can't make the machine index out of range.
+func DecodeProgram(data []byte) (*Program, error) {
+	if !IsProgram(data) {
+		return nil, fmt.Errorf("not an OSOVM program")
+	}
+	r := &programReader{data: data, pos: len(programMagic)}
+	p := &Program{}
+
+	if r.remaining() < 2 {
+		return nil, fmt.Errorf("truncated program header")
+	}
+	p.Version = int(binary.BigEndian.Uint16(data[r.pos:]))
+	r.pos += 2
+	if p.Version != BytecodeVersion {
+		return nil, fmt.Errorf("bytecode version %d is not supported (want %d)", p.Version, BytecodeVersion)
+	}
+
+	p.Ritual = r.string()
+	p.Orisa = r.string()
+	args, _ := r.value(0).(map[string]interface{})
+	p.Args = args
+	if p.GasLimit = int64(r.uvarint()); p.GasLimit < 0 {
+		return nil, fmt.Errorf("gas limit out of range")
+	}
+	attrs, _ := r.value(0).(map[string]interface{})
+	if r.err == nil {
+		decoded, err := decodeAttributes(attrs)
+		if err != nil {
+			return nil, err
+		}
+		p.Attributes = decoded
+	}
+
+	constants := r.count()
+	for i := 0; i < constants && r.err == nil; i++ {
+		p.Constants = append(p.Constants, r.value(0))
+	}
+	p.Locals = r.count()
+
+	n := r.count()
+	for i := 0; i < n && r.err == nil; i++ {
+		op := Opcode(r.byte())
+		info, ok := opcodes[op]
+		if !ok && r.err == nil {
+			return nil, fmt.Errorf("instruction %d: unknown opcode %d", i, byte(op))
+		}
+		in := Instr{Op: op}
+		if info.Operands > 0 {
+			in.A = r.count()
+		}
+		if info.Operands > 1 {
+			in.B = r.count()
+		}
+		p.Code = append(p.Code, in)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.remaining() > 0 {
+		return nil, fmt.Errorf("unexpected data after program")
+	}
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// decodeAttributes restores the typed struct of each encoded attribute.
+// Names the registry doesn't know and fields a struct doesn't have are
+// errors, so no attribute policy is silently dropped.
+func decodeAttributes(encoded map[string]interface{}) (map[string]Validator, error) {
+	attrs := make(map[string]Validator, len(encoded))
+	for name, raw := range encoded {
+		spec, known := attributeRegistry.Lookup(name)
+		data, isString := raw.(string)
+		if !known || !isString {
+			return nil, fmt.Errorf("unknown attribute @%s", name)
+		}
+		value := spec.New()
+		dec := json.NewDecoder(strings.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(value); err != nil {
+			return nil, fmt.Errorf("attribute @%s: %w", name, err)
+		}
+		attrs[name] = value
+	}
+	return attrs, nil
+}
+
+// check validates the prologue and operand references: names must be
+// string constants and loop bounds integer constants
+func (p *Program) check() error {
+	isConst := func(i int, want func(v interface{}) bool) bool {
+		return i < len(p.Constants) && want(p.Constants[i])
+	}
+	isString := func(v interface{}) bool { _, ok := v.(string); return ok }
+	isInt := func(v interface{}) bool { _, ok := v.(int64); return ok }
+
+	start, err := p.checkPrologue()
+	if err != nil {
+		return err
+	}
+	for pc, in := range p.Code {
+		ok := true
+		switch in.Op {
+		case OpConst:
+			ok = in.A < len(p.Constants)
+		case OpLoad, OpStore, OpBuiltin, OpCall:
+			ok = isConst(in.A, isString)
+		case OpRequireProof, OpSeal, OpPrecompile:
+			if pc >= start {
+				return fmt.Errorf("instruction %d (%s): only allowed in the prologue", pc, in.Op)
+			}
+		case OpLoopGuard:
+			ok = in.A < p.Locals && isConst(in.B, isInt)
+		case OpLoadLocal, OpStoreLocal:
+			ok = in.A < p.Locals
+		case OpJump, OpJumpIfFalse, OpJumpIfTrue:
+			// Nothing jumps back into the prologue to run it twice
+			ok = in.A >= start && in.A <= len(p.Code)
+		}
+		if !ok {
+			return fmt.Errorf("instruction %d (%s): bad operand", pc, in.Op)
+		}
+	}
+	return nil
+}
+
+// checkPrologue checks the program opens as Compile emits it -
+// REQUIRE_PROOF, SEAL, then PRECOMPILE of the program's Òrìṣà - and that
+// REQUIRE_PROOF asks for exactly the proof of the encoded @àṣẹ. Only a
+// ritual without @àṣẹ may leave REQUIRE_PROOF out. It returns the index
+// of the first instruction after the prologue.
+func (p *Program) checkPrologue() (int, error) {
+	code, pc := p.Code, 0
+	constant := func(i int) string {
+		if i < len(p.Constants) {
+			s, _ := p.Constants[i].(string)
+			return s
+		}
+		return ""
+	}
+
+	ase, hasAse := p.Attributes["àṣẹ"].(*UniversalAse)
+	if pc < len(code) && code[pc].Op == OpRequireProof {
+		in := code[pc]
+		proofType := constant(in.A)
+		if proofType == "" {
+			return 0, fmt.Errorf("instruction %d (%s): bad operand", pc, in.Op)
+		}
+		if hasAse && (proofType != ase.ProofType || in.B != ase.Witnesses) {
+			return 0, fmt.Errorf("REQUIRE_PROOF %s with %d witnesses does not match @àṣẹ (%s with %d witnesses)",
+				proofType, in.B, ase.ProofType, ase.Witnesses)
+		}
+		pc++
+	} else if hasAse {
+		return 0, fmt.Errorf("program has @àṣẹ but does not start with REQUIRE_PROOF")
+	}
+
+	if pc >= len(code) || code[pc].Op != OpSeal {
+		return 0, fmt.Errorf("instruction %d: expected SEAL in the prologue", pc)
+	}
+	pc++
+	if pc >= len(code) || code[pc].Op != OpPrecompile {
+		return 0, fmt.Errorf("instruction %d: expected PRECOMPILE in the prologue", pc)
+	}
+	if orisa := constant(code[pc].A); orisa != p.Orisa {
+		return 0, fmt.Errorf("instruction %d (PRECOMPILE): runs %q, not the program's Òrìṣà %q", pc, orisa, p.Orisa)
+	}
+	return pc + 1, nil
+}
+
+// programReader reads the encoding, remembering the first error
+type programReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *programReader) remaining() int {
+	return len(r.data) - r.pos
+}
+
+func (r *programReader) fail(format string, args ...interface{}) {
+	if r.err == nil {
+		r.err = fmt.Errorf("malformed program at byte %d: %s", r.pos, fmt.Sprintf(format, args...))
+	}
+	r.pos = len(r.data)
+}
+
+func (r *programReader) byte() byte {
+	if r.remaining() < 1 {
+		r.fail("unexpected end")
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *programReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.fail("bad varint")
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+// count reads a length or index, bounded by the bytes left
+func (r *programReader) count() int {
+	v := r.uvarint()
+	if v > uint64(len(r.data)) {
+		r.fail("count %d out of range", v)
+		return 0
+	}
+	return int(v)
+}
+
+func (r *programReader) string() string {
+	n := r.count()
+	if r.remaining() < n {
+		r.fail("truncated string")
+		return ""
+	}
+	s := string(r.data[r.pos : r.pos+n])
+	r.pos += n
+	return s
+}
+
+// maxValueDepth bounds nested lists and objects in the constant pool
+const maxValueDepth = 32
+
+func (r *programReader) value(depth int) interface{} {
+	if depth > maxValueDepth {
+		r.fail("constant nested too deeply")
+		return nil
+	}
+	switch tag := r.byte(); tag {
+	case tagNull:
+		return nil
+	case tagFalse:
+		return false
+	case tagTrue:
+		return true
+	case tagInt:
+		v, n := binary.Varint(r.data[r.pos:])
+		if n <= 0 {
+			r.fail("bad varint")
+			return nil
+		}
+		r.pos += n
+		return v
+	case tagFloat:
+		if r.remaining() < 8 {
+			r.fail("truncated float")
+			return nil
+		}
+		f := math.Float64frombits(binary.BigEndian.Uint64(r.data[r.pos:]))
+		r.pos += 8
+		return f
+	case tagString:
+		return r.string()
+	case tagList:
+		n := r.count()
+		list := make([]interface{}, 0, n)
+		for i := 0; i < n && r.err == nil; i++ {
+			list = append(list, r.value(depth+1))
+		}
+		return list
+	case tagObject:
+		n := r.count()
+		obj := make(map[string]interface{}, n)
+		for i := 0; i < n && r.err == nil; i++ {
+			k := r.string()
+			obj[k] = r.value(depth + 1)
+		}
+		return obj
+	default:
+		r.fail("unknown value tag %d", tag)
+		return nil
+	}
+}
+
+// ========== Disassembler ==========
+
+// Disassemble renders the program as text, one instruction per line
+func Disassemble(p *Program) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "; %s (%s) bytecode v%d\n", p.Ritual, p.Orisa, p.Version)
+	fmt.Fprintf(&sb, "; hash %s\n", p.Hash())
+	if len(p.Args) > 0 {
+		fmt.Fprintf(&sb, "; args %s\n", formatValue(map[string]interface{}(p.Args)))
+	}
+	if p.GasLimit > 0 {
+		fmt.Fprintf(&sb, "; gas limit %d\n", p.GasLimit)
+	}
+	if len(p.Attributes) > 0 {
+		names := make([]string, 0, len(p.Attributes))
+		for name := range p.Attributes {
+			names = append(names, "@"+name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(&sb, "; attributes %s\n", strings.Join(names, " "))
+	}
+	fmt.Fprintf(&sb, "; %d constants, %d locals, %d instructions\n", len(p.Constants), p.Locals, len(p.Code))
+
+	for pc, in := range p.Code {
+		line := fmt.Sprintf("%04d  %-14s", pc, in.Op)
+		switch in.Op {
+		case OpConst, OpLoad, OpStore, OpPrecompile:
+			line += fmt.Sprintf("#%d  ; %s", in.A, formatValue(p.Constants[in.A]))
+		case OpBuiltin, OpCall:
+			line += fmt.Sprintf("#%d %d  ; %s/%d", in.A, in.B, formatValue(p.Constants[in.A]), in.B)
+		case OpRequireProof:
+			line += fmt.Sprintf("#%d %d  ; %s, %d witnesses", in.A, in.B, formatValue(p.Constants[in.A]), in.B)
+		case OpLoopGuard:
+			line += fmt.Sprintf("%d #%d  ; max %s", in.A, in.B, formatValue(p.Constants[in.B]))
+		case OpLoadLocal, OpStoreLocal, OpReturn:
+			line += fmt.Sprintf("%d", in.A)
+		case OpJump, OpJumpIfFalse, OpJumpIfTrue:
+			line += fmt.Sprintf("%04d", in.A)
+		}
+		if span := p.span(pc); span.Start.IsValid() {
+			line += fmt.Sprintf("  @%s", span.Start)
+		}
+		sb.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,265 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const deadlineRitual = `{
+	"name": "timed", "orisa": "eshu_router",
+	"attributes": {"deadline": {"unix": 1700000100}, "vote": {"weight": %s}, "gas_limit": {"max": 5000}},
+	"args": {"to": "alice"},
+	"statements": [{"type": "return", "data": {"expr": "to"}}]
+}`
+
+// compileSource loads source in a fresh VM and returns the ritual's
+// encoded program
+func compileSource(t *testing.T, source, ritual string) []byte {
+	t.Helper()
+	vm := NewVM()
+	vm.Sinks = nil
+	if err := vm.LoadSource("test.oso", []byte(source)); err != nil {
+		t.Fatal(err)
+	}
+	return vm.Rituals[ritual].Program.Encode()
+}
+
+func TestCompiledProgramKeepsItsAttributes(t *testing.T) {
+	encoded := compileSource(t, strings.Replace(deadlineRitual, "%s", "1.0", 1), "timed")
+	p, err := DecodeProgram(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p.Encode(), encoded) {
+		t.Error("decoding and re-encoding changed the program")
+	}
+	if got := Disassemble(p); !strings.Contains(got, "; attributes @deadline @gas_limit @vote\n") {
+		t.Errorf("disassembly:\n%s", got)
+	}
+
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	if err := vm.LoadSource("timed.osob", encoded); err != nil {
+		t.Fatal(err)
+	}
+	res, err := vm.Execute("timed", nil, nil)
+	if err != nil {
+		t.Fatalf("before the deadline: %v", err)
+	}
+	if res.Return != "alice" || res.GasLimit != 5000 {
+		t.Errorf("returned %v with gas limit %d", res.Return, res.GasLimit)
+	}
+
+	// The @deadline hook still runs for the compiled program
+	now = now.Add(time.Hour)
+	if _, err := vm.Execute("timed", nil, nil); err == nil || !strings.Contains(err.Error(), "deadline passed") {
+		t.Errorf("after the deadline: %v", err)
+	}
+
+	// As does validation: a bad @vote fails the program like its source
+	encoded = compileSource(t, strings.Replace(deadlineRitual, "%s", "0.5", 1), "timed")
+	if err := vm.LoadSource("timed.osob", encoded); err != nil {
+		t.Fatal(err)
+	}
+	var invalid *ValidationError
+	if _, err := vm.Execute("timed", nil, nil); !errors.As(err, &invalid) || invalid.Failures[0].Attribute != "vote" {
+		t.Errorf("invalid @vote: %v", err)
+	}
+}
+
+func TestDecodeRejectsMalformedPrograms(t *testing.T) {
+	valid := func() *Program {
+		return &Program{
+			Version:   BytecodeVersion,
+			Ritual:    "p",
+			Orisa:     "eshu_router",
+			Constants: []interface{}{"x", int64(3), "eshu_router", "telemetry"},
+			Locals:    1,
+			Code:      []Instr{{Op: OpSeal}, {Op: OpPrecompile, A: 2}, {Op: OpConst, A: 1}, {Op: OpReturn}},
+		}
+	}
+	if _, err := DecodeProgram(valid().Encode()); err != nil {
+		t.Fatalf("valid program: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		mutate func(p *Program)
+		err    string
+	}{
+		{"constant", func(p *Program) { p.Code[2].A = 4 }, "instruction 2 (CONST): bad operand"},
+		{"name not a string", func(p *Program) { p.Code[2] = Instr{Op: OpLoad, A: 1} }, "instruction 2 (LOAD): bad operand"},
+		{"local", func(p *Program) { p.Code[2] = Instr{Op: OpStoreLocal, A: 1} }, "instruction 2 (STORE_LOCAL): bad operand"},
+		{"loop bound not an int", func(p *Program) { p.Code[2] = Instr{Op: OpLoopGuard, A: 0, B: 0} }, "instruction 2 (LOOP_GUARD): bad operand"},
+		{"jump past the end", func(p *Program) { p.Code[2] = Instr{Op: OpJump, A: 5} }, "instruction 2 (JUMP): bad operand"},
+		{"jump into the prologue", func(p *Program) { p.Code[2] = Instr{Op: OpJump, A: 0} }, "instruction 2 (JUMP): bad operand"},
+		{"unknown opcode", func(p *Program) { p.Code[3].Op = 200 }, "instruction 3: unknown opcode 200"},
+		{"no prologue", func(p *Program) { p.Code = p.Code[2:] }, "instruction 0: expected SEAL in the prologue"},
+		{"prologue out of order", func(p *Program) { p.Code[0], p.Code[1] = p.Code[1], p.Code[0] }, "expected SEAL in the prologue"},
+		{"proof after the seal", func(p *Program) {
+			p.Code = append([]Instr{{Op: OpSeal}, {Op: OpRequireProof, A: 3, B: 1}}, p.Code[1:]...)
+		}, "instruction 1: expected PRECOMPILE in the prologue"},
+		{"another Òrìṣà", func(p *Program) { p.Constants[2] = "ogun_forge" }, `runs "ogun_forge", not the program's Òrìṣà "eshu_router"`},
+		{"second seal", func(p *Program) { p.Code[2] = Instr{Op: OpSeal} }, "instruction 2 (SEAL): only allowed in the prologue"},
+		{"late precompile", func(p *Program) { p.Code[2] = Instr{Op: OpPrecompile, A: 2} }, "instruction 2 (PRECOMPILE): only allowed in the prologue"},
+		{"proof not a string", func(p *Program) {
+			p.Code = append([]Instr{{Op: OpRequireProof, A: 1, B: 1}}, p.Code...)
+		}, "instruction 0 (REQUIRE_PROOF): bad operand"},
+		{"àṣẹ without REQUIRE_PROOF", func(p *Program) {
+			p.Attributes = map[string]Validator{"àṣẹ": &UniversalAse{ProofType: "telemetry", Witnesses: 1}}
+		}, "program has @àṣẹ but does not start with REQUIRE_PROOF"},
+		{"REQUIRE_PROOF weaker than àṣẹ", func(p *Program) {
+			p.Attributes = map[string]Validator{"àṣẹ": &UniversalAse{ProofType: "telemetry", Witnesses: 3}}
+			p.Code = append([]Instr{{Op: OpRequireProof, A: 3, B: 1}}, p.Code...)
+		}, "REQUIRE_PROOF telemetry with 1 witnesses does not match @àṣẹ (telemetry with 3 witnesses)"},
+		{"version", func(p *Program) { p.Version = BytecodeVersion - 1 }, "is not supported"},
+		{"unknown attribute", func(p *Program) { p.Attributes = map[string]Validator{"nope": &DeadlineAttr{}} }, "unknown attribute @nope"},
+	} {
+		p := valid()
+		tc.mutate(p)
+		if _, err := DecodeProgram(p.Encode()); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.err)
+		}
+	}
+
+	encoded := valid().Encode()
+	for name, data := range map[string][]byte{
+		"truncated":     encoded[:len(encoded)-1],
+		"trailing data": append(append([]byte{}, encoded...), 0),
+		"no header":     encoded[:5],
+		"not a program": []byte("{}"),
+	} {
+		if _, err := DecodeProgram(data); err == nil {
+			t.Errorf("%s decoded", name)
+		}
+	}
+
+	// An attribute field its struct doesn't have fails the load
+	p := valid()
+	p.Attributes = map[string]Validator{"deadline": &DeadlineAttr{Unix: 1}}
+	encoded = bytes.Replace(p.Encode(), []byte(`{"unix":1}`), []byte(`{"unit":1}`), 1)
+	if _, err := DecodeProgram(encoded); err == nil || !strings.Contains(err.Error(), `attribute @deadline: json: unknown field "unit"`) {
+		t.Errorf("unknown field: %v", err)
+	}
+}
+
+func TestTamperedProgramsAreRejected(t *testing.T) {
+	source := `{
+		"name": "sealed", "orisa": "eshu_router",
+		"ase": {"proof": "telemetry", "witnesses": 3},
+		"statements": [{"type": "return", "data": {"expr": "1"}}]
+	}`
+	encoded := compileSource(t, source, "sealed")
+	if err := NewVM().LoadSource("sealed.osob", encoded); err != nil {
+		t.Fatalf("untampered: %v", err)
+	}
+
+	// Editing the REQUIRE_PROOF operand in place: one witness instead of
+	// the three @àṣẹ asks for
+	p, err := DecodeProgram(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Code[0].B = 1
+	weakened := p.Encode()
+
+	// Cutting REQUIRE_PROOF out altogether
+	p, _ = DecodeProgram(encoded)
+	p.Code = p.Code[1:]
+	stripped := p.Encode()
+
+	// Rewriting the attribute, leaving the code alone
+	relaxed := bytes.Replace(encoded, []byte(`"witnesses":3`), []byte(`"witnesses":1`), 1)
+	if bytes.Equal(relaxed, encoded) {
+		t.Fatal("no @àṣẹ to rewrite")
+	}
+
+	for name, tc := range map[string]struct {
+		data []byte
+		err  string
+	}{
+		"weakened": {weakened, "REQUIRE_PROOF telemetry with 1 witnesses does not match @àṣẹ (telemetry with 3 witnesses)"},
+		"stripped": {stripped, "program has @àṣẹ but does not start with REQUIRE_PROOF"},
+		"relaxed":  {relaxed, "REQUIRE_PROOF telemetry with 3 witnesses does not match @àṣẹ (telemetry with 1 witnesses)"},
+	} {
+		vm := NewVM()
+		if err := vm.LoadSource("sealed.osob", tc.data); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", name, err, tc.err)
+		}
+		if _, loaded := vm.Rituals["sealed"]; loaded {
+			t.Errorf("%s: tampered program registered", name)
+		}
+	}
+}
+
+func TestMachineRuntimeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		err  string
+	}{
+		{"9223372036854775807 + 1", "integer overflow"},
+		{"-9223372036854775807 - 2", "integer overflow"},
+		{"4611686018427387904 * 2", "integer overflow"},
+		{"-9223372036854775808 / -1", "integer overflow"},
+		{"-(-9223372036854775808)", "integer overflow"},
+		{"1 / 0", "division by zero"},
+		{"1 % 0", "division by zero"},
+		{"missing + 1", `undefined variable "missing"`},
+		{`"a" - 1`, "cannot apply - to string and integer"},
+	} {
+		vm := NewVM()
+		vm.Sinks = nil
+		_, err := run(t, vm, `{
+			"name": "calc", "orisa": "eshu_router",
+			"statements": [{"type": "return", "data": {"expr": "`+strings.ReplaceAll(tc.expr, `"`, `\"`)+`"}}]
+		}`)
+		var rt *RuntimeError
+		if !errors.As(err, &rt) || !strings.Contains(rt.Err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", tc.expr, err, tc.err)
+		}
+	}
+
+	// The largest values stay in range
+	vm := NewVM()
+	vm.Sinks = nil
+	res, err := run(t, vm, `{
+		"name": "edge", "orisa": "eshu_router",
+		"statements": [{"type": "return", "data": {"expr": "-9223372036854775807 - 1"}}]
+	}`)
+	if err != nil || res.Return != int64(-9223372036854775808) {
+		t.Errorf("min int64: %v, %v", res.Return, err)
+	}
+}
+
+func TestMachineStackBounds(t *testing.T) {
+	overflow := make([]Instr, maxStackDepth+1)
+	for i := range overflow {
+		overflow[i] = Instr{Op: OpConst}
+	}
+
+	for _, tc := range []struct {
+		name string
+		code []Instr
+		err  string
+	}{
+		{"underflow", []Instr{{Op: OpPop}}, "stack underflow"},
+		{"binary underflow", []Instr{{Op: OpConst}, {Op: OpAdd}}, "stack underflow"},
+		{"overflow", overflow, "stack overflow"},
+		{"no return", []Instr{{Op: OpConst}}, "program ended without RETURN"},
+		{"no receipt", []Instr{{Op: OpConst}, {Op: OpReturn}}, "returned without emitting a receipt"},
+	} {
+		vm := NewVM()
+		vm.Sinks = nil
+		prologue := []Instr{{Op: OpSeal}, {Op: OpPrecompile, A: 1}}
+		p := &Program{Version: BytecodeVersion, Ritual: "raw", Orisa: "eshu_router", Constants: []interface{}{int64(1), "eshu_router"}, Code: append(prologue, tc.code...)}
+		if err := vm.LoadProgram(p); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := vm.Execute("raw", nil, nil); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// OSOVM Yemọja Cache
+// The yemoja_cache precompile: restores the ritual's cached state before
+// its body runs and stores it back for @cache(ttl) seconds once the
+// execution completes. A ritual only sees entries it stored itself, and
+// masked values are cached redacted, like everything else that leaves
+// the execution.
+
+package vm
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// cacheWrite - A cache write held back until the execution completes, so
+// a failed execution caches nothing. State writes store the variables.
+type cacheWrite struct {
+	key   string
+	value interface{}
+	ttl   time.Duration
+	state bool
+}
+
+// cacheKey is the ritual's state entry: its key arg, or one named after it
+func (vm *VM) cacheKey() string {
+	if key, ok := vm.Context.Ritual.ArgValues["key"].(string); ok && key != "" {
+		return vm.Context.redactString(key)
+	}
+	return "ritual:" + vm.Context.Ritual.Name
+}
+
+// cacheRitual is the namespace of the ritual's entries: its name and
+// program identity, so neither another ritual nor a changed version of
+// this one reads them
+func (vm *VM) cacheRitual() string {
+	return vm.Context.Ritual.ID()
+}
+
+// cacheTTL is the ritual's @cache ttl
+func (vm *VM) cacheTTL() (time.Duration, bool) {
+	attr, ok := vm.Context.Ritual.Attributes["cache"].(*CacheAttr)
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(attr.TTL) * time.Second, true
+}
+
+// Yemọja - Memory & Cache
+func (vm *VM) yemojaCache() error {
+	ctx := vm.Context
+	ttl, ok := vm.cacheTTL()
+	if !ok {
+		return fmt.Errorf("yemoja_cache needs @cache(ttl) to know how long to keep state")
+	}
+	key := vm.cacheKey()
+
+	// 1. A live entry restores the variables the body has not set
+	loaded := CacheLoaded{Key: key}
+	if e, hit := vm.Cache.Get(vm.cacheRitual(), key); hit {
+		state, ok := e.Value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("yemoja_cache: %s holds %s, not ritual state", key, typeName(e.Value))
+		}
+		for name, v := range state {
+			if _, set := ctx.Variables[name]; !set {
+				ctx.Variables[name] = v
+				loaded.Restored = append(loaded.Restored, name)
+			}
+		}
+		sort.Strings(loaded.Restored)
+		loaded.Hit, loaded.Expires = true, e.Expires
+	}
+	vm.emit(loaded)
+
+	// 2. The variables are stored back once the execution completes
+	ctx.cacheWrites = append(ctx.cacheWrites, cacheWrite{key: key, ttl: ttl, state: true})
+	return nil
+}
+
+// cacheGet reads key, seeing this execution's pending writes first
+func (vm *VM) cacheGet(key string) interface{} {
+	key = vm.Context.redactString(key)
+	writes := vm.Context.cacheWrites
+	for i := len(writes) - 1; i >= 0; i-- {
+		if w := writes[i]; w.key == key && !w.state {
+			return w.value
+		}
+	}
+	if e, ok := vm.Cache.Get(vm.cacheRitual(), key); ok {
+		return e.Value
+	}
+	return nil
+}
+
+// cachePut queues a write of value under key
+func (vm *VM) cachePut(key string, value interface{}, ttl time.Duration) error {
+	if key == "" {
+		return fmt.Errorf("cache key required")
+	}
+	if ttl <= 0 {
+		return fmt.Errorf("cache ttl must be positive (got %s)", ttl)
+	}
+	vm.Context.cacheWrites = append(vm.Context.cacheWrites, cacheWrite{key: vm.Context.redactString(key), value: value, ttl: ttl})
+	return nil
+}
+
+// flushCache applies the execution's cache writes in order. Keys and
+// TTLs were checked when the writes were queued; a write that still fails
+// is reported as cache.failed, since the receipt is already sealed.
+// Masked variables are left out of the state, so they are never restored
+// as their hashes, and every value is redacted.
+func (vm *VM) flushCache() {
+	ctx := vm.Context
+	for _, w := range ctx.cacheWrites {
+		value := w.value
+		if w.state {
+			state := make(map[string]interface{}, len(ctx.Variables))
+			for name, v := range ctx.Variables {
+				if !ctx.Masked[name] {
+					state[name] = v
+				}
+			}
+			value = state
+		}
+		e, err := vm.Cache.Set(vm.cacheRitual(), w.key, ctx.redact(value), w.ttl)
+		if err != nil {
+			vm.emit(CacheFailed{Key: w.key, Error: err.Error()})
+			continue
+		}
+		vm.emit(CacheStored{Key: e.Key, Expires: e.Expires})
+	}
+}
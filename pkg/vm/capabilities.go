@@ -0,0 +1,147 @@
+// OSOVM Engine Capabilities
+// Phase 2 device features (device, auto_device, QR, delivery) switched on
+// by a ritual's attributes
+
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ase-lang/osovm/pkg/proofkind"
+)
+
+// Capability - An engine feature a ritual opts into. Unsealed capabilities
+// run before Àṣẹ validation and may supply the proof and witnesses; sealed
+// ones run once Àṣẹ is sealed.
+type Capability struct {
+	Name    string
+	Sealed  bool
+	Enabled func(r *Ritual) bool
+	Run     func(vm *VM) error
+}
+
+// capabilities run in this order within each stage
+var capabilities = []Capability{
+	{Name: "device", Enabled: hasAttribute("device"), Run: bindDevice},
+	{Name: "auto_device", Enabled: aseFlag(func(a *UniversalAse) bool { return a.AutoDevice }), Run: autoDetectDevice},
+	{Name: "qr", Enabled: aseFlag(func(a *UniversalAse) bool { return a.QR }), Run: scanQR},
+	{Name: "delivery", Sealed: true, Enabled: hasAttribute("delivery"), Run: trackDelivery},
+}
+
+// runCapabilities runs the enabled capabilities of one stage
+func (vm *VM) runCapabilities(sealed bool) error {
+	for _, c := range capabilities {
+		if c.Sealed != sealed || !c.Enabled(vm.Context.Ritual) {
+			continue
+		}
+		if err := c.Run(vm); err != nil {
+			return fmt.Errorf("%s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+func hasAttribute(name string) func(r *Ritual) bool {
+	return func(r *Ritual) bool {
+		_, ok := r.Attributes[name]
+		return ok
+	}
+}
+
+func aseFlag(flag func(a *UniversalAse) bool) func(r *Ritual) bool {
+	return func(r *Ritual) bool {
+		ase := r.Universal()
+		return ase != nil && flag(ase)
+	}
+}
+
+// ========== Device ==========
+
+// bindDevice binds the ritual to its declared @device; the proof must
+// come from that device
+func bindDevice(vm *VM) error {
+	device := vm.Context.Ritual.Attributes["device"].(*DeviceAttr)
+	vm.Context.Device = device
+	vm.emit(DeviceBound{ID: device.ID, Type: device.Type})
+	return nil
+}
+
+// autoDetectDevice uses the declared @device, or else the device the VM
+// runs on
+func autoDetectDevice(vm *VM) error {
+	if vm.Context.Device == nil {
+		if vm.Device == nil {
+			return fmt.Errorf("no device detected")
+		}
+		vm.Context.Device = vm.Device
+	}
+	vm.emit(DeviceBound{ID: vm.Context.Device.ID, Type: vm.Context.Device.Type, Auto: true})
+	return nil
+}
+
+// ========== QR ==========
+
+// scanQR captures the proof with the VM's scanner when none was supplied,
+// then gathers witnesses from the mesh if none were supplied either
+func scanQR(vm *VM) error {
+	ctx := vm.Context
+	ase := ctx.Ritual.Ase
+	if ase == nil {
+		// The àṣẹ attribute asked for a scan, but there is no proof
+		// requirement to say what the scan proves
+		return fmt.Errorf("ritual %s has no Àṣẹ requirement to capture a proof for", ctx.Ritual.Name)
+	}
+	if ctx.Proof != nil || vm.Scanner == nil {
+		return nil
+	}
+
+	scan, err := vm.Scanner.Scan()
+	if err != nil {
+		return fmt.Errorf("QR scan failed: %w", err)
+	}
+	// A scanner without an ID is the camera of the ritual's device
+	deviceID := scan.DeviceID
+	if deviceID == "" && ctx.Device != nil {
+		deviceID = ctx.Device.ID
+	}
+	payload, err := json.Marshal(proofkind.QRCode{RawData: scan.RawData})
+	if err != nil {
+		return err
+	}
+	ctx.Proof = &Proof{
+		Type:      ase.ProofType,
+		Receipt:   scan.Hash,
+		Timestamp: scan.Timestamp,
+		DeviceID:  deviceID,
+		Payload:   payload,
+	}
+	vm.emit(QRScanned{Hash: scan.Hash})
+
+	if len(ctx.Witnesses) > 0 || vm.Mesh == nil {
+		return nil
+	}
+	witnesses, err := vm.Mesh.Broadcast(ctx.Ritual.Name, ctx.Proof, ase.Witnesses)
+	if err != nil {
+		return fmt.Errorf("witness mesh failed: %w", err)
+	}
+	ctx.Witnesses = witnesses
+	devices := make([]string, len(witnesses))
+	for i, w := range witnesses {
+		devices[i] = w.DeviceID
+	}
+	vm.emit(WitnessesGathered{Devices: devices})
+	return nil
+}
+
+// ========== Delivery ==========
+
+// trackDelivery records the sealed delivery in the ritual's variables
+func trackDelivery(vm *VM) error {
+	d := vm.Context.Ritual.Attributes["delivery"].(*DeliveryAttr)
+	vm.emit(DeliveryTracked{ID: d.ID, From: d.From, To: d.To, State: d.State})
+	if d.State != "" {
+		vm.Context.Variables["delivery_state"] = d.State
+	}
+	return nil
+}
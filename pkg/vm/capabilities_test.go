@@ -0,0 +1,155 @@
+package vm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/camera"
+	"github.com/ase-lang/osovm/pkg/witness"
+)
+
+// fixedScanner scans the same QR code every time, at the VM's clock
+type fixedScanner struct {
+	deviceID, data string
+	now            func() time.Time
+	err            error
+}
+
+func (s *fixedScanner) GetDeviceID() string { return s.deviceID }
+
+func (s *fixedScanner) Scan() (*camera.QRScan, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	sum := sha256.Sum256([]byte(s.data))
+	return &camera.QRScan{RawData: s.data, Hash: hex.EncodeToString(sum[:]), Timestamp: s.now().Unix(), DeviceID: s.deviceID}, nil
+}
+
+func TestDeviceCapabilityBindsTheProof(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	ritual := `{
+		"name": "bound", "orisa": "eshu_router",
+		"ase": {"proof": "telemetry", "witnesses": 1},
+		"device": {"type": "drone", "id": "drone_001"}
+	}`
+	res, err := proved(t, vm, "drone_001", ritual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bound := eventOf(res, EventDeviceBound).(DeviceBound); bound != (DeviceBound{ID: "drone_001", Type: "drone"}) {
+		t.Errorf("bound %+v", bound)
+	}
+
+	if _, err := proved(t, vm, "drone_002", ritual); err == nil || !strings.Contains(err.Error(), "proof from drone_002, but the ritual is bound to device drone_001") {
+		t.Errorf("proof from another device: %v", err)
+	}
+}
+
+func TestAutoDeviceCapability(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	ritual := func(device string) string {
+		return `{
+			"name": "auto", "orisa": "eshu_router",
+			"ase": {"proof": "telemetry", "witnesses": 1, "auto_device": true}` + device + `
+		}`
+	}
+
+	// Without a declared @device the VM's own device is used
+	vm := newTestVM(t, &now)
+	if _, err := proved(t, vm, "drone_001", ritual("")); err == nil || !strings.Contains(err.Error(), "auto_device: no device detected") {
+		t.Errorf("no device: %v", err)
+	}
+	vm.Device = &DeviceAttr{Type: "phone", ID: "phone_007"}
+	res, err := proved(t, vm, "phone_007", ritual(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bound := eventOf(res, EventDeviceBound).(DeviceBound); bound != (DeviceBound{ID: "phone_007", Type: "phone", Auto: true}) {
+		t.Errorf("bound %+v", bound)
+	}
+	if _, err := proved(t, vm, "drone_001", ritual("")); err == nil || !strings.Contains(err.Error(), "bound to device phone_007") {
+		t.Errorf("proof from another device: %v", err)
+	}
+
+	// A declared @device wins over the VM's
+	res, err = proved(t, vm, "drone_001", ritual(`, "device": {"type": "drone", "id": "drone_001"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bound []DeviceBound
+	for _, e := range res.Events {
+		if d, ok := e.Data.(DeviceBound); ok {
+			bound = append(bound, d)
+		}
+	}
+	if len(bound) != 2 || bound[1] != (DeviceBound{ID: "drone_001", Type: "drone", Auto: true}) {
+		t.Errorf("bound %+v", bound)
+	}
+}
+
+func TestQRCapabilityCapturesTheProof(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	vm.Mesh = NewLocalMesh(vm.Witnesses, witness.NetworkLoRa)
+	scanner := &fixedScanner{deviceID: "scanner_001", data: "DELIVERY_PKG_12345_CHECKPOINT", now: vm.Now}
+	vm.Scanner = scanner
+	ritual := `{
+		"name": "scanned", "orisa": "eshu_router",
+		"ase": {"proof": "qr", "witnesses": 2, "qr": true}
+	}`
+	if err := vm.LoadSource("scanned.oso", []byte(ritual)); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := vm.Execute("scanned", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(scanner.data))
+	if scanned := eventOf(res, EventQRScanned).(QRScanned); scanned.Hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("scanned %+v", scanned)
+	}
+	if gathered := eventOf(res, EventWitnessesGathered).(WitnessesGathered); len(gathered.Devices) != 2 || len(res.Witnesses) != 2 {
+		t.Errorf("gathered %+v, counted %d", gathered, len(res.Witnesses))
+	}
+	if p := vm.Context.Proof; p.Type != "qr" || p.DeviceID != "scanner_001" || p.Receipt != hex.EncodeToString(sum[:]) {
+		t.Errorf("captured proof %+v", p)
+	}
+
+	// The same code can't seal the ritual twice
+	if _, err := vm.Execute("scanned", nil, nil); err == nil || !strings.Contains(err.Error(), "already") {
+		t.Errorf("rescanned: %v", err)
+	}
+
+	// A supplied proof is used as it is; no scan happens
+	proof := &Proof{Type: "telemetry", Receipt: strings.Repeat("ab", 32), Timestamp: now.Unix(), DeviceID: "drone_001"}
+	witnesses, err := vm.Mesh.Broadcast("scanned", proof, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = vm.Execute("scanned", proof, witnesses)
+	if err == nil || !strings.Contains(err.Error(), "proof type mismatch") || eventOf(res, EventQRScanned) != nil {
+		t.Errorf("supplied proof: %v", err)
+	}
+
+	scanner.err = errors.New("lens cap on")
+	if _, err := vm.Execute("scanned", nil, nil); err == nil || !strings.Contains(err.Error(), "qr: QR scan failed: lens cap on") {
+		t.Errorf("failed scan: %v", err)
+	}
+
+	// A ritual built in Go with the qr flag but no Àṣẹ requirement fails
+	// instead of panicking
+	vm.Rituals["raw_qr"] = &Ritual{
+		Name:       "raw_qr",
+		Orisa:      "eshu_router",
+		Attributes: map[string]Validator{"àṣẹ": &UniversalAse{ProofType: "qr", Witnesses: 1, QR: true}},
+	}
+	if _, err := vm.Execute("raw_qr", nil, nil); err == nil || !strings.Contains(err.Error(), "qr: ritual raw_qr has no Àṣẹ requirement") {
+		t.Errorf("no Àṣẹ requirement: %v", err)
+	}
+}
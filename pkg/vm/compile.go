@@ -0,0 +1,272 @@
+// OSOVM Compiler
+// Compiles a ritual and its statement body to stack machine bytecode
+
+package vm
+
+import (
+	"fmt"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+)
+
+// Compile compiles a ritual to bytecode. The program requires the
+// ritual's Àṣẹ, seals it, runs the Òrìṣà precompile, then the body; every
+// path ends by emitting the execution receipt and returning.
+func Compile(r *Ritual) (*Program, error) {
+	c := &compiler{
+		prog: &Program{
+			Version:    BytecodeVersion,
+			Ritual:     r.Name,
+			Orisa:      r.Orisa,
+			Args:       r.ArgValues,
+			Attributes: r.Attributes,
+		},
+		consts: make(map[string]int),
+	}
+	if c.prog.Args == nil {
+		c.prog.Args = argValues(r.Args)
+	}
+	if gas, ok := r.Attributes["gas_limit"].(*GasLimitAttr); ok {
+		c.prog.GasLimit = gas.Max
+	}
+
+	none := ast.Span{}
+	if r.Ase != nil {
+		c.emit(none, OpRequireProof, c.constant(string(r.Ase.ProofType)), r.Ase.Witnesses)
+	}
+	c.emit(none, OpSeal, 0, 0)
+	c.emit(none, OpPrecompile, c.constant(r.Orisa), 0)
+
+	c.block(r.Body)
+
+	c.emit(none, OpConst, c.constant(nil), 0)
+	c.emit(none, OpEmitReceipt, 0, 0)
+	c.emit(none, OpReturn, 0, 0)
+
+	if c.err != nil {
+		return nil, fmt.Errorf("compile %s: %w", r.Name, c.err)
+	}
+	return c.prog, nil
+}
+
+// argValues types the string args of rituals built in Go without ArgValues
+func argValues(args map[string]string) map[string]interface{} {
+	values := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		values[k] = v
+	}
+	return values
+}
+
+type compiler struct {
+	prog   *Program
+	consts map[string]int // constant pool index by encoding, for reuse
+	err    error
+}
+
+func (c *compiler) emit(span ast.Span, op Opcode, a, b int) int {
+	c.prog.Code = append(c.prog.Code, Instr{Op: op, A: a, B: b})
+	c.prog.Spans = append(c.prog.Spans, span)
+	return len(c.prog.Code) - 1
+}
+
+// patch points the jump at pc to the next instruction
+func (c *compiler) patch(pc int) {
+	c.prog.Code[pc].A = len(c.prog.Code)
+}
+
+// constant returns the pool index of v, adding it once
+func (c *compiler) constant(v interface{}) int {
+	key := string(encodeValue(v))
+	if i, ok := c.consts[key]; ok {
+		return i
+	}
+	c.prog.Constants = append(c.prog.Constants, v)
+	c.consts[key] = len(c.prog.Constants) - 1
+	return len(c.prog.Constants) - 1
+}
+
+func (c *compiler) local() int {
+	c.prog.Locals++
+	return c.prog.Locals - 1
+}
+
+func (c *compiler) fail(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// ========== Statements ==========
+
+func (c *compiler) block(body []ast.Stmt) {
+	for _, stmt := range body {
+		c.stmt(stmt)
+	}
+}
+
+func (c *compiler) stmt(stmt ast.Stmt) {
+	span := stmt.Extent()
+	switch s := stmt.(type) {
+	case *ast.Call:
+		for _, a := range s.Args {
+			c.emit(a.Value.Span, OpConst, c.constant(a.Value.Interface()), 0)
+		}
+		c.emit(span, OpCall, c.constant(s.Func.Name), len(s.Args))
+		if s.Result.Name != "" {
+			c.emit(s.Result.Span, OpStore, c.constant(s.Result.Name), 0)
+		} else {
+			c.emit(span, OpPop, 0, 0)
+		}
+
+	case *ast.Assign:
+		c.expr(s.Value)
+		c.emit(span, OpStore, c.constant(s.Variable.Name), 0)
+
+	case *ast.Return:
+		if s.Value != nil {
+			c.expr(s.Value)
+		} else {
+			c.emit(span, OpConst, c.constant(nil), 0)
+		}
+		c.emit(span, OpEmitReceipt, 0, 0)
+		c.emit(span, OpReturn, 1, 0)
+
+	case *ast.If:
+		c.expr(s.Cond)
+		c.emit(s.Cond.Extent(), OpBool, 0, 0)
+		toElse := c.emit(span, OpJumpIfFalse, 0, 0)
+		c.block(s.Then)
+		if len(s.Else) == 0 {
+			c.patch(toElse)
+			return
+		}
+		toEnd := c.emit(span, OpJump, 0, 0)
+		c.patch(toElse)
+		c.block(s.Else)
+		c.patch(toEnd)
+
+	case *ast.Loop:
+		if s.Count != nil {
+			c.repeat(s)
+		} else {
+			c.while(s)
+		}
+
+	default:
+		c.fail(fmt.Errorf("%s: unsupported statement %T", span.Start, stmt))
+	}
+}
+
+// repeat compiles a counted loop over two hidden locals:
+//
+//	count; COUNT; STORE_LOCAL n; CONST 0; STORE_LOCAL i
+//	top: LOAD_LOCAL i; LOAD_LOCAL n; LT; JUMP_IF_FALSE end
+//	     [LOAD_LOCAL i; STORE var] body; LOAD_LOCAL i; CONST 1; ADD; STORE_LOCAL i; JUMP top
+//	end:
+func (c *compiler) repeat(s *ast.Loop) {
+	span := s.Extent()
+	n, i := c.local(), c.local()
+
+	c.expr(s.Count)
+	c.emit(s.Count.Extent(), OpCount, 0, 0)
+	c.emit(span, OpStoreLocal, n, 0)
+	c.emit(span, OpConst, c.constant(int64(0)), 0)
+	c.emit(span, OpStoreLocal, i, 0)
+
+	top := len(c.prog.Code)
+	c.emit(span, OpLoadLocal, i, 0)
+	c.emit(span, OpLoadLocal, n, 0)
+	c.emit(span, OpLt, 0, 0)
+	exit := c.emit(span, OpJumpIfFalse, 0, 0)
+	if s.Var.Name != "" {
+		c.emit(span, OpLoadLocal, i, 0)
+		c.emit(s.Var.Span, OpStore, c.constant(s.Var.Name), 0)
+	}
+	c.block(s.Body)
+	c.emit(span, OpLoadLocal, i, 0)
+	c.emit(span, OpConst, c.constant(int64(1)), 0)
+	c.emit(span, OpAdd, 0, 0)
+	c.emit(span, OpStoreLocal, i, 0)
+	c.emit(span, OpJump, top, 0)
+	c.patch(exit)
+}
+
+// while compiles a condition loop; LOOP_GUARD fails once the body would
+// run more than max times
+func (c *compiler) while(s *ast.Loop) {
+	span := s.Extent()
+	guard := c.local()
+	c.emit(span, OpConst, c.constant(int64(0)), 0)
+	c.emit(span, OpStoreLocal, guard, 0)
+
+	top := len(c.prog.Code)
+	c.expr(s.Cond)
+	c.emit(s.Cond.Extent(), OpBool, 0, 0)
+	exit := c.emit(span, OpJumpIfFalse, 0, 0)
+	c.emit(span, OpLoopGuard, guard, c.constant(s.Max))
+	c.block(s.Body)
+	c.emit(span, OpJump, top, 0)
+	c.patch(exit)
+}
+
+// ========== Expressions ==========
+
+func (c *compiler) expr(expr ast.Expr) {
+	span := expr.Extent()
+	switch e := expr.(type) {
+	case *ast.Literal:
+		c.emit(span, OpConst, c.constant(e.Value.Interface()), 0)
+
+	case *ast.VarRef:
+		c.emit(span, OpLoad, c.constant(e.Name), 0)
+
+	case *ast.Unary:
+		c.expr(e.X)
+		if e.Op == "!" {
+			c.emit(span, OpNot, 0, 0)
+		} else {
+			c.emit(span, OpNeg, 0, 0)
+		}
+
+	case *ast.Binary:
+		switch e.Op {
+		case "&&", "||":
+			// x; BOOL; DUP; JUMP_IF_FALSE|TRUE end; POP; y; BOOL; end:
+			jump := OpJumpIfFalse
+			if e.Op == "||" {
+				jump = OpJumpIfTrue
+			}
+			c.expr(e.X)
+			c.emit(e.X.Extent(), OpBool, 0, 0)
+			c.emit(span, OpDup, 0, 0)
+			end := c.emit(span, jump, 0, 0)
+			c.emit(span, OpPop, 0, 0)
+			c.expr(e.Y)
+			c.emit(e.Y.Extent(), OpBool, 0, 0)
+			c.patch(end)
+		default:
+			op, ok := binaryOps[e.Op]
+			if !ok {
+				c.fail(fmt.Errorf("%s: unknown operator %s", span.Start, e.Op))
+				return
+			}
+			c.expr(e.X)
+			c.expr(e.Y)
+			c.emit(span, op, 0, 0)
+		}
+
+	case *ast.FuncCall:
+		for _, a := range e.Args {
+			c.expr(a)
+		}
+		if _, ok := builtins[e.Func.Name]; ok {
+			c.emit(span, OpBuiltin, c.constant(e.Func.Name), len(e.Args))
+		} else {
+			c.emit(span, OpCall, c.constant(e.Func.Name), len(e.Args))
+		}
+
+	default:
+		c.fail(fmt.Errorf("%s: unsupported expression %T", span.Start, expr))
+	}
+}
@@ -0,0 +1,660 @@
+// OSOVM Events
+// Typed events published while a ritual executes, and the sinks that
+// receive them: the emoji console log and JSON lines for machines
+
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/receipt"
+)
+
+// EventKind - The type of an event, e.g. "proof.validated"
+type EventKind string
+
+const (
+	EventRitualInvoked         EventKind = "ritual.invoked"
+	EventAttributesValidated   EventKind = "attributes.validated"
+	EventDeviceBound           EventKind = "device.bound"
+	EventQRScanned             EventKind = "qr.scanned"
+	EventWitnessesGathered     EventKind = "witnesses.gathered"
+	EventDeliveryTracked       EventKind = "delivery.tracked"
+	EventPayloadVerified       EventKind = "payload.verified"
+	EventProofValidated        EventKind = "proof.validated"
+	EventWitnessDiscarded      EventKind = "witness.discarded"
+	EventAseSealed             EventKind = "ase.sealed"
+	EventOrisaInvoked          EventKind = "orisa.invoked"
+	EventGuardDecided          EventKind = "guard.decided"
+	EventTitheRouted           EventKind = "tithe.routed"
+	EventVaultSettled          EventKind = "vault.settled"
+	EventFundsHeld             EventKind = "funds.held"
+	EventFundsReleased         EventKind = "funds.released"
+	EventLedgerRolledBack      EventKind = "ledger.rolled_back"
+	EventProofReleased         EventKind = "proof.released"
+	EventWitnessesOrchestrated EventKind = "witnesses.orchestrated"
+	EventReviewApproved        EventKind = "review.approved"
+	EventCacheLoaded           EventKind = "cache.loaded"
+	EventCacheStored           EventKind = "cache.stored"
+	EventCacheFailed           EventKind = "cache.failed"
+	EventArtifactForged        EventKind = "artifact.forged"
+	EventStreamOpened          EventKind = "stream.opened"
+	EventStreamFlowed          EventKind = "stream.flowed"
+	EventHerbPrescribed        EventKind = "herb.prescribed"
+	EventHerbsChecked          EventKind = "herbs.checked"
+	EventOracleAnswered        EventKind = "oracle.answered"
+	EventHostCalled            EventKind = "host.called"
+	EventVariableSet           EventKind = "variable.set"
+	EventValueReturned         EventKind = "value.returned"
+	EventReceiptSealed         EventKind = "receipt.sealed"
+	EventRitualCompleted       EventKind = "ritual.completed"
+	EventRitualFailed          EventKind = "ritual.failed"
+)
+
+// EventData is implemented by the payload of each event kind
+type EventData interface {
+	Kind() EventKind
+}
+
+// Event - One step of an execution. Seq numbers events from 1 within an
+// execution; Time comes from VM.Now.
+type Event struct {
+	Seq    int       `json:"seq"`
+	Kind   EventKind `json:"kind"`
+	Ritual string    `json:"ritual"`
+	Time   time.Time `json:"time"`
+	Data   EventData `json:"data"`
+}
+
+// ========== Event Payloads ==========
+
+type RitualInvoked struct {
+	Orisa string `json:"orisa"`
+}
+
+type AttributesValidated struct {
+	Count int `json:"count"`
+}
+
+// DeviceBound - The device proofs must come from; Auto is set when it was
+// detected rather than declared with @device
+type DeviceBound struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Auto bool   `json:"auto,omitempty"`
+}
+
+type QRScanned struct {
+	Hash string `json:"hash"`
+}
+
+// WitnessesGathered - Witnesses the mesh returned for a captured proof
+type WitnessesGathered struct {
+	Devices []string `json:"devices"`
+}
+
+type DeliveryTracked struct {
+	ID    string `json:"id,omitempty"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+	State string `json:"state,omitempty"`
+}
+
+type PayloadVerified struct {
+	ProofType ProofType `json:"proof_type"`
+}
+
+// ProofValidated - The proof passed Àṣẹ validation with Counted of the
+// Required witnesses
+type ProofValidated struct {
+	ProofType ProofType `json:"proof_type"`
+	Receipt   string    `json:"receipt"`
+	Counted   int       `json:"counted"`
+	Required  int       `json:"required"`
+}
+
+type WitnessDiscarded struct {
+	Index    int    `json:"index"`
+	DeviceID string `json:"device_id"`
+	Reason   string `json:"reason"`
+}
+
+type AseSealed struct{}
+
+type OrisaInvoked struct {
+	Orisa string `json:"orisa"`
+}
+
+// GuardDecided - obatala_guard allowed or denied the ritual
+type GuardDecided struct {
+	Decision *GuardDecision `json:"decision"`
+}
+
+// TitheRouted - eshu_router moved Amount out of From: Tithe to Wallet and
+// the rest to To, as ledger entry Entry (0 if nothing moved). Rule names
+// the route rule that matched, if any.
+type TitheRouted struct {
+	Amount int64  `json:"amount"`
+	From   string `json:"from"`
+	To     string `json:"to,omitempty"`
+	Tithe  int64  `json:"tithe"`
+	Wallet string `json:"wallet,omitempty"`
+	Rule   string `json:"rule,omitempty"`
+	Entry  uint64 `json:"entry,omitempty"`
+}
+
+// VaultSettled - sango_vault opened Vault with Opened (if new), then had
+// Source pay the Slashed penalty and the Split as ledger entry Entry.
+// Balance is the vault's balance afterwards.
+type VaultSettled struct {
+	Vault   string  `json:"vault"`
+	Opened  int64   `json:"opened,omitempty"`
+	Funder  string  `json:"funder,omitempty"` // account that paid the opening balance
+	Source  string  `json:"source"`
+	Slashed int64   `json:"slashed,omitempty"`
+	Reason  string  `json:"reason,omitempty"`
+	Split   []Share `json:"split,omitempty"`
+	Entry   uint64  `json:"entry,omitempty"`
+	Balance int64   `json:"balance"`
+}
+
+// Share - One account's part of a split
+type Share struct {
+	Account string `json:"account"`
+	Amount  int64  `json:"amount"`
+}
+
+type FundsHeld struct {
+	Hold    uint64 `json:"hold"`
+	Account string `json:"account"`
+	Amount  int64  `json:"amount"`
+}
+
+type FundsReleased struct {
+	Hold    uint64 `json:"hold"`
+	Account string `json:"account"`
+	Amount  int64  `json:"amount"`
+}
+
+// LedgerRolledBack - The execution failed, so the Entries it posted (and
+// any holds or streams it changed) were undone
+type LedgerRolledBack struct {
+	Entries int `json:"entries"`
+}
+
+// ProofReleased - The execution failed, so its proof Receipt may be used
+// again
+type ProofReleased struct {
+	Receipt string `json:"receipt"`
+}
+
+// WitnessesOrchestrated - oya_witness has Counted of the Required
+// witnesses on Network, Gathered of them from the mesh
+type WitnessesOrchestrated struct {
+	Network  string   `json:"network,omitempty"`
+	Required int      `json:"required"`
+	Counted  int      `json:"counted"`
+	Gathered int      `json:"gathered"`
+	Devices  []string `json:"devices"`
+}
+
+// ReviewApproved - witness_review_approval counted Counted of the Required
+// @review witnesses, Gathered of them from the mesh. Subject is the receipt
+// they attested: the Àṣẹ proof's, or the review's own without a proof.
+type ReviewApproved struct {
+	Subject  string   `json:"subject"`
+	Required int      `json:"required"`
+	Counted  int      `json:"counted"`
+	Gathered int      `json:"gathered"`
+	Devices  []string `json:"devices"`
+}
+
+// CacheLoaded - yemoja_cache looked up Key; on a hit the Restored
+// variables were loaded from an entry that lives until Expires
+type CacheLoaded struct {
+	Key      string   `json:"key"`
+	Hit      bool     `json:"hit"`
+	Restored []string `json:"restored,omitempty"`
+	Expires  int64    `json:"expires,omitempty"`
+}
+
+// CacheStored - A cache write was applied once the execution completed
+type CacheStored struct {
+	Key     string `json:"key"`
+	Expires int64  `json:"expires"`
+}
+
+// CacheFailed - A cache write could not be applied. The receipt is
+// already sealed by then, so the execution still completes.
+type CacheFailed struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// ArtifactForged - ogun_forge passed every stage and forged Artifact
+type ArtifactForged struct {
+	Artifact string       `json:"artifact"`
+	Stages   []ForgeStage `json:"stages"`
+}
+
+// StreamOpened - oshun_river held Amount in From to pay To over Duration
+// seconds
+type StreamOpened struct {
+	Stream   uint64 `json:"stream"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Amount   int64  `json:"amount"`
+	Duration int64  `json:"duration"`
+	Hold     uint64 `json:"hold"`
+}
+
+// StreamFlowed - oshun_river paid Flowed as ledger entry Entry (0 if
+// nothing had accrued), Paid of Amount so far
+type StreamFlowed struct {
+	Stream uint64 `json:"stream"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Flowed int64  `json:"flowed"`
+	Paid   int64  `json:"paid"`
+	Amount int64  `json:"amount"`
+	Entry  uint64 `json:"entry,omitempty"`
+	Closed bool   `json:"closed,omitempty"`
+}
+
+// HerbPrescribed - osanyin_herb found the herb and it is available in Locale
+type HerbPrescribed struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Class  string `json:"class"`
+	Locale string `json:"locale,omitempty"`
+}
+
+// HerbsChecked - check_herb_interactions found no interaction among Herbs
+type HerbsChecked struct {
+	Herbs []string `json:"herbs"`
+}
+
+// OracleAnswered - orunmila_oracle's answer to Query
+type OracleAnswered struct {
+	Query      string      `json:"query"`
+	Value      interface{} `json:"value"`
+	Source     string      `json:"source"`
+	Confidence float64     `json:"confidence"`
+}
+
+type HostCalled struct {
+	Function string        `json:"function"`
+	Args     []interface{} `json:"args"`
+}
+
+// VariableSet - A variable was stored. The Value of a variable hidden by
+// @mask is its hash.
+type VariableSet struct {
+	Name   string      `json:"name"`
+	Value  interface{} `json:"value"`
+	Masked bool        `json:"masked,omitempty"`
+}
+
+// ValueReturned - A return statement ended the body
+type ValueReturned struct {
+	Value interface{} `json:"value"`
+}
+
+// ReceiptSealed - The execution receipt was signed and appended to the
+// receipt log after Prev
+type ReceiptSealed struct {
+	Seq      uint64 `json:"seq"`
+	Hash     string `json:"hash"`
+	Prev     string `json:"prev"`
+	Operator string `json:"operator"`
+}
+
+type RitualCompleted struct {
+	Return   interface{} `json:"return"`
+	GasUsed  int64       `json:"gas_used"`
+	GasLimit int64       `json:"gas_limit"`
+	Receipt  string      `json:"receipt"`
+}
+
+type RitualFailed struct {
+	Error string `json:"error"`
+}
+
+func (RitualInvoked) Kind() EventKind         { return EventRitualInvoked }
+func (AttributesValidated) Kind() EventKind   { return EventAttributesValidated }
+func (DeviceBound) Kind() EventKind           { return EventDeviceBound }
+func (QRScanned) Kind() EventKind             { return EventQRScanned }
+func (WitnessesGathered) Kind() EventKind     { return EventWitnessesGathered }
+func (DeliveryTracked) Kind() EventKind       { return EventDeliveryTracked }
+func (PayloadVerified) Kind() EventKind       { return EventPayloadVerified }
+func (ProofValidated) Kind() EventKind        { return EventProofValidated }
+func (WitnessDiscarded) Kind() EventKind      { return EventWitnessDiscarded }
+func (AseSealed) Kind() EventKind             { return EventAseSealed }
+func (OrisaInvoked) Kind() EventKind          { return EventOrisaInvoked }
+func (GuardDecided) Kind() EventKind          { return EventGuardDecided }
+func (TitheRouted) Kind() EventKind           { return EventTitheRouted }
+func (VaultSettled) Kind() EventKind          { return EventVaultSettled }
+func (FundsHeld) Kind() EventKind             { return EventFundsHeld }
+func (FundsReleased) Kind() EventKind         { return EventFundsReleased }
+func (LedgerRolledBack) Kind() EventKind      { return EventLedgerRolledBack }
+func (ProofReleased) Kind() EventKind         { return EventProofReleased }
+func (WitnessesOrchestrated) Kind() EventKind { return EventWitnessesOrchestrated }
+func (ReviewApproved) Kind() EventKind        { return EventReviewApproved }
+func (CacheLoaded) Kind() EventKind           { return EventCacheLoaded }
+func (CacheStored) Kind() EventKind           { return EventCacheStored }
+func (CacheFailed) Kind() EventKind           { return EventCacheFailed }
+func (ArtifactForged) Kind() EventKind        { return EventArtifactForged }
+func (StreamOpened) Kind() EventKind          { return EventStreamOpened }
+func (StreamFlowed) Kind() EventKind          { return EventStreamFlowed }
+func (HerbPrescribed) Kind() EventKind        { return EventHerbPrescribed }
+func (HerbsChecked) Kind() EventKind          { return EventHerbsChecked }
+func (OracleAnswered) Kind() EventKind        { return EventOracleAnswered }
+func (HostCalled) Kind() EventKind            { return EventHostCalled }
+func (VariableSet) Kind() EventKind           { return EventVariableSet }
+func (ValueReturned) Kind() EventKind         { return EventValueReturned }
+func (ReceiptSealed) Kind() EventKind         { return EventReceiptSealed }
+func (RitualCompleted) Kind() EventKind       { return EventRitualCompleted }
+func (RitualFailed) Kind() EventKind          { return EventRitualFailed }
+
+// emit records an event in the execution context and publishes it to
+// every sink
+func (vm *VM) emit(data EventData) {
+	ctx := vm.Context
+	data = ctx.redactEvent(data)
+	e := Event{
+		Seq:    len(ctx.Events) + 1,
+		Kind:   data.Kind(),
+		Ritual: ctx.Ritual.Name,
+		Time:   vm.Now(),
+		Data:   data,
+	}
+	ctx.Events = append(ctx.Events, e)
+	for _, sink := range vm.Sinks {
+		sink.Emit(e)
+	}
+}
+
+// ========== Sinks ==========
+
+// EventSink receives every event as it is emitted. Sinks must not fail the
+// execution; ones that can fail keep the error for the caller to inspect.
+type EventSink interface {
+	Emit(e Event)
+}
+
+// ConsoleSink - The human-readable emoji log
+type ConsoleSink struct {
+	w io.Writer
+}
+
+// NewConsoleSink writes the emoji log to w
+func NewConsoleSink(w io.Writer) *ConsoleSink {
+	return &ConsoleSink{w: w}
+}
+
+// orisaBanners is what each precompile announces on the console
+var orisaBanners = map[string]string{
+	"eshu_router":     "🍶 Èṣù routes the action...",
+	"obatala_guard":   "🤍 Ọbàtálá enforces quorum...",
+	"sango_vault":     "⚡ Ṣàngó manages the vault...",
+	"oya_witness":     "🌪️  Ọ̀yá gathers the witnesses...",
+	"yemoja_cache":    "🌊 Yemọja keeps the ritual's memory...",
+	"ogun_forge":      "⚒️  Ògún fires the forge...",
+	"oshun_river":     "🍯 Ọ̀ṣun lets the river flow...",
+	"osanyin_herb":    "🌿 Òsanyìn consults the herbs...",
+	"orunmila_oracle": "📿 Ọrúnmìlà consults the oracle...",
+}
+
+func (s *ConsoleSink) Emit(e Event) {
+	var lines []string
+	switch d := e.Data.(type) {
+	case RitualInvoked:
+		lines = append(lines, fmt.Sprintf("🔮 Invoking %s ritual...", d.Orisa))
+	case AttributesValidated:
+		lines = append(lines, fmt.Sprintf("📜 Attributes validated: %d", d.Count))
+	case DeviceBound:
+		if d.Auto {
+			lines = append(lines, fmt.Sprintf("🤖 Auto-detected device: %s (%s)", d.ID, d.Type))
+		} else {
+			lines = append(lines, fmt.Sprintf("📱 Device: %s (%s)", d.ID, d.Type))
+		}
+	case QRScanned:
+		lines = append(lines, fmt.Sprintf("📷 QR scanned: %s", short(d.Hash)))
+	case WitnessesGathered:
+		lines = append(lines, fmt.Sprintf("📡 Broadcast → %d nodes echo → seal", len(d.Devices)))
+		for _, id := range d.Devices {
+			lines = append(lines, fmt.Sprintf("   👁️  %s confirmed", id))
+		}
+	case DeliveryTracked:
+		switch {
+		case d.From != "" || d.To != "":
+			lines = append(lines, fmt.Sprintf("📦 Delivery: %s → %s", d.From, d.To))
+		case d.ID != "":
+			lines = append(lines, fmt.Sprintf("📦 Delivery: %s (%s)", d.ID, d.State))
+		}
+	case PayloadVerified:
+		lines = append(lines, fmt.Sprintf("🧾 Payload verified: %s", d.ProofType))
+	case ProofValidated:
+		lines = append(lines,
+			fmt.Sprintf("📡 Proof validated: %s (%s)", d.ProofType, short(d.Receipt)),
+			fmt.Sprintf("👥 Witnesses confirmed: %d/%d", d.Counted, d.Required))
+	case WitnessDiscarded:
+		lines = append(lines, fmt.Sprintf("   ⚠️  witness %d (%s) discarded: %s", d.Index, d.DeviceID, d.Reason))
+	case AseSealed:
+		lines = append(lines, "✅ Àṣẹ sealed with proof + witnesses")
+	case OrisaInvoked:
+		if banner, ok := orisaBanners[d.Orisa]; ok {
+			lines = append(lines, banner)
+		}
+	case GuardDecided:
+		if d.Decision.Allow {
+			lines = append(lines, "⚖️  Guard allows")
+		} else {
+			lines = append(lines, "⛔ Guard denies")
+		}
+		if len(d.Decision.Checks) == 0 {
+			lines = append(lines, "   no policies attached")
+		}
+		for _, c := range d.Decision.Checks {
+			mark := "✓"
+			if !c.Passed {
+				mark = "✗"
+			}
+			lines = append(lines, fmt.Sprintf("   %s %s: %s", mark, c.Policy, c.Reason))
+		}
+	case TitheRouted:
+		if d.Tithe > 0 {
+			lines = append(lines, fmt.Sprintf("💸 Tithe: %d of %d from %s → %s", d.Tithe, d.Amount, d.From, d.Wallet))
+		} else {
+			lines = append(lines, fmt.Sprintf("💸 Tithe exempt (%s): %d from %s", d.Rule, d.Amount, d.From))
+		}
+		if d.To != "" {
+			lines = append(lines, fmt.Sprintf("   %s receives %d", d.To, d.Amount-d.Tithe))
+		}
+	case VaultSettled:
+		if d.Opened > 0 {
+			lines = append(lines, fmt.Sprintf("🏦 Vault %s opened with %d from %s", d.Vault, d.Opened, d.Funder))
+		}
+		if d.Slashed > 0 {
+			lines = append(lines, fmt.Sprintf("⚔️  Slashed %d from %s: %s", d.Slashed, d.Source, d.Reason))
+		}
+		if len(d.Split) > 0 {
+			parts := make([]string, len(d.Split))
+			for i, s := range d.Split {
+				parts[i] = fmt.Sprintf("%d → %s", s.Amount, s.Account)
+			}
+			lines = append(lines, fmt.Sprintf("🏛️  Shrine split from %s: %s", d.Source, strings.Join(parts, ", ")))
+		}
+		lines = append(lines, fmt.Sprintf("   vault balance: %d", d.Balance))
+	case FundsHeld:
+		lines = append(lines, fmt.Sprintf("🔒 Held %d in %s (hold #%d)", d.Amount, d.Account, d.Hold))
+	case FundsReleased:
+		lines = append(lines, fmt.Sprintf("🔓 Released %d in %s (hold #%d)", d.Amount, d.Account, d.Hold))
+	case LedgerRolledBack:
+		noun := "entries"
+		if d.Entries == 1 {
+			noun = "entry"
+		}
+		lines = append(lines, fmt.Sprintf("↩️  Ledger rolled back: %d %s undone", d.Entries, noun))
+	case ProofReleased:
+		lines = append(lines, fmt.Sprintf("↩️  Proof receipt %s released for a retry", short(d.Receipt)))
+	case WitnessesOrchestrated:
+		network := d.Network
+		if network == "" {
+			network = "any network"
+		}
+		lines = append(lines, fmt.Sprintf("👥 Witnesses on %s: %d/%d (%d gathered)", network, d.Counted, d.Required, d.Gathered))
+	case ReviewApproved:
+		lines = append(lines, fmt.Sprintf("👁️  Review approved by %d/%d witnesses (%d gathered)", d.Counted, d.Required, d.Gathered))
+	case CacheLoaded:
+		if d.Hit {
+			lines = append(lines, fmt.Sprintf("💾 Cache hit: %s (restored %s)", d.Key, strings.Join(d.Restored, ", ")))
+		} else {
+			lines = append(lines, fmt.Sprintf("💾 Cache miss: %s", d.Key))
+		}
+	case CacheStored:
+		lines = append(lines, fmt.Sprintf("💾 Cached %s until %s", d.Key, time.Unix(d.Expires, 0).UTC().Format(time.RFC3339)))
+	case CacheFailed:
+		lines = append(lines, fmt.Sprintf("⚠️  Cache write %s failed: %s", d.Key, d.Error))
+	case ArtifactForged:
+		for _, s := range d.Stages {
+			lines = append(lines, fmt.Sprintf("   ✓ %s: %s", s.Name, s.Detail))
+		}
+		lines = append(lines, fmt.Sprintf("🔨 Artifact forged: %s", short(d.Artifact)))
+	case StreamOpened:
+		lines = append(lines, fmt.Sprintf("💧 Stream #%d: %d from %s → %s over %ds (hold #%d)", d.Stream, d.Amount, d.From, d.To, d.Duration, d.Hold))
+	case StreamFlowed:
+		lines = append(lines, fmt.Sprintf("💧 Stream #%d flowed %d to %s: %d/%d paid", d.Stream, d.Flowed, d.To, d.Paid, d.Amount))
+		if d.Closed {
+			lines = append(lines, "   stream closed")
+		}
+	case HerbPrescribed:
+		line := fmt.Sprintf("🌿 Herb: %s (%s, %s)", d.Key, d.Name, d.Class)
+		if d.Locale != "" {
+			line += " available in " + d.Locale
+		}
+		lines = append(lines, line)
+	case HerbsChecked:
+		lines = append(lines, fmt.Sprintf("🌿 Interactions checked for %s: none", strings.Join(d.Herbs, ", ")))
+	case OracleAnswered:
+		lines = append(lines, fmt.Sprintf("📿 Oracle (%s): %s → %s", d.Source, d.Query, formatValue(d.Value)))
+	case HostCalled:
+		lines = append(lines, fmt.Sprintf("  → Calling %s", d.Function))
+	case VariableSet:
+		lines = append(lines, fmt.Sprintf("  → %s = %s", d.Name, formatValue(d.Value)))
+	case ValueReturned:
+		lines = append(lines, fmt.Sprintf("  → Returning: %s", formatValue(d.Value)))
+	case ReceiptSealed:
+		prev := "genesis"
+		if d.Prev != "" {
+			prev = short(d.Prev)
+		}
+		lines = append(lines, fmt.Sprintf("🔏 Receipt #%d sealed: %s (prev %s)", d.Seq, short(d.Hash), prev))
+	case RitualCompleted:
+		lines = append(lines,
+			fmt.Sprintf("⛽ Gas used: %d/%d", d.GasUsed, d.GasLimit),
+			fmt.Sprintf("🧾 Execution receipt: %s", short(d.Receipt)),
+			"✨ Ritual complete. Àṣẹ flows.")
+	}
+	// Failures are reported by whoever called Execute
+	for _, line := range lines {
+		fmt.Fprintln(s.w, line)
+	}
+}
+
+// short abbreviates a hash for the console
+func short(hash string) string {
+	if len(hash) <= 16 {
+		return hash
+	}
+	return hash[:16] + "..."
+}
+
+// JSONLinesSink - One JSON object per event, for machines
+type JSONLinesSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewJSONLinesSink writes events to w as JSON lines
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{enc: json.NewEncoder(w)}
+}
+
+func (s *JSONLinesSink) Emit(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = s.enc.Encode(e)
+	}
+}
+
+// Err returns the first write error; later events are dropped after one
+func (s *JSONLinesSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// ========== Execution Result ==========
+
+// ExecutionStatus - How an execution ended
+type ExecutionStatus string
+
+const (
+	StatusCompleted ExecutionStatus = "completed"
+	StatusFailed    ExecutionStatus = "failed"
+)
+
+// ExecutionResult - Everything an execution produced. Witnesses are the
+// ones counted toward the Àṣẹ quorum; Sealed is the signed receipt.
+type ExecutionResult struct {
+	Ritual    string                 `json:"ritual"`
+	Status    ExecutionStatus        `json:"status"`
+	Return    interface{}            `json:"return"`
+	Variables map[string]interface{} `json:"variables"`
+	GasUsed   int64                  `json:"gas_used"`
+	GasLimit  int64                  `json:"gas_limit"`
+	Witnesses []Witness              `json:"witnesses"`
+	Receipt   string                 `json:"receipt,omitempty"`
+	Sealed    *receipt.Receipt       `json:"sealed,omitempty"`
+	Guard     *GuardDecision         `json:"guard,omitempty"`
+	Events    []Event                `json:"events"`
+	StartedAt time.Time              `json:"started_at"`
+	Duration  time.Duration          `json:"duration"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// result builds the ExecutionResult of the current context
+func (vm *VM) result(started time.Time, err error) *ExecutionResult {
+	ctx := vm.Context
+	res := &ExecutionResult{
+		Ritual:    ctx.Ritual.Name,
+		Status:    StatusCompleted,
+		Return:    ctx.redact(ctx.Return),
+		Variables: ctx.redact(ctx.Variables).(map[string]interface{}),
+		Witnesses: ctx.Counted,
+		Receipt:   ctx.Receipt,
+		Sealed:    ctx.Sealed,
+		Guard:     ctx.redactGuard(),
+		StartedAt: started,
+		Duration:  vm.Now().Sub(started),
+	}
+	if ctx.Gas != nil {
+		res.GasUsed, res.GasLimit = ctx.Gas.Used, ctx.Gas.Limit
+	}
+	if err != nil {
+		res.Status, res.Error = StatusFailed, strings.TrimSpace(err.Error())
+		vm.emit(RitualFailed{Error: res.Error})
+	} else {
+		vm.emit(RitualCompleted{Return: res.Return, GasUsed: res.GasUsed, GasLimit: res.GasLimit, Receipt: res.Receipt})
+	}
+	res.Events = ctx.Events
+	return res
+}
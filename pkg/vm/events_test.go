@@ -0,0 +1,289 @@
+package vm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/witness"
+)
+
+const eventsRitual = `{
+	"name": "events", "orisa": "eshu_router",
+	"attributes": {"deadline": {"unix": 1800000000}},
+	"args": {"d": %s},
+	"statements": [
+		{"type": "assign", "data": {"variable": "x", "expr": "10 / d"}},
+		{"type": "return", "data": {"expr": "x"}}
+	]
+}`
+
+func eventsSource(d string) string {
+	return fmt.Sprintf(eventsRitual, d)
+}
+
+// newTestVM returns a VM with no sinks whose clocks read *now
+func newTestVM(t *testing.T, now *time.Time) *VM {
+	t.Helper()
+	vm := NewVM()
+	vm.Sinks = nil
+	clock := func() time.Time { return *now }
+	vm.Now, vm.Ledger.Now, vm.Cache.Now, vm.Witnesses.Now = clock, clock, clock, clock
+	return vm
+}
+
+// run loads a JSON ritual and executes it without a proof
+func run(t *testing.T, vm *VM, source string) (*ExecutionResult, error) {
+	t.Helper()
+	if err := vm.LoadSource("test.oso", []byte(source)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	name := source[strings.Index(source, `"name": "`)+9:]
+	return vm.Execute(name[:strings.Index(name, `"`)], nil, nil)
+}
+
+// proofs counts the proofs proved makes, so each has its own receipt
+var proofs int
+
+// proved loads a JSON ritual and executes it with a fresh telemetry proof
+// from device, its witnesses gathered from the VM's mesh
+func proved(t *testing.T, vm *VM, device, source string) (*ExecutionResult, error) {
+	t.Helper()
+	if err := vm.LoadSource("test.oso", []byte(source)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	name := source[strings.Index(source, `"name": "`)+9:]
+	name = name[:strings.Index(name, `"`)]
+	proofs++
+	proof := &Proof{Type: "telemetry", Receipt: fmt.Sprintf("%064x", proofs), Timestamp: vm.Now().Unix(), DeviceID: device}
+	if vm.Mesh == nil {
+		vm.Mesh = NewLocalMesh(vm.Witnesses, witness.NetworkLoRa)
+	}
+	witnesses, err := vm.Mesh.Broadcast(name, proof, vm.Rituals[name].Ase.Witnesses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vm.Execute(name, proof, witnesses)
+}
+
+// eventOf returns the data of the first event of kind
+func eventOf(res *ExecutionResult, kind EventKind) EventData {
+	for _, e := range res.Events {
+		if e.Kind == kind {
+			return e.Data
+		}
+	}
+	return nil
+}
+
+func kinds(events []Event) []EventKind {
+	out := make([]EventKind, len(events))
+	for i, e := range events {
+		out[i] = e.Kind
+	}
+	return out
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	vm := newTestVM(t, &now)
+	var buf bytes.Buffer
+	sink := NewJSONLinesSink(&buf)
+	vm.Sinks = []EventSink{sink}
+
+	res, err := run(t, vm, eventsSource("2"))
+	if err != nil || sink.Err() != nil {
+		t.Fatalf("run: %v, sink: %v", err, sink.Err())
+	}
+
+	// One object per line, in the order and with the fields of res.Events
+	var lines []map[string]json.RawMessage
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %d is not a JSON object: %v: %s", len(lines)+1, err, scanner.Text())
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != len(res.Events) {
+		t.Fatalf("%d lines for %d events", len(lines), len(res.Events))
+	}
+	for i, line := range lines {
+		if len(line) != 5 {
+			t.Errorf("line %d has fields %v", i+1, line)
+		}
+		var e struct {
+			Seq    int
+			Kind   EventKind
+			Ritual string
+			Time   time.Time
+		}
+		raw, _ := json.Marshal(line)
+		json.Unmarshal(raw, &e)
+		if e.Seq != i+1 || e.Kind != res.Events[i].Kind || e.Ritual != "events" || !e.Time.Equal(now) {
+			t.Errorf("line %d: %+v", i+1, e)
+		}
+		data, _ := json.Marshal(res.Events[i].Data)
+		if string(line["data"]) != string(data) {
+			t.Errorf("line %d data %s, want %s", i+1, line["data"], data)
+		}
+	}
+
+	// Payload fields use their JSON names
+	var completed RitualCompleted
+	if err := json.Unmarshal(lines[len(lines)-1]["data"], &completed); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(lines[len(lines)-1]["data"]), `"gas_used":`) || completed.GasUsed != res.GasUsed || completed.Return != float64(5) {
+		t.Errorf("ritual.completed data: %s", lines[len(lines)-1]["data"])
+	}
+}
+
+type failingWriter struct{ writes int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestJSONLinesSinkKeepsTheFirstError(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	w := &failingWriter{}
+	sink := NewJSONLinesSink(w)
+	vm.Sinks = []EventSink{sink}
+
+	// A sink that cannot write does not fail the execution
+	if _, err := run(t, vm, eventsSource("2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Err(); err == nil || err.Error() != "disk full" {
+		t.Errorf("sink error: %v", err)
+	}
+	if w.writes != 1 {
+		t.Errorf("%d writes after the first failed", w.writes-1)
+	}
+}
+
+func TestExecutionResult(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	t.Run("completed", func(t *testing.T) {
+		vm := newTestVM(t, &now)
+		res, err := run(t, vm, eventsSource("2"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Ritual != "events" || res.Status != StatusCompleted || res.Error != "" || res.Return != int64(5) || res.Variables["x"] != int64(5) {
+			t.Errorf("result: %+v", res)
+		}
+		if res.GasUsed <= 0 || res.GasUsed > res.GasLimit || res.GasLimit != DefaultGasLimit {
+			t.Errorf("gas %d of %d", res.GasUsed, res.GasLimit)
+		}
+		if !res.StartedAt.Equal(now) || res.Duration != 0 {
+			t.Errorf("started %v, took %v", res.StartedAt, res.Duration)
+		}
+		got := kinds(res.Events)
+		if got[0] != EventRitualInvoked || got[len(got)-1] != EventRitualCompleted || eventOf(res, EventRitualFailed) != nil {
+			t.Errorf("events: %v", got)
+		}
+		for i, e := range res.Events {
+			if e.Seq != i+1 || e.Ritual != "events" {
+				t.Errorf("event %d: %+v", i, e)
+			}
+		}
+		completed := eventOf(res, EventRitualCompleted).(RitualCompleted)
+		if completed.Return != res.Return || completed.GasUsed != res.GasUsed || completed.GasLimit != res.GasLimit || completed.Receipt != res.Receipt {
+			t.Errorf("ritual.completed %+v does not match the result", completed)
+		}
+		if returned, ok := eventOf(res, EventValueReturned).(ValueReturned); !ok || returned.Value != int64(5) {
+			t.Errorf("value.returned: %+v", returned)
+		}
+	})
+
+	t.Run("runtime failure", func(t *testing.T) {
+		vm := newTestVM(t, &now)
+		res, err := run(t, vm, eventsSource("0"))
+		var rt *RuntimeError
+		if !errors.As(err, &rt) || res == nil {
+			t.Fatalf("got %v", err)
+		}
+		if res.Status != StatusFailed || res.Error != strings.TrimSpace(err.Error()) || !strings.Contains(res.Error, "division by zero") {
+			t.Errorf("status %s, error %q", res.Status, res.Error)
+		}
+		if res.Return != nil || res.Variables["x"] != nil {
+			t.Errorf("a failed division left return %v, variables %v", res.Return, res.Variables)
+		}
+		// Gas spent before the failure is still reported
+		if res.GasUsed <= 0 || res.GasLimit != DefaultGasLimit {
+			t.Errorf("gas %d of %d", res.GasUsed, res.GasLimit)
+		}
+		got := kinds(res.Events)
+		if got[len(got)-1] != EventRitualFailed || eventOf(res, EventRitualCompleted) != nil || eventOf(res, EventValueReturned) != nil {
+			t.Errorf("events: %v", got)
+		}
+		if failed := eventOf(res, EventRitualFailed).(RitualFailed); failed.Error != res.Error {
+			t.Errorf("ritual.failed %q, result %q", failed.Error, res.Error)
+		}
+	})
+
+	t.Run("validation failure", func(t *testing.T) {
+		vm := newTestVM(t, &now)
+		invalid := strings.Replace(eventsSource("2"), `"deadline": {"unix": 1800000000}`, `"tithe": {"rate": 100}`, 1)
+		res, err := run(t, vm, invalid)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || res == nil {
+			t.Fatalf("got %v", err)
+		}
+		if res.Status != StatusFailed || !strings.Contains(res.Error, "tithe rate must be exactly 369") || res.Receipt != "" || res.Sealed != nil {
+			t.Errorf("result: %+v", res)
+		}
+		// Nothing ran, so nothing was charged
+		if res.GasUsed != 0 || res.Return != nil {
+			t.Errorf("gas %d, return %v", res.GasUsed, res.Return)
+		}
+		got := kinds(res.Events)
+		if len(got) != 2 || got[0] != EventRitualInvoked || got[1] != EventRitualFailed {
+			t.Errorf("events: %v", got)
+		}
+	})
+
+	t.Run("hook failure", func(t *testing.T) {
+		late := time.Unix(1_900_000_000, 0)
+		vm := newTestVM(t, &late)
+		res, err := run(t, vm, eventsSource("2"))
+		if err == nil || res.Status != StatusFailed || res.Error != strings.TrimSpace(err.Error()) || !strings.Contains(res.Error, "deadline passed") {
+			t.Fatalf("got %v, result %+v", err, res)
+		}
+		if res.Receipt != "" || res.Sealed != nil || res.Variables["x"] != nil {
+			t.Errorf("result: %+v", res)
+		}
+		if got := kinds(res.Events); got[len(got)-1] != EventRitualFailed || eventOf(res, EventAttributesValidated) == nil {
+			t.Errorf("events: %v", got)
+		}
+	})
+
+	// The result marshals with its documented field names
+	vm := newTestVM(t, &now)
+	res, _ := run(t, vm, eventsSource("0"))
+	raw, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	json.Unmarshal(raw, &fields)
+	for _, name := range []string{"ritual", "status", "return", "variables", "gas_used", "gas_limit", "events", "error"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("no %q in %s", name, raw)
+		}
+	}
+	if string(fields["status"]) != `"failed"` {
+		t.Errorf("status %s", fields["status"])
+	}
+}
@@ -0,0 +1,93 @@
+// OSOVM Ògún Forge
+// The ogun_forge precompile: runs the ritual through the forge pipeline
+// (safety, simulation, compute) and forges an artifact whose hash commits
+// to the program, its inputs and every stage
+
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ase-lang/osovm/internal/tagged"
+)
+
+// forgeDomain tags artifact hashes apart from the program and receipt
+// hashes they commit to
+const forgeDomain = "osovm/forge-artifact/v1"
+
+// ForgeStage - One pipeline stage and what it established
+type ForgeStage struct {
+	Name   string `json:"name"`
+	Detail string `json:"detail"`
+}
+
+// forgeStages checks the ritual's forge attributes in pipeline order
+func (vm *VM) forgeStages() ([]ForgeStage, error) {
+	attrs := vm.Context.Ritual.Attributes
+	var stages []ForgeStage
+
+	// 1. Safety: an e-stop check needs a signed checklist with an estop
+	safety, hasSafety := attrs["safety"].(*SafetyAttr)
+	if hw, ok := attrs["hardware"].(*HardwareAttr); ok && hw.EstopCheck {
+		if !hasSafety || !containsString(safety.Items, "estop") {
+			return nil, fmt.Errorf("@hardware(estop_check) needs @safety items to include estop")
+		}
+	}
+	if hasSafety {
+		if safety.SignedByVisa == "" {
+			return nil, fmt.Errorf("@safety %s is not signed", safety.ID)
+		}
+		stages = append(stages, ForgeStage{Name: "safety", Detail: fmt.Sprintf("%s: %s, signed by %s",
+			safety.ID, strings.Join(safety.Items, ", "), safety.SignedByVisa)})
+	}
+
+	// 2. Simulation
+	if sim, ok := attrs["sim"].(*SimAttr); ok {
+		if sim.Steps <= 0 {
+			return nil, fmt.Errorf("@sim episode %d must run at least one step", sim.EpisodeID)
+		}
+		stages = append(stages, ForgeStage{Name: "simulate", Detail: fmt.Sprintf("episode %d, %d steps", sim.EpisodeID, sim.Steps)})
+	}
+
+	// 3. Compute
+	if compute, ok := attrs["compute"].(*ComputeAttr); ok {
+		if compute.Hours <= 0 {
+			return nil, fmt.Errorf("@compute must reserve at least one hour")
+		}
+		stages = append(stages, ForgeStage{Name: "compute", Detail: fmt.Sprintf("%d h of %s on %s", compute.Hours, compute.GPU, compute.Provider)})
+	}
+	return stages, nil
+}
+
+// artifactHash commits to the program hash, the ritual's inputs and the
+// stages: the tagged hash of each, stages as their count then each name
+// and detail
+func artifactHash(ritual, programHash string, inputs []byte, stages []ForgeStage) string {
+	fields := []string{ritual, programHash, string(inputs), strconv.Itoa(len(stages))}
+	for _, s := range stages {
+		fields = append(fields, s.Name, s.Detail)
+	}
+	return tagged.Hash(forgeDomain, fields...)
+}
+
+// Ògún - Hardware & Manufacturing
+func (vm *VM) ogunForge() error {
+	ctx := vm.Context
+	stages, err := vm.forgeStages()
+	if err != nil {
+		return fmt.Errorf("ogun_forge: %w", err)
+	}
+
+	p := ctx.Ritual.Program
+	inputs, err := json.Marshal(p.Args)
+	if err != nil {
+		return fmt.Errorf("ogun_forge: encode inputs: %w", err)
+	}
+	artifact := artifactHash(ctx.Ritual.Name, p.Hash(), inputs, stages)
+	ctx.Variables["artifact"] = artifact
+	vm.emit(ArtifactForged{Artifact: artifact, Stages: stages})
+	return nil
+}
@@ -0,0 +1,95 @@
+// OSOVM Gas Metering
+// Every instruction, precompile and attribute hook draws from a
+// per-execution gas budget capped by the VM and lowered by @gas_limit
+
+package vm
+
+import "fmt"
+
+// DefaultGasLimit caps an execution when VM.GasLimit is zero
+const DefaultGasLimit int64 = 1_000_000
+
+// Gas costs. Simple stack and arithmetic instructions cost GasBase; the
+// rest are priced by the work behind them.
+const (
+	GasBase         int64 = 1
+	GasLoad         int64 = 2
+	GasStore        int64 = 3
+	GasBuiltin      int64 = 5 // plus GasPerArg per argument
+	GasPerArg       int64 = 1
+	GasCall         int64 = 10
+	GasRequireProof int64 = 100 // plus GasPerWitness per required witness
+	GasPerWitness   int64 = 20
+	GasSeal         int64 = 10
+	GasHook         int64 = 25 // per attribute hook run by SEAL
+	GasPrecompile   int64 = 50
+	GasReceipt      int64 = 20
+)
+
+// OutOfGasError - Execution needed more gas than its budget allowed
+type OutOfGasError struct {
+	Limit  int64
+	Used   int64  // gas consumed before the failing charge
+	Cost   int64  // the charge that did not fit
+	Action string // what was being charged, e.g. "PRECOMPILE" or "hook @deadline"
+}
+
+func (e *OutOfGasError) Error() string {
+	return fmt.Sprintf("out of gas: %s needs %d, %d of %d used", e.Action, e.Cost, e.Used, e.Limit)
+}
+
+// GasMeter - The gas budget of one execution
+type GasMeter struct {
+	Limit int64
+	Used  int64
+}
+
+// Consume charges cost for action, failing without charging if the budget
+// would be exceeded
+func (g *GasMeter) Consume(cost int64, action string) error {
+	if cost > g.Limit-g.Used {
+		return &OutOfGasError{Limit: g.Limit, Used: g.Used, Cost: cost, Action: action}
+	}
+	g.Used += cost
+	return nil
+}
+
+// Remaining returns the unspent budget
+func (g *GasMeter) Remaining() int64 {
+	return g.Limit - g.Used
+}
+
+// gasLimit is the VM's cap, lowered by the program's @gas_limit if smaller
+func (vm *VM) gasLimit(p *Program) int64 {
+	limit := vm.GasLimit
+	if limit <= 0 {
+		limit = DefaultGasLimit
+	}
+	if p.GasLimit > 0 && p.GasLimit < limit {
+		limit = p.GasLimit
+	}
+	return limit
+}
+
+// gasCost prices one instruction
+func gasCost(in Instr) int64 {
+	switch in.Op {
+	case OpLoad:
+		return GasLoad
+	case OpStore:
+		return GasStore
+	case OpBuiltin:
+		return GasBuiltin + GasPerArg*int64(in.B)
+	case OpCall:
+		return GasCall + GasPerArg*int64(in.B)
+	case OpRequireProof:
+		return GasRequireProof + GasPerWitness*int64(in.B)
+	case OpSeal:
+		return GasSeal
+	case OpPrecompile:
+		return GasPrecompile
+	case OpEmitReceipt:
+		return GasReceipt
+	}
+	return GasBase
+}
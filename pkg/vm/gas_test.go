@@ -0,0 +1,121 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestGasMeterChargesUpToTheLimit(t *testing.T) {
+	g := &GasMeter{Limit: 10}
+	if err := g.Consume(4, "a"); err != nil {
+		t.Fatal(err)
+	}
+	// A charge that exactly exhausts the budget fits
+	if err := g.Consume(6, "b"); err != nil || g.Remaining() != 0 {
+		t.Fatalf("exact fit: %v, %d left", err, g.Remaining())
+	}
+	var oog *OutOfGasError
+	if err := g.Consume(1, "c"); !errors.As(err, &oog) || *oog != (OutOfGasError{Limit: 10, Used: 10, Cost: 1, Action: "c"}) {
+		t.Fatalf("past the limit: %v", err)
+	}
+	if g.Used != 10 {
+		t.Errorf("a failed charge was applied: used %d", g.Used)
+	}
+}
+
+const gasRitual = `{
+	"name": "metered", "orisa": "eshu_router",
+	"attributes": {"deadline": {"unix": 1800000000}%s},
+	"statements": [
+		{"type": "assign", "data": {"variable": "x", "expr": "1 + 2"}},
+		{"type": "return", "data": {"expr": "x"}}
+	]
+}`
+
+// meteredCost is what gasRitual charges: SEAL and the @deadline hook it
+// runs, PRECOMPILE, then the body
+const meteredCost = GasSeal + GasHook + GasPrecompile +
+	3*GasBase + GasStore + // x = 1 + 2
+	GasLoad + GasReceipt + GasBase // return x
+
+func TestOutOfGasAtTheExactLimit(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	for _, tc := range []struct {
+		name     string
+		vmLimit  int64
+		attr     int64 // @gas_limit(max), 0 for none
+		limit    int64
+		outOfGas bool
+	}{
+		{"default", 0, 0, DefaultGasLimit, false},
+		{"exact @gas_limit", 0, meteredCost, meteredCost, false},
+		{"one short", 0, meteredCost - 1, meteredCost - 1, true},
+		{"exact VM cap", meteredCost, 0, meteredCost, false},
+		{"VM cap one short", meteredCost - 1, 0, meteredCost - 1, true},
+		// @gas_limit can lower the VM's cap but never raise it
+		{"@gas_limit above the cap", meteredCost - 1, 1_000, meteredCost - 1, true},
+	} {
+		vm := newTestVM(t, &now)
+		vm.GasLimit = tc.vmLimit
+		attr := ""
+		if tc.attr > 0 {
+			attr = fmt.Sprintf(`, "gas_limit": {"max": %d}`, tc.attr)
+		}
+		res, err := run(t, vm, fmt.Sprintf(gasRitual, attr))
+
+		if res.GasLimit != tc.limit {
+			t.Errorf("%s: limit %d, want %d", tc.name, res.GasLimit, tc.limit)
+		}
+		var oog *OutOfGasError
+		if !tc.outOfGas {
+			if err != nil || res.GasUsed != meteredCost {
+				t.Errorf("%s: used %d of %d: %v", tc.name, res.GasUsed, meteredCost, err)
+			}
+			continue
+		}
+		// The last instruction, RETURN, is the one that doesn't fit
+		if !errors.As(err, &oog) || oog.Action != "RETURN" || oog.Used != meteredCost-GasBase || oog.Cost != GasBase {
+			t.Errorf("%s: got %v", tc.name, err)
+		}
+	}
+}
+
+func TestHooksAndHostCallsAreCharged(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+
+	// Just enough for SEAL, not for the @deadline hook it runs
+	_, err := run(t, vm, fmt.Sprintf(gasRitual, fmt.Sprintf(`, "gas_limit": {"max": %d}`, GasSeal+GasHook-1)))
+	var oog *OutOfGasError
+	if !errors.As(err, &oog) || oog.Action != "hook @deadline" || oog.Used != GasSeal || oog.Cost != GasHook {
+		t.Errorf("hook: %v", err)
+	}
+
+	// A host function's own gas is charged after its CALL instruction
+	if err := vm.Hosts.Register(&HostFunc{
+		Name:   "ping",
+		Params: []HostParam{{Name: "msg", Type: TypeString}},
+		Result: TypeVoid,
+		Gas:    500,
+		Call:   func(*VM, []interface{}) (interface{}, error) { return nil, nil },
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ritual := `{
+		"name": "ping", "orisa": "eshu_router",
+		"attributes": {"gas_limit": {"max": %d}},
+		"statements": [{"type": "call", "data": {"function": "ping", "args": ["hi"]}}]
+	}`
+	const beforeHost = GasSeal + GasPrecompile + GasBase + GasCall + GasPerArg
+	_, err = run(t, vm, fmt.Sprintf(ritual, beforeHost+499))
+	if !errors.As(err, &oog) || oog.Action != "call ping" || oog.Used != beforeHost || oog.Cost != 500 {
+		t.Errorf("host call: %v", err)
+	}
+	res, err := run(t, vm, fmt.Sprintf(ritual, 10_000))
+	// then the call's null result is popped and the receipt emitted
+	if want := beforeHost + 500 + GasBase + GasBase + GasReceipt + GasBase; err != nil || res.GasUsed != want {
+		t.Errorf("host call: used %d, want %d: %v", res.GasUsed, want, err)
+	}
+}
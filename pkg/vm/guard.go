@@ -0,0 +1,363 @@
+// OSOVM Ọbàtálá Guard
+// The obatala_guard precompile: weighs @quorum, @consensus, @veto and
+// @mask against the counted witnesses and the governance state, and
+// allows or denies the ritual with a reason for every policy
+
+package vm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Governance - Decisions made outside the ritual that obatala_guard
+// weighs. Witnesses not listed in Dissent support the action they attested.
+type Governance struct {
+	Dissent map[string]string `json:"dissent,omitempty"` // witness device ID → why it objects
+	Vetoes  map[string]string `json:"vetoes,omitempty"`  // authority → why it vetoes
+}
+
+// GuardCheck - The verdict of one policy
+type GuardCheck struct {
+	Policy string `json:"policy"` // quorum, consensus, veto or mask
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason"`
+}
+
+// GuardDecision - What obatala_guard decided. Checks are always in the
+// order quorum, consensus, veto, mask, and only for attached policies.
+type GuardDecision struct {
+	Allow  bool         `json:"allow"`
+	Checks []GuardCheck `json:"checks"`
+	Masked []string     `json:"masked,omitempty"` // fields hidden from receipts and events
+}
+
+// Reasons returns the reasons of the failed checks
+func (d *GuardDecision) Reasons() []string {
+	var reasons []string
+	for _, c := range d.Checks {
+		if !c.Passed {
+			reasons = append(reasons, c.Policy+": "+c.Reason)
+		}
+	}
+	return reasons
+}
+
+func (d *GuardDecision) check(policy string, passed bool, format string, args ...interface{}) {
+	d.Checks = append(d.Checks, GuardCheck{Policy: policy, Passed: passed, Reason: fmt.Sprintf(format, args...)})
+	if !passed {
+		d.Allow = false
+	}
+}
+
+// GuardDeniedError - obatala_guard denied the ritual
+type GuardDeniedError struct {
+	Decision *GuardDecision
+}
+
+func (e *GuardDeniedError) Error() string {
+	return "obatala_guard denied: " + strings.Join(e.Decision.Reasons(), "; ")
+}
+
+// evaluateGuard decides whether the ritual may proceed. quorum is a floor
+// on top of @quorum: the larger of the two is required, so a caller can
+// raise the ritual's quorum but never lower it.
+func (vm *VM) evaluateGuard(quorum int) *GuardDecision {
+	ctx := vm.Context
+	attrs := ctx.Ritual.Attributes
+	d := &GuardDecision{Allow: true}
+	counted := len(ctx.Counted)
+
+	// 1. Quorum: enough witnesses were counted toward the Àṣẹ
+	if q, ok := attrs["quorum"].(*QuorumAttr); ok && q.Required > quorum {
+		quorum = q.Required
+	}
+	if quorum > 0 {
+		d.check("quorum", counted >= quorum, "%d witnesses counted, %d required", counted, quorum)
+	}
+
+	// 2. Consensus: enough of them support the action
+	if c, ok := attrs["consensus"].(*ConsensusAttr); ok {
+		var dissent []string
+		for _, w := range ctx.Counted {
+			if _, objects := vm.Governance.Dissent[w.DeviceID]; objects {
+				dissent = append(dissent, w.DeviceID)
+			}
+		}
+		if counted == 0 {
+			d.check("consensus", false, "no counted witnesses to reach %.0f%% consensus", c.Threshold*100)
+		} else {
+			support := float64(counted-len(dissent)) / float64(counted)
+			reason := fmt.Sprintf("%d of %d witnesses support (%.0f%%, threshold %.0f%%)",
+				counted-len(dissent), counted, support*100, c.Threshold*100)
+			if len(dissent) > 0 {
+				reason += "; dissent from " + strings.Join(dissent, ", ")
+			}
+			d.check("consensus", support >= c.Threshold, "%s", reason)
+		}
+	}
+
+	// 3. Veto: the named authority has not vetoed
+	if v, ok := attrs["veto"].(*VetoAttr); ok {
+		if why, vetoed := vm.Governance.Vetoes[v.Authority]; vetoed {
+			d.check("veto", false, "vetoed by %s: %s", v.Authority, why)
+		} else {
+			d.check("veto", true, "no veto from %s", v.Authority)
+		}
+	}
+
+	// 4. Mask: the fields to hide are named
+	if m, ok := attrs["mask"].(*MaskAttr); ok {
+		d.Masked = maskFields(m.Fields)
+		if len(d.Masked) == 0 {
+			d.check("mask", false, "names no fields to mask")
+		} else {
+			d.check("mask", true, "masking %s", strings.Join(d.Masked, ", "))
+		}
+	}
+	return d
+}
+
+// guard evaluates the guard, records its decision, and fails with a
+// *GuardDeniedError on deny
+func (vm *VM) guard(quorum int) error {
+	d := vm.evaluateGuard(quorum)
+	vm.Context.Guard = d
+	vm.emit(GuardDecided{Decision: d})
+	if !d.Allow {
+		return &GuardDeniedError{Decision: d}
+	}
+	return nil
+}
+
+// Ọbàtálá - Governance & Safety
+func (vm *VM) obatalaGuard() error {
+	return vm.guard(0)
+}
+
+// ========== Masking ==========
+
+// maskFields splits a @mask field list on commas and spaces
+func maskFields(fields string) []string {
+	return strings.FieldsFunc(fields, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// maskedFields returns the args and variables @mask hides in ritual. They
+// are hidden from the first event on, not just once the guard has run.
+func maskedFields(ritual *Ritual) map[string]bool {
+	masked := make(map[string]bool)
+	if m, ok := ritual.Attributes["mask"].(*MaskAttr); ok {
+		for _, field := range maskFields(m.Fields) {
+			masked[field] = true
+		}
+	}
+	return masked
+}
+
+// maskValue replaces a masked value with the SHA-256 of its JSON, so a
+// holder of the value can still check it
+func maskValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data = []byte(formatValue(v))
+	}
+	sum := sha256.Sum256(data)
+	return "masked:sha256:" + hex.EncodeToString(sum[:])
+}
+
+// minHiddenSubstring is the shortest hidden string redacted inside longer
+// strings. Shorter ones are redacted only where they are the whole value,
+// so a masked "7" doesn't rewrite every 7 in an error message.
+const minHiddenSubstring = 4
+
+// hide records the value of a masked arg or variable, so it is redacted
+// wherever else it turns up: in host call args, copies in other variables,
+// the return value and error messages
+func (ctx *Context) hide(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil || v == nil || v == "" {
+		return
+	}
+	if ctx.hidden == nil {
+		ctx.hidden = make(map[string]interface{})
+	}
+	if _, seen := ctx.hidden[string(data)]; seen {
+		return
+	}
+	ctx.hidden[string(data)] = v
+	if s, ok := v.(string); ok && len(s) >= minHiddenSubstring {
+		ctx.replacer = nil
+	}
+}
+
+// redact returns v with every masked field and hidden value replaced by
+// its hash. Lists and maps are copied, never changed in place; hidden
+// strings are also replaced inside longer strings.
+func (ctx *Context) redact(v interface{}) interface{} {
+	if len(ctx.hidden) == 0 && len(ctx.Masked) == 0 {
+		return v
+	}
+	switch v := v.(type) {
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = ctx.redact(e)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			if ctx.Masked[k] {
+				out[k] = maskValue(e)
+			} else {
+				out[k] = ctx.redact(e)
+			}
+		}
+		return out
+	case []string:
+		out := make([]string, len(v))
+		for i, e := range v {
+			out[i] = ctx.redactString(e)
+		}
+		return out
+	case string:
+		return ctx.redactString(v)
+	}
+	if data, err := json.Marshal(v); err == nil {
+		if _, hidden := ctx.hidden[string(data)]; hidden {
+			return maskValue(v)
+		}
+	}
+	return v
+}
+
+// redactString replaces s by its hash if it is a hidden value, and
+// otherwise every hidden string of at least minHiddenSubstring bytes
+// inside it. The replacement is one pass trying the longest values first,
+// so a value inside another never splits it and no hash is rewritten.
+func (ctx *Context) redactString(s string) string {
+	if len(ctx.hidden) == 0 {
+		return s
+	}
+	if data, err := json.Marshal(s); err == nil {
+		if _, hidden := ctx.hidden[string(data)]; hidden {
+			return maskValue(s).(string)
+		}
+	}
+	if ctx.replacer == nil {
+		var values []string
+		for _, v := range ctx.hidden {
+			if h, ok := v.(string); ok && len(h) >= minHiddenSubstring {
+				values = append(values, h)
+			}
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if len(values[i]) != len(values[j]) {
+				return len(values[i]) > len(values[j])
+			}
+			return values[i] < values[j]
+		})
+		pairs := make([]string, 0, 2*len(values))
+		for _, h := range values {
+			pairs = append(pairs, h, maskValue(h).(string))
+		}
+		ctx.replacer = strings.NewReplacer(pairs...)
+	}
+	return ctx.replacer.Replace(s)
+}
+
+// redactEvent returns a copy of data with every string, value, list and
+// map in it redacted, down through pointers, structs and slices such as
+// GuardDecided's decision. emit passes every event through it, so no sink
+// and no result sees a masked value.
+func (ctx *Context) redactEvent(data EventData) EventData {
+	if len(ctx.hidden) == 0 && len(ctx.Masked) == 0 {
+		return data
+	}
+	if r, ok := ctx.redactValue(reflect.ValueOf(data)).Interface().(EventData); ok {
+		return r
+	}
+	return data
+}
+
+// redactValue returns a redacted copy of v; nothing v refers to is changed
+func (ctx *Context) redactValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		r := reflect.New(v.Type()).Elem()
+		r.SetString(ctx.redactString(v.String()))
+		return r
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		r := reflect.New(v.Type().Elem())
+		r.Elem().Set(ctx.redactValue(v.Elem()))
+		return r
+	case reflect.Struct:
+		r := reflect.New(v.Type()).Elem()
+		r.Set(v)
+		for i := 0; i < r.NumField(); i++ {
+			if f := r.Field(i); f.CanSet() {
+				f.Set(ctx.redactValue(f))
+			}
+		}
+		return r
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		r := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			r.Index(i).Set(ctx.redactValue(v.Index(i)))
+		}
+		return r
+	case reflect.Interface, reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		if redacted := reflect.ValueOf(ctx.redact(v.Interface())); redacted.Type().AssignableTo(v.Type()) {
+			r := reflect.New(v.Type()).Elem()
+			r.Set(redacted)
+			return r
+		}
+	}
+	return v
+}
+
+// redactGuard returns a redacted copy of obatala_guard's decision, nil if
+// the guard hasn't run
+func (ctx *Context) redactGuard() *GuardDecision {
+	if ctx.Guard == nil || (len(ctx.hidden) == 0 && len(ctx.Masked) == 0) {
+		return ctx.Guard
+	}
+	return ctx.redactValue(reflect.ValueOf(ctx.Guard)).Interface().(*GuardDecision)
+}
+
+// RedactedError - An execution error whose message has had masked values
+// replaced by their hashes. Unwrap still reaches the original error for
+// callers that check its type.
+type RedactedError struct {
+	Message string
+	Err     error
+}
+
+func (e *RedactedError) Error() string { return e.Message }
+func (e *RedactedError) Unwrap() error { return e.Err }
+
+// redactError returns err, wrapped in a RedactedError if its message
+// names a hidden value
+func (ctx *Context) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if msg := ctx.redactString(err.Error()); msg != err.Error() {
+		return &RedactedError{Message: msg, Err: err}
+	}
+	return err
+}
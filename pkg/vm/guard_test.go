@@ -0,0 +1,207 @@
+package vm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const secret = "s3cr3t-pin-4242"
+
+// scanForSecret fails if secret appears in any event, in the result or in
+// anything written to the sinks
+func scanForSecret(t *testing.T, res *ExecutionResult, sinks ...*bytes.Buffer) {
+	t.Helper()
+	for _, e := range res.Events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), secret) {
+			t.Errorf("event %d leaks the masked value: %s", e.Seq, data)
+		}
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Errorf("result leaks the masked value: %s", data)
+	}
+	for i, sink := range sinks {
+		if strings.Contains(sink.String(), secret) {
+			t.Errorf("sink %d leaks the masked value:\n%s", i, sink)
+		}
+	}
+}
+
+func TestMaskedValuesNeverLeave(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	var console, lines bytes.Buffer
+	vm.Sinks = []EventSink{NewConsoleSink(&console), NewJSONLinesSink(&lines)}
+
+	// The pin is passed to a host call and copied before the guard runs,
+	// then returned
+	res, err := proved(t, vm, "drone_001", `{
+		"name": "masked", "orisa": "eshu_router",
+		"ase": {"proof": "telemetry", "witnesses": 1},
+		"attributes": {"mask": {"fields": "pin"}},
+		"args": {"pin": "`+secret+`", "note": "hello"},
+		"statements": [
+			{"type": "assign", "data": {"variable": "cached", "expr": "cache_get(pin)"}},
+			{"type": "assign", "data": {"variable": "copy", "expr": "pin"}},
+			{"type": "call", "data": {"function": "obatala_guard"}},
+			{"type": "return", "data": {"expr": "copy"}}
+		]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanForSecret(t, res, &console, &lines)
+	if res.Return != maskValue(secret) || res.Variables["copy"] != maskValue(secret) {
+		t.Errorf("returned %v, copy %v", res.Return, res.Variables["copy"])
+	}
+	if called := eventOf(res, EventHostCalled).(HostCalled); called.Args[0] != maskValue(secret) {
+		t.Errorf("host call args %v", called.Args)
+	}
+	var inputs map[string]interface{}
+	if err := json.Unmarshal(res.Sealed.Inputs, &inputs); err != nil || inputs["pin"] != maskValue(secret) || inputs["note"] != "hello" {
+		t.Errorf("sealed inputs %s", res.Sealed.Inputs)
+	}
+
+	// Nor does it leak through an error naming it
+	console.Reset()
+	lines.Reset()
+	res, err = proved(t, vm, "drone_001", `{
+		"name": "masked_from", "orisa": "eshu_router",
+		"ase": {"proof": "telemetry", "witnesses": 1},
+		"attributes": {"mask": {"fields": "from"}},
+		"args": {"amount": 5, "from": "`+secret+`"}
+	}`)
+	var redacted *RedactedError
+	if err == nil || strings.Contains(err.Error(), secret) || !strings.Contains(err.Error(), maskValue(secret).(string)) {
+		t.Fatalf("got %v", err)
+	}
+	if !errors.As(err, &redacted) || !strings.Contains(redacted.Err.Error(), secret) {
+		t.Errorf("the cause isn't kept: %#v", err)
+	}
+	scanForSecret(t, res, &console, &lines)
+	if res.Error != err.Error() {
+		t.Errorf("result error %q, returned %q", res.Error, err)
+	}
+
+	// Nor through obatala_guard's decision, in its event or in the result
+	console.Reset()
+	lines.Reset()
+	vm.Governance.Vetoes = map[string]string{"council": "the pin " + secret + " is known"}
+	res, err = proved(t, vm, "drone_001", `{
+		"name": "masked_veto", "orisa": "obatala_guard",
+		"ase": {"proof": "telemetry", "witnesses": 1},
+		"attributes": {"mask": {"fields": "pin"}, "veto": {"authority": "council"}},
+		"args": {"pin": "`+secret+`"}
+	}`)
+	var denied *GuardDeniedError
+	if !errors.As(err, &denied) {
+		t.Fatalf("got %v", err)
+	}
+	scanForSecret(t, res, &console, &lines)
+	decided := eventOf(res, EventGuardDecided).(GuardDecided)
+	if want := "vetoed by council: the pin " + maskValue(secret).(string) + " is known"; decided.Decision.Checks[0].Reason != want || res.Guard.Checks[0].Reason != want {
+		t.Errorf("guard reasons %q, %q", decided.Decision.Checks[0].Reason, res.Guard.Checks[0].Reason)
+	}
+	if !strings.Contains(vm.Context.Guard.Checks[0].Reason, secret) {
+		t.Error("redaction changed the guard's own decision")
+	}
+}
+
+func TestRedactString(t *testing.T) {
+	ctx := &Context{}
+	for _, v := range []interface{}{"abc", "abcdef", "abcdefgh", "7", int64(42)} {
+		ctx.hide(v)
+	}
+	hash := func(v interface{}) string { return maskValue(v).(string) }
+
+	for _, tc := range []struct {
+		in, want string
+	}{
+		// The longest value wins wherever values overlap, every time
+		{"key abcdefgh", "key " + hash("abcdefgh")},
+		{"abcdefgh abcdef", hash("abcdefgh") + " " + hash("abcdef")},
+		{"abcdefabcdefgh", hash("abcdef") + hash("abcdefgh")},
+		// Short values are redacted only where they are the whole string
+		{"abc", hash("abc")},
+		{"7", hash("7")},
+		{"abc 7 route 77", "abc 7 route 77"},
+		{"42", "42"},
+	} {
+		for i := 0; i < 20; i++ {
+			if got := ctx.redactString(tc.in); got != tc.want {
+				t.Fatalf("redactString(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		}
+	}
+	if got := ctx.redact(int64(42)); got != hash(int64(42)) {
+		t.Errorf("redact(42) = %v", got)
+	}
+
+	// A value hidden later is redacted from then on
+	ctx.hide("route")
+	if got := ctx.redactString("abc 7 route 77"); got != "abc 7 "+hash("route")+" 77" {
+		t.Errorf("after hiding route: %q", got)
+	}
+}
+
+func TestGuardPolicyRanges(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	for _, tc := range []struct {
+		attrs string
+		err   string
+	}{
+		{`"quorum": {"required": -1}`, "quorum must require at least one witness (got -1)"},
+		{`"quorum": {"required": 0}`, "quorum must require at least one witness (got 0)"},
+		{`"consensus": {"threshold": 0}`, "consensus threshold must be in (0, 1] (got 0)"},
+		{`"consensus": {"threshold": 1.5}`, "consensus threshold must be in (0, 1]"},
+		{`"veto": {}`, "veto needs an authority"},
+	} {
+		_, err := run(t, newTestVM(t, &now), `{"name": "g", "orisa": "obatala_guard", "attributes": {`+tc.attrs+`}}`)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", tc.attrs, err, tc.err)
+		}
+	}
+}
+
+func TestGuardCallCannotLowerTheQuorum(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	guard := func(name, quorum string, args string) (*ExecutionResult, error) {
+		return proved(t, vm, "drone_001", `{
+			"name": "`+name+`", "orisa": "eshu_router",
+			"ase": {"proof": "telemetry", "witnesses": 2},
+			"attributes": {"quorum": {"required": `+quorum+`}},
+			"statements": [{"type": "call", "data": {"function": "obatala_guard", "args": [`+args+`]}}]
+		}`)
+	}
+
+	// Two witnesses are counted
+	for _, tc := range []struct {
+		quorum, args string
+		err          string
+	}{
+		{"2", "", ""},
+		{"3", "", "2 witnesses counted, 3 required"},
+		{"3", "1", "2 witnesses counted, 3 required"},
+		{"2", "3", "2 witnesses counted, 3 required"},
+		{"2", "0", "quorum must require at least one witness (got 0)"},
+		{"2", "-4", "quorum must require at least one witness (got -4)"},
+	} {
+		_, err := guard("g"+tc.quorum+tc.args, tc.quorum, tc.args)
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("@quorum(%s), obatala_guard(%s): got %v, want %q", tc.quorum, tc.args, err, tc.err)
+		}
+	}
+}
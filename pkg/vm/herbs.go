@@ -0,0 +1,182 @@
+// OSOVM Òsanyìn Herb Registry
+// The osanyin_herb precompile: looks the ritual's @herb up in the herb
+// registry and checks its class, potency and @regional availability.
+// check_herb_interactions checks herbs given together against the
+// interactions the registry records.
+
+package vm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Herb - A registry entry
+type Herb struct {
+	Key          string            `json:"key"`
+	Name         string            `json:"name"`    // botanical name
+	Class        string            `json:"class"`   // e.g. ONCO, FIRE
+	Regions      []string          `json:"regions"` // country codes ("DO") or full locales ("DO-Santiago")
+	MaxPotency   float64           `json:"max_potency,omitempty"`
+	Interactions map[string]string `json:"interactions,omitempty"` // herb key → how the two interact
+}
+
+// AvailableIn reports whether the herb is available in locale, either
+// by the full locale or by its country code
+func (h *Herb) AvailableIn(locale string) bool {
+	country, _, _ := strings.Cut(locale, "-")
+	for _, r := range h.Regions {
+		if strings.EqualFold(r, locale) || strings.EqualFold(r, country) {
+			return true
+		}
+	}
+	return false
+}
+
+// InteractsWith returns how the herb and other interact, as recorded by
+// either of them
+func (h *Herb) InteractsWith(other *Herb) (string, bool) {
+	if how, ok := h.Interactions[other.Key]; ok {
+		return how, true
+	}
+	how, ok := other.Interactions[h.Key]
+	return how, ok
+}
+
+// HerbRegistry - Herbs by key
+type HerbRegistry map[string]Herb
+
+// NewHerbRegistry indexes herbs by key
+func NewHerbRegistry(herbs ...Herb) (HerbRegistry, error) {
+	r := make(HerbRegistry, len(herbs))
+	for _, h := range herbs {
+		if h.Key == "" {
+			return nil, fmt.Errorf("herb %q has no key", h.Name)
+		}
+		if _, dup := r[h.Key]; dup {
+			return nil, fmt.Errorf("herb %s listed twice", h.Key)
+		}
+		r[h.Key] = h
+	}
+	for _, h := range herbs {
+		for other := range h.Interactions {
+			if _, ok := r[other]; !ok {
+				return nil, fmt.Errorf("herb %s interacts with %s, which is not in the registry", h.Key, other)
+			}
+		}
+	}
+	return r, nil
+}
+
+// Lookup finds a herb by key, or by botanical name ignoring case. Names
+// are tried in key order, so a name two herbs share always finds the same
+// one.
+func (r HerbRegistry) Lookup(keyOrName string) (*Herb, bool) {
+	if h, ok := r[keyOrName]; ok {
+		return &h, true
+	}
+	for _, key := range r.Keys() {
+		if h := r[key]; strings.EqualFold(h.Name, keyOrName) {
+			return &h, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns every herb key, sorted
+func (r HerbRegistry) Keys() []string {
+	keys := make([]string, 0, len(r))
+	for key := range r {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DefaultHerbs is used by NewVM
+var DefaultHerbs = HerbRegistry{
+	"cerasee":     {Key: "cerasee", Name: "Momordica charantia", Class: "ONCO", Regions: []string{"DO", "HT", "JM", "TT", "NG"}, Interactions: map[string]string{"moringa": "both lower blood sugar"}},
+	"soursop":     {Key: "soursop", Name: "Annona muricata", Class: "ONCO", Regions: []string{"DO", "JM", "BR", "NG"}},
+	"bitter_leaf": {Key: "bitter_leaf", Name: "Vernonia amygdalina", Class: "ONCO", Regions: []string{"NG", "GH", "CM"}},
+	"ginger":      {Key: "ginger", Name: "Zingiber officinale", Class: "FIRE", Regions: []string{"NG", "JM", "IN", "DO"}},
+	"moringa":     {Key: "moringa", Name: "Moringa oleifera", Class: "VITAL", Regions: []string{"NG", "GH", "HT", "IN"}},
+}
+
+// Òsanyìn - Herbs & Healing
+func (vm *VM) osanyinHerb() error {
+	attrs := vm.Context.Ritual.Attributes
+	want, ok := attrs["herb"].(*HerbAttr)
+	if !ok || (want.Key == "" && want.Name == "") {
+		return fmt.Errorf("osanyin_herb needs @herb(key, class) or @herb(name, potency)")
+	}
+	query := want.Key
+	if query == "" {
+		query = want.Name
+	}
+
+	herb, ok := vm.Herbs.Lookup(query)
+	if !ok {
+		return fmt.Errorf("osanyin_herb: %s is not in the herb registry", query)
+	}
+	if want.Class != "" && !strings.EqualFold(want.Class, herb.Class) {
+		return fmt.Errorf("osanyin_herb: %s is class %s, not %s", herb.Key, herb.Class, want.Class)
+	}
+	if want.Potency < 0 || (herb.MaxPotency > 0 && want.Potency > herb.MaxPotency) {
+		return fmt.Errorf("osanyin_herb: potency %g of %s is outside 0-%g", want.Potency, herb.Key, herb.MaxPotency)
+	}
+	prescribed := HerbPrescribed{Key: herb.Key, Name: herb.Name, Class: herb.Class}
+	if regional, ok := attrs["regional"].(*RegionalAttr); ok && regional.Locale != "" {
+		if !herb.AvailableIn(regional.Locale) {
+			return fmt.Errorf("osanyin_herb: %s is not available in %s (grows in %s)",
+				herb.Key, regional.Locale, strings.Join(herb.Regions, ", "))
+		}
+		prescribed.Locale = regional.Locale
+	}
+
+	vm.Context.Variables["herb"] = herb.Key
+	vm.emit(prescribed)
+	return nil
+}
+
+// checkHerbInteractions checks the ritual's @herb and the herbs in with,
+// a comma-separated list of keys or names, for interactions between any
+// two of them
+func (vm *VM) checkHerbInteractions(with string) error {
+	var queries []string
+	if want, ok := vm.Context.Ritual.Attributes["herb"].(*HerbAttr); ok {
+		if want.Key != "" {
+			queries = append(queries, want.Key)
+		} else if want.Name != "" {
+			queries = append(queries, want.Name)
+		}
+	}
+	for _, q := range strings.Split(with, ",") {
+		if q = strings.TrimSpace(q); q != "" {
+			queries = append(queries, q)
+		}
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no herbs to check: the ritual has no @herb and none were given")
+	}
+
+	herbs := make([]*Herb, 0, len(queries))
+	checked := HerbsChecked{}
+	for _, q := range queries {
+		herb, ok := vm.Herbs.Lookup(q)
+		if !ok {
+			return fmt.Errorf("%s is not in the herb registry", q)
+		}
+		herbs = append(herbs, herb)
+		checked.Herbs = append(checked.Herbs, herb.Key)
+	}
+	for i, a := range herbs {
+		for _, b := range herbs[i+1:] {
+			if how, ok := a.InteractsWith(b); ok {
+				return fmt.Errorf("%s interacts with %s: %s", a.Key, b.Key, how)
+			}
+		}
+	}
+	vm.emit(checked)
+	return nil
+}
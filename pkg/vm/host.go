@@ -0,0 +1,424 @@
+// OSOVM Host Functions
+// Registry of Go functions rituals invoke with "call" statements or from
+// expressions. Calls are resolved and literal arguments type-checked when
+// a ritual loads; the stack machine checks every call again at run time.
+
+package vm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+	"github.com/ase-lang/osovm/pkg/diag"
+)
+
+// HostType - The type of a host function parameter or result
+type HostType int
+
+const (
+	TypeVoid   HostType = iota // no result
+	TypeAny                    // any value, null included
+	TypeString                 // string
+	TypeInt                    // integer
+	TypeNumber                 // integer or float
+	TypeBool                   // boolean
+	TypeList                   // list
+	TypeObject                 // object
+)
+
+var hostTypeNames = map[HostType]string{
+	TypeVoid:   "void",
+	TypeAny:    "any",
+	TypeString: "string",
+	TypeInt:    "int",
+	TypeNumber: "number",
+	TypeBool:   "bool",
+	TypeList:   "list",
+	TypeObject: "object",
+}
+
+func (t HostType) String() string {
+	return hostTypeNames[t]
+}
+
+// Accepts reports whether v is a value of type t
+func (t HostType) Accepts(v interface{}) bool {
+	switch t {
+	case TypeAny:
+		return true
+	case TypeString:
+		_, ok := v.(string)
+		return ok
+	case TypeInt:
+		_, ok := v.(int64)
+		return ok
+	case TypeNumber:
+		_, ok := toFloat(v)
+		return ok
+	case TypeBool:
+		_, ok := v.(bool)
+		return ok
+	case TypeList:
+		_, ok := v.([]interface{})
+		return ok
+	case TypeObject:
+		_, ok := v.(map[string]interface{})
+		return ok
+	}
+	return v == nil
+}
+
+// HostParam - One parameter. Optional parameters come last and receive
+// null when omitted.
+type HostParam struct {
+	Name     string
+	Type     HostType
+	Optional bool
+}
+
+// HostFunc - A Go function callable from rituals
+type HostFunc struct {
+	Name   string
+	Params []HostParam
+	Result HostType
+	Gas    int64 // charged on top of the CALL instruction
+	Call   func(vm *VM, args []interface{}) (interface{}, error)
+}
+
+// Signature renders the function as `name(a string, b int?) -> bool`
+func (f *HostFunc) Signature() string {
+	params := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		params[i] = p.Name + " " + p.Type.String()
+		if p.Optional {
+			params[i] += "?"
+		}
+	}
+	return fmt.Sprintf("%s(%s) -> %s", f.Name, strings.Join(params, ", "), f.Result)
+}
+
+// hasParam reports whether the function declares a parameter called name
+func (f *HostFunc) hasParam(name string) bool {
+	for _, p := range f.Params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// required returns the number of parameters that must be passed
+func (f *HostFunc) required() int {
+	n := 0
+	for _, p := range f.Params {
+		if !p.Optional {
+			n++
+		}
+	}
+	return n
+}
+
+// checkArgs checks positional arguments against the signature
+func (f *HostFunc) checkArgs(args []interface{}) error {
+	if len(args) < f.required() || len(args) > len(f.Params) {
+		return f.arityError(len(args))
+	}
+	for i, v := range args {
+		p := f.Params[i]
+		if v == nil && p.Optional {
+			continue
+		}
+		if !p.Type.Accepts(v) {
+			return fmt.Errorf("%s: argument %s must be %s, got %s", f.Name, p.Name, p.Type, typeName(v))
+		}
+	}
+	return nil
+}
+
+func (f *HostFunc) arityError(n int) error {
+	if f.required() == len(f.Params) {
+		return fmt.Errorf("%s takes %d argument(s), got %d", f.Name, len(f.Params), n)
+	}
+	return fmt.Errorf("%s takes %d to %d arguments, got %d", f.Name, f.required(), len(f.Params), n)
+}
+
+// invoke checks the arguments, calls the function and checks its result
+func (f *HostFunc) invoke(vm *VM, args []interface{}) (interface{}, error) {
+	if err := f.checkArgs(args); err != nil {
+		return nil, err
+	}
+	result, err := f.Call(vm, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	if f.Result == TypeVoid {
+		return nil, nil
+	}
+	if !f.Result.Accepts(result) {
+		return nil, fmt.Errorf("%s returned %s, declared %s", f.Name, typeName(result), f.Result)
+	}
+	return result, nil
+}
+
+// ========== Registry ==========
+
+var hostNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// HostRegistry - Host functions by name
+type HostRegistry struct {
+	funcs map[string]*HostFunc
+}
+
+// NewHostRegistry returns a registry holding the standard host library
+func NewHostRegistry() *HostRegistry {
+	r := &HostRegistry{funcs: make(map[string]*HostFunc)}
+	for _, f := range hostLibrary {
+		if err := r.Register(f); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Register adds a host function. Names must be identifiers not taken by
+// a builtin or another host function, and optional parameters must come
+// after required ones.
+func (r *HostRegistry) Register(f *HostFunc) error {
+	switch {
+	case !hostNamePattern.MatchString(f.Name):
+		return fmt.Errorf("host function name %q is not an identifier", f.Name)
+	case f.Call == nil:
+		return fmt.Errorf("host function %s has no implementation", f.Name)
+	case f.Gas < 0:
+		return fmt.Errorf("host function %s has negative gas", f.Name)
+	}
+	if _, ok := builtins[f.Name]; ok {
+		return fmt.Errorf("host function %s shadows a builtin", f.Name)
+	}
+	if _, dup := r.funcs[f.Name]; dup {
+		return fmt.Errorf("host function %s registered twice", f.Name)
+	}
+	optional := false
+	for _, p := range f.Params {
+		if p.Type == TypeVoid {
+			return fmt.Errorf("host function %s: parameter %s cannot be void", f.Name, p.Name)
+		}
+		if optional && !p.Optional {
+			return fmt.Errorf("host function %s: required parameter %s follows an optional one", f.Name, p.Name)
+		}
+		optional = p.Optional
+	}
+	r.funcs[f.Name] = f
+	return nil
+}
+
+// Lookup returns the function registered under name
+func (r *HostRegistry) Lookup(name string) (*HostFunc, bool) {
+	f, ok := r.funcs[name]
+	return f, ok
+}
+
+// Names returns every registered name, sorted
+func (r *HostRegistry) Names() []string {
+	names := make([]string, 0, len(r.funcs))
+	for name := range r.funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ========== Load-Time Resolution ==========
+
+// resolveCalls resolves every call in a ritual body against the builtins
+// and host functions. Call statement arguments are reordered to match the
+// signature, omitted optional ones before the last given becoming null.
+func resolveCalls(body []ast.Stmt, hosts *HostRegistry, diags *diag.List) {
+	for _, stmt := range body {
+		switch s := stmt.(type) {
+		case *ast.Call:
+			resolveCallStmt(s, hosts, diags)
+		case *ast.Assign:
+			resolveExpr(s.Value, hosts, diags)
+		case *ast.Return:
+			if s.Value != nil {
+				resolveExpr(s.Value, hosts, diags)
+			}
+		case *ast.If:
+			resolveExpr(s.Cond, hosts, diags)
+			resolveCalls(s.Then, hosts, diags)
+			resolveCalls(s.Else, hosts, diags)
+		case *ast.Loop:
+			if s.Count != nil {
+				resolveExpr(s.Count, hosts, diags)
+			}
+			if s.Cond != nil {
+				resolveExpr(s.Cond, hosts, diags)
+			}
+			resolveCalls(s.Body, hosts, diags)
+		}
+	}
+}
+
+func resolveCallStmt(s *ast.Call, hosts *HostRegistry, diags *diag.List) {
+	f, ok := hosts.Lookup(s.Func.Name)
+	if !ok {
+		if _, builtin := builtins[s.Func.Name]; builtin {
+			diags.Errorf(s.Func.Span, "%s is a builtin; call it from an expression", s.Func.Name)
+		} else {
+			diags.Errorf(s.Func.Span, "unknown function %q", s.Func.Name)
+		}
+		return
+	}
+	if s.Result.Name != "" && f.Result == TypeVoid {
+		diags.Errorf(s.Result.Span, "%s returns no result to assign", f.Name)
+	}
+	for _, a := range s.Args {
+		if name, value, ok := legacyArg(f, a); ok {
+			diags.Warnf(a.Value.Span, "%q is a deprecated legacy argument; pass %s by name", a.Value.Str, name.Name)
+			a.Name, a.Value = name, value
+		}
+	}
+
+	// Bind positional arguments, then named ones
+	bound := make([]*ast.Arg, len(f.Params))
+	failed := false
+	for i, a := range s.Args {
+		index := i
+		if a.Name.Name != "" {
+			index = -1
+			for j, p := range f.Params {
+				if p.Name == a.Name.Name {
+					index = j
+				}
+			}
+			if index < 0 {
+				diags.Errorf(a.Name.Span, "%s has no parameter %q (signature %s)", f.Name, a.Name.Name, f.Signature())
+				failed = true
+				continue
+			}
+		}
+		if index >= len(f.Params) {
+			diags.Errorf(a.Span, "too many arguments: %s", f.Signature())
+			failed = true
+			continue
+		}
+		if bound[index] != nil {
+			diags.Errorf(a.Span, "argument %s of %s given twice", f.Params[index].Name, f.Name)
+			failed = true
+			continue
+		}
+		p := f.Params[index]
+		if v := a.Value.Interface(); !(v == nil && p.Optional) && !p.Type.Accepts(v) {
+			diags.Errorf(a.Value.Span, "argument %s of %s must be %s, got %s", p.Name, f.Name, p.Type, typeName(v))
+			failed = true
+		}
+		bound[index] = a
+	}
+
+	// Fill omitted optional arguments up to the last one given
+	n := 0
+	for i, p := range f.Params {
+		if bound[i] != nil {
+			n = i + 1
+		} else if !p.Optional {
+			diags.Errorf(s.Span, "missing argument %s: %s", p.Name, f.Signature())
+			failed = true
+		}
+	}
+	if failed {
+		return
+	}
+	for i := range bound[:n] {
+		if bound[i] == nil {
+			bound[i] = &ast.Arg{Span: s.Span, Value: &ast.Value{Span: s.Span, Kind: ast.ValueNull}}
+		}
+	}
+	s.Args = bound[:n]
+}
+
+// legacyArg recognizes the legacy "name:value" string form of a named
+// argument, such as "quorum:3", when name is one of f's parameters. The
+// value is typed like a literal: integer, float, boolean, else a string.
+func legacyArg(f *HostFunc, a *ast.Arg) (ast.Ident, *ast.Value, bool) {
+	if a.Name.Name != "" || a.Value.Kind != ast.ValueString {
+		return ast.Ident{}, nil, false
+	}
+	name, raw, found := strings.Cut(a.Value.Str, ":")
+	if !found || !f.hasParam(name) {
+		return ast.Ident{}, nil, false
+	}
+
+	value := &ast.Value{Span: a.Value.Span, Kind: ast.ValueString, Str: raw}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		value.Kind, value.Int = ast.ValueInt, n
+	} else if x, err := strconv.ParseFloat(raw, 64); err == nil {
+		value.Kind, value.Float = ast.ValueFloat, x
+	} else if b, err := strconv.ParseBool(raw); err == nil {
+		value.Kind, value.Bool = ast.ValueBool, b
+	}
+	return ast.Ident{Span: a.Value.Span, Name: name}, value, true
+}
+
+func resolveExpr(expr ast.Expr, hosts *HostRegistry, diags *diag.List) {
+	switch e := expr.(type) {
+	case *ast.Unary:
+		resolveExpr(e.X, hosts, diags)
+	case *ast.Binary:
+		resolveExpr(e.X, hosts, diags)
+		resolveExpr(e.Y, hosts, diags)
+	case *ast.FuncCall:
+		for _, a := range e.Args {
+			resolveExpr(a, hosts, diags)
+		}
+		if fn, ok := builtins[e.Func.Name]; ok {
+			if err := fn.checkArity(len(e.Args)); err != nil {
+				diags.Errorf(e.Span, "%s %v", e.Func.Name, err)
+			}
+			return
+		}
+		f, ok := hosts.Lookup(e.Func.Name)
+		if !ok {
+			diags.Errorf(e.Func.Span, "unknown function %q", e.Func.Name)
+			return
+		}
+		if f.Result == TypeVoid {
+			diags.Errorf(e.Span, "%s returns no result to use in an expression", f.Name)
+		}
+		if len(e.Args) < f.required() || len(e.Args) > len(f.Params) {
+			diags.Errorf(e.Span, "%v", f.arityError(len(e.Args)))
+			return
+		}
+		for i, a := range e.Args {
+			if lit, ok := a.(*ast.Literal); ok {
+				p := f.Params[i]
+				if v := lit.Value.Interface(); !(v == nil && p.Optional) && !p.Type.Accepts(v) {
+					diags.Errorf(lit.Span, "argument %s of %s must be %s, got %s", p.Name, f.Name, p.Type, typeName(v))
+				}
+			}
+		}
+	}
+}
+
+// resolveProgram checks that every CALL in a loaded program names a
+// registered host function with an acceptable argument count
+func resolveProgram(p *Program, hosts *HostRegistry) error {
+	for pc, in := range p.Code {
+		if in.Op != OpCall {
+			continue
+		}
+		name := p.Constants[in.A].(string)
+		f, ok := hosts.Lookup(name)
+		if !ok {
+			return fmt.Errorf("instruction %d: unknown function %q", pc, name)
+		}
+		if in.B < f.required() || in.B > len(f.Params) {
+			return fmt.Errorf("instruction %d: %v", pc, f.arityError(in.B))
+		}
+	}
+	return nil
+}
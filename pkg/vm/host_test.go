@@ -0,0 +1,145 @@
+package vm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+	"github.com/ase-lang/osovm/pkg/witness"
+)
+
+func TestRegisterRejects(t *testing.T) {
+	noop := func(*VM, []interface{}) (interface{}, error) { return nil, nil }
+	r := NewHostRegistry()
+	if err := r.Register(&HostFunc{Name: "ping", Call: noop}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		f   *HostFunc
+		err string
+	}{
+		{&HostFunc{Name: "ping", Call: noop}, "host function ping registered twice"},
+		{&HostFunc{Name: "obatala_guard", Call: noop}, "host function obatala_guard registered twice"},
+		{&HostFunc{Name: "len", Call: noop}, "host function len shadows a builtin"},
+		{&HostFunc{Name: "two words", Call: noop}, `host function name "two words" is not an identifier`},
+		{&HostFunc{Name: "idle"}, "host function idle has no implementation"},
+		{&HostFunc{Name: "free", Gas: -1, Call: noop}, "host function free has negative gas"},
+		{&HostFunc{Name: "v", Params: []HostParam{{Name: "x", Type: TypeVoid}}, Call: noop}, "parameter x cannot be void"},
+		{&HostFunc{Name: "o", Params: []HostParam{{Name: "a", Type: TypeInt, Optional: true}, {Name: "b", Type: TypeInt}}, Call: noop}, "required parameter b follows an optional one"},
+	} {
+		if err := r.Register(tc.f); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", tc.f.Name, err, tc.err)
+		}
+	}
+
+	// A rejected registration leaves the first one in place
+	if f, _ := r.Lookup("ping"); f.Gas != 0 || len(r.Names()) != len(hostLibrary)+1 {
+		t.Errorf("registry changed: %v", r.Names())
+	}
+}
+
+func TestLegacyNamedArgumentsStillBind(t *testing.T) {
+	vm := NewVM()
+	vm.Sinks = nil
+	var warnings bytes.Buffer
+	vm.Warnings = &warnings
+	if err := vm.LoadSource("legacy.oso", []byte(`{
+		"name": "legacy", "orisa": "obatala_guard",
+		"statements": [
+			{"type": "call", "data": {"function": "obatala_guard", "args": ["quorum:3"]}},
+			{"type": "call", "data": {"function": "scan_qr", "args": ["quorum:3"]}}
+		]
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	body := vm.Rituals["legacy"].Body
+	guard, scan := body[0].(*ast.Call), body[1].(*ast.Call)
+	if a := guard.Args[0]; a.Name.Name != "quorum" || a.Value.Kind != ast.ValueInt || a.Value.Int != 3 {
+		t.Errorf("obatala_guard arg: %s=%s", a.Name.Name, a.Value)
+	}
+	// scan_qr has no quorum parameter, so its string is just a string
+	if a := scan.Args[0]; a.Name.Name != "" || a.Value.Str != "quorum:3" {
+		t.Errorf("scan_qr arg: %s=%s", a.Name.Name, a.Value)
+	}
+	if got := warnings.String(); !strings.HasPrefix(got, `legacy.oso:4:68: warning: "quorum:3" is a deprecated legacy argument; pass quorum by name`) || strings.Count(got, "warning") != 1 {
+		t.Errorf("warnings:\n%s", got)
+	}
+}
+
+func TestWitnessHostFunctionsUseCountedWitnesses(t *testing.T) {
+	vm := NewVM()
+	vm.Sinks = nil
+	if err := vm.LoadSource("review.oso", []byte(`{
+		"name": "review", "orisa": "eshu_router",
+		"ase": {"proof": "telemetry", "witnesses": 2},
+		"attributes": {"review": {"witness": 3}},
+		"statements": [
+			{"type": "call", "data": {"function": "witness_review_approval"}},
+			{"type": "return", "data": {"expr": "broadcast_to_witnesses()"}}
+		]
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	mesh := NewLocalMesh(vm.Witnesses, witness.NetworkLoRa)
+	_, newProof := quorumVM(t)
+
+	// Three attestations reach the VM, but one is forged
+	proof := newProof()
+	witnesses, err := mesh.Broadcast("review", proof, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witnesses[2].Signature = witnesses[0].Signature
+	if _, err := vm.Execute("review", proof, witnesses); err == nil || !strings.Contains(err.Error(), "review needs 3 witnesses, 2 counted") {
+		t.Errorf("forged witness approved the review: %v", err)
+	}
+
+	proof = newProof()
+	if witnesses, err = mesh.Broadcast("review", proof, 3); err != nil {
+		t.Fatal(err)
+	}
+	res, err := vm.Execute("review", proof, append(witnesses, witnesses[0]))
+	if err != nil || res.Return != int64(3) {
+		t.Errorf("returned %v: %v", res.Return, err)
+	}
+}
+
+func TestReviewGathersWitnessesWithoutAProof(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	ritual := `{
+		"name": "prescribe", "orisa": "eshu_router",
+		"attributes": {"review": {"witness": 2}},
+		"statements": [{"type": "call", "data": {"function": "witness_review_approval"}}]
+	}`
+	if _, err := run(t, vm, ritual); err == nil || !strings.Contains(err.Error(), "review needs 2 witnesses, 0 counted and no witness mesh") {
+		t.Errorf("approved without witnesses: %v", err)
+	}
+
+	// Over a mesh the reviewers attest the review itself, and are verified
+	vm.Mesh = NewLocalMesh(vm.Witnesses, witness.NetworkLoRa)
+	res, err := run(t, vm, ritual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approved := eventOf(res, EventReviewApproved).(ReviewApproved)
+	if approved.Counted != 2 || approved.Gathered != 2 || len(approved.Devices) != 2 || !isValidHash(approved.Subject) {
+		t.Errorf("approved %+v", approved)
+	}
+	// They attest the review, not an Àṣẹ proof, so the receipt names none
+	if len(res.Witnesses) != 0 || len(res.Sealed.Witnesses) != 0 {
+		t.Errorf("reviewers counted as Àṣẹ witnesses: %v", res.Witnesses)
+	}
+
+	// Reviewers from a revoked key don't count
+	if _, err := vm.Witnesses.Revoke(approved.Devices[0], "lost"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(t, vm, ritual); err == nil || !strings.Contains(err.Error(), "review needs 2 witnesses, 1 counted") {
+		t.Errorf("revoked reviewer counted: %v", err)
+	}
+}
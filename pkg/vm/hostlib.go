@@ -0,0 +1,235 @@
+// OSOVM Host Library
+// Standard host functions available to every ritual. Functions that drive
+// hardware or external services are Phase 1 stubs.
+
+package vm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+)
+
+var hostLibrary = []*HostFunc{
+	// ========== Proof & Witnesses ==========
+	{
+		Name:   "scan_qr",
+		Params: []HostParam{{Name: "code", Type: TypeString}},
+		Result: TypeString,
+		Gas:    20,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			sum := sha256.Sum256([]byte(args[0].(string)))
+			return hex.EncodeToString(sum[:]), nil
+		},
+	},
+	{
+		Name:   "scan_qr_checkpoint",
+		Result: TypeString,
+		Gas:    20,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			if vm.Context.Proof == nil {
+				return nil, fmt.Errorf("no proof was captured")
+			}
+			return vm.Context.Proof.Receipt, nil
+		},
+	},
+	{
+		Name:   "broadcast_to_witnesses",
+		Result: TypeInt,
+		Gas:    20,
+		Call:   broadcastWitnesses,
+	},
+	{
+		Name:   "broadcast_to_witness_mesh",
+		Result: TypeInt,
+		Gas:    20,
+		Call:   broadcastWitnesses,
+	},
+	{
+		Name:   "witness_review_approval",
+		Result: TypeBool,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			if err := vm.approveReview(); err != nil {
+				return nil, err
+			}
+			return true, nil
+		},
+	},
+	{
+		Name:   "obatala_guard",
+		Params: []HostParam{{Name: "quorum", Type: TypeInt, Optional: true}},
+		Result: TypeBool,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			// The quorum arg can only raise @quorum; with neither, one
+			// witness is required
+			quorum := 1
+			if len(args) > 0 && args[0] != nil {
+				n := args[0].(int64)
+				if n < 1 || n > math.MaxInt32 {
+					return nil, fmt.Errorf("quorum must require at least one witness (got %d)", n)
+				}
+				quorum = int(n)
+			}
+			if err := vm.guard(quorum); err != nil {
+				return nil, err
+			}
+			return true, nil
+		},
+	},
+
+	// ========== Delivery & Offerings ==========
+	{
+		Name: "complete_delivery",
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			vm.Context.Variables["delivery_state"] = "delivered"
+			return nil, nil
+		},
+	},
+	{
+		Name:   "validate_shrine_presence",
+		Result: TypeString,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			offering, ok := vm.Context.Ritual.Attributes["offering"].(*OfferingAttr)
+			if !ok || offering.Shrine == "" {
+				return nil, fmt.Errorf("ritual has no @offering shrine")
+			}
+			return offering.Shrine, nil
+		},
+	},
+	{
+		Name:   "distribute_50_25_15_10",
+		Params: []HostParam{{Name: "amount", Type: TypeInt, Optional: true}},
+		Result: TypeList,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			var amount int64
+			if len(args) > 0 && args[0] != nil {
+				amount = args[0].(int64)
+			} else if offering, ok := vm.Context.Ritual.Attributes["offering"].(*OfferingAttr); ok {
+				amount = int64(offering.Amount)
+			} else {
+				return nil, fmt.Errorf("no amount given and ritual has no @offering")
+			}
+			if amount < 0 {
+				return nil, fmt.Errorf("amount cannot be negative (%d)", amount)
+			}
+			split := shrineSplit(amount)
+			return []interface{}{split[0], split[1], split[2], split[3]}, nil
+		},
+	},
+	{
+		Name:   "validate_regional_availability",
+		Result: TypeString,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			regional, ok := vm.Context.Ritual.Attributes["regional"].(*RegionalAttr)
+			if !ok || regional.Locale == "" {
+				return nil, fmt.Errorf("ritual has no @regional locale")
+			}
+			return regional.Locale, nil
+		},
+	},
+
+	// ========== Vault ==========
+	{
+		Name: "vault_hold",
+		Params: []HostParam{
+			{Name: "amount", Type: TypeInt},
+			{Name: "account", Type: TypeString, Optional: true},
+		},
+		Result: TypeInt,
+		Gas:    20,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			var account string
+			if len(args) > 1 && args[1] != nil {
+				account = args[1].(string)
+			}
+			id, err := vm.holdFunds(args[0].(int64), account)
+			return int64(id), err
+		},
+	},
+	{
+		Name:   "vault_release",
+		Params: []HostParam{{Name: "hold", Type: TypeInt}},
+		Gas:    20,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			id := args[0].(int64)
+			if id <= 0 {
+				return nil, fmt.Errorf("no hold %d", id)
+			}
+			return nil, vm.releaseFunds(uint64(id))
+		},
+	},
+
+	// ========== Cache ==========
+	{
+		Name:   "cache_get",
+		Params: []HostParam{{Name: "key", Type: TypeString}},
+		Result: TypeAny,
+		Gas:    10,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			return vm.cacheGet(args[0].(string)), nil
+		},
+	},
+	{
+		Name: "cache_put",
+		Params: []HostParam{
+			{Name: "key", Type: TypeString},
+			{Name: "value", Type: TypeAny},
+			{Name: "ttl", Type: TypeInt, Optional: true},
+		},
+		Gas: 20,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			// Without a ttl arg the ritual's @cache ttl applies
+			ttl, ok := vm.cacheTTL()
+			if len(args) > 2 && args[2] != nil {
+				ttl, ok = time.Duration(args[2].(int64))*time.Second, true
+			}
+			if !ok {
+				return nil, fmt.Errorf("no ttl given and the ritual has no @cache(ttl)")
+			}
+			return nil, vm.cachePut(args[0].(string), args[1], ttl)
+		},
+	},
+
+	// ========== Herbs ==========
+	{
+		Name:   "check_herb_interactions",
+		Params: []HostParam{{Name: "with", Type: TypeString, Optional: true}},
+		Result: TypeBool,
+		Gas:    10,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			var with string
+			if len(args) > 0 && args[0] != nil {
+				with = args[0].(string)
+			}
+			if err := vm.checkHerbInteractions(with); err != nil {
+				return nil, err
+			}
+			return true, nil
+		},
+	},
+
+	// ========== Phase 1 Stubs ==========
+	hostStub("fund_orisa_robots_100_percent"),
+	hostStub("initialize_simulation"),
+	hostStub("run_navigation_episode"),
+	hostStub("collect_telemetry"),
+	hostStub("calculate_golden_ratio_dosage"),
+}
+
+// broadcastWitnesses returns the number of witnesses Àṣẹ validation
+// counted for the proof; submitted attestations that failed it don't count
+func broadcastWitnesses(vm *VM, args []interface{}) (interface{}, error) {
+	return int64(len(vm.Context.Counted)), nil
+}
+
+// hostStub - A void function with no effect beyond its host.called event
+func hostStub(name string) *HostFunc {
+	return &HostFunc{
+		Name: name,
+		Call: func(vm *VM, args []interface{}) (interface{}, error) {
+			return nil, nil
+		},
+	}
+}
@@ -0,0 +1,67 @@
+// OSOVM Lowering
+// Converts parsed modules (pkg/ast) into runtime Rituals
+
+package vm
+
+import (
+	"github.com/ase-lang/osovm/pkg/ast"
+	"github.com/ase-lang/osovm/pkg/bind"
+	"github.com/ase-lang/osovm/pkg/diag"
+)
+
+// lowerModule converts every ritual in a module, resolving calls against
+// hosts and reporting semantic errors to diags
+func lowerModule(mod *ast.Module, hosts *HostRegistry, diags *diag.List) []*Ritual {
+	seen := make(map[string]ast.Span)
+	var rituals []*Ritual
+	for _, r := range mod.Rituals {
+		if prev, dup := seen[r.Name.Name]; dup {
+			diags.Errorf(r.Name.Span, "ritual %q already declared at %s", r.Name.Name, prev.Start)
+			continue
+		}
+		seen[r.Name.Name] = r.Name.Span
+		rituals = append(rituals, lowerRitual(r, hosts, diags))
+	}
+	return rituals
+}
+
+func lowerRitual(r *ast.Ritual, hosts *HostRegistry, diags *diag.List) *Ritual {
+	ritual := &Ritual{
+		Name:      r.Name.Name,
+		Orisa:     r.Orisa.Name,
+		Args:      make(map[string]string, len(r.Args)),
+		ArgValues: make(map[string]interface{}, len(r.Args)),
+		Body:      r.Body,
+	}
+
+	ritual.Attributes = lowerAttributes(r.Attributes, diags)
+	if u, ok := ritual.Attributes["àṣẹ"].(*UniversalAse); ok {
+		ritual.Ase = &AseAttr{ProofType: ProofType(u.ProofType), Witnesses: u.Witnesses}
+	}
+
+	for _, a := range r.Args {
+		ritual.Args[a.Name.Name] = a.Value.String()
+		ritual.ArgValues[a.Name.Name] = a.Value.Interface()
+	}
+
+	resolveCalls(r.Body, hosts, diags)
+	return ritual
+}
+
+// lowerAttributes decodes each attribute into its typed struct. Unknown
+// attribute names and parameters that don't fit the struct are errors.
+func lowerAttributes(attrs []*ast.Attribute, diags *diag.List) map[string]Validator {
+	decoded := make(map[string]Validator, len(attrs))
+	for _, attr := range attrs {
+		spec, known := attributeRegistry.Lookup(attr.Name.Name)
+		if !known {
+			diags.Errorf(attr.Name.Span, "unknown attribute %q", attr.Name.Name)
+			continue
+		}
+		value := spec.New()
+		if bind.Attribute(attr, value, diags) {
+			decoded[attr.Name.Name] = value
+		}
+	}
+	return decoded
+}
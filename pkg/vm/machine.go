@@ -0,0 +1,343 @@
+// OSOVM Stack Machine
+// Executes compiled ritual programs deterministically: no host clock,
+// randomness or map iteration order reaches a ritual's result
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ase-lang/osovm/internal/tagged"
+	"github.com/ase-lang/osovm/pkg/ast"
+)
+
+// executionDomain tags the hash EMIT_RECEIPT commits to, so an execution
+// receipt never equals any other hash of the same program
+const executionDomain = "osovm/execution-commitment/v1"
+
+// DefaultMaxLoopIterations bounds every loop when VM.MaxLoopIterations is zero
+const DefaultMaxLoopIterations = 10000
+
+// maxStackDepth bounds the operand stack of malformed programs
+const maxStackDepth = 1024
+
+// RuntimeError - An instruction that failed while executing, with the
+// source span it was compiled from when known
+type RuntimeError struct {
+	Span ast.Span
+	PC   int
+	Err  error
+}
+
+func (e *RuntimeError) Error() string {
+	if e.Span.Start.IsValid() {
+		return fmt.Sprintf("%s: %v", e.Span.Start, e.Err)
+	}
+	return fmt.Sprintf("pc %04d: %v", e.PC, e.Err)
+}
+
+func (e *RuntimeError) Unwrap() error {
+	return e.Err
+}
+
+// stageError marks a failed engine stage (Àṣẹ, seal, precompile), which
+// keeps its own message instead of becoming a RuntimeError
+type stageError struct {
+	err error
+}
+
+func (e *stageError) Error() string { return e.err.Error() }
+func (e *stageError) Unwrap() error { return e.err }
+
+func (vm *VM) maxLoopIterations() int64 {
+	if vm.MaxLoopIterations > 0 {
+		return vm.MaxLoopIterations
+	}
+	return DefaultMaxLoopIterations
+}
+
+// ========== Machine ==========
+
+type machine struct {
+	vm     *VM
+	prog   *Program
+	stack  []interface{}
+	locals []interface{}
+}
+
+func (m *machine) push(v interface{}) error {
+	if len(m.stack) >= maxStackDepth {
+		return fmt.Errorf("stack overflow")
+	}
+	m.stack = append(m.stack, v)
+	return nil
+}
+
+func (m *machine) pop() (interface{}, error) {
+	if len(m.stack) == 0 {
+		return nil, fmt.Errorf("stack underflow")
+	}
+	v := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	return v, nil
+}
+
+func (m *machine) popN(n int) ([]interface{}, error) {
+	if n > len(m.stack) {
+		return nil, fmt.Errorf("stack underflow")
+	}
+	args := make([]interface{}, n)
+	copy(args, m.stack[len(m.stack)-n:])
+	m.stack = m.stack[:len(m.stack)-n]
+	return args, nil
+}
+
+func (m *machine) name(i int) string {
+	s, _ := m.prog.Constants[i].(string)
+	return s
+}
+
+// run executes a program against the VM's context and returns the
+// ritual's result
+func (vm *VM) run(p *Program) (interface{}, error) {
+	m := &machine{vm: vm, prog: p, locals: make([]interface{}, p.Locals)}
+	vm.Context.Gas = &GasMeter{Limit: vm.gasLimit(p)}
+	for pc := 0; pc < len(p.Code); {
+		in := p.Code[pc]
+		err := vm.Context.Gas.Consume(gasCost(in), in.Op.String())
+		next, done := pc, false
+		if err == nil {
+			next, done, err = m.step(pc, in)
+		}
+		if err != nil {
+			var oog *OutOfGasError
+			if errors.As(err, &oog) {
+				return nil, fmt.Errorf("❌ Execution aborted at pc %04d: %w", pc, oog)
+			}
+			if stage, ok := err.(*stageError); ok {
+				return nil, stage.err
+			}
+			return nil, fmt.Errorf("❌ Statement execution failed: %w", &RuntimeError{Span: p.span(pc), PC: pc, Err: err})
+		}
+		if done {
+			if vm.Context.Receipt == "" {
+				return nil, fmt.Errorf("❌ Statement execution failed: program returned without emitting a receipt")
+			}
+			return vm.Context.Return, nil
+		}
+		pc = next
+	}
+	return nil, fmt.Errorf("❌ Statement execution failed: program ended without RETURN")
+}
+
+// step executes one instruction and returns the next pc
+func (m *machine) step(pc int, in Instr) (next int, done bool, err error) {
+	vm, ctx := m.vm, m.vm.Context
+	next = pc + 1
+
+	switch in.Op {
+	case OpConst:
+		err = m.push(m.prog.Constants[in.A])
+
+	case OpLoad:
+		name := m.name(in.A)
+		if v, ok := ctx.Variables[name]; ok {
+			err = m.push(v)
+		} else if v, ok := m.prog.Args[name]; ok {
+			err = m.push(v)
+		} else {
+			err = fmt.Errorf("undefined variable %q", name)
+		}
+
+	case OpStore:
+		var v interface{}
+		if v, err = m.pop(); err == nil {
+			name := m.name(in.A)
+			ctx.Variables[name] = v
+			if ctx.Masked[name] {
+				ctx.hide(v)
+				vm.emit(VariableSet{Name: name, Value: maskValue(v), Masked: true})
+			} else {
+				vm.emit(VariableSet{Name: name, Value: v})
+			}
+		}
+
+	case OpLoadLocal:
+		err = m.push(m.locals[in.A])
+
+	case OpStoreLocal:
+		m.locals[in.A], err = m.pop()
+
+	case OpPop:
+		_, err = m.pop()
+
+	case OpDup:
+		if len(m.stack) == 0 {
+			return 0, false, fmt.Errorf("stack underflow")
+		}
+		err = m.push(m.stack[len(m.stack)-1])
+
+	case OpNeg, OpNot:
+		var x interface{}
+		if x, err = m.pop(); err == nil {
+			op := "-"
+			if in.Op == OpNot {
+				op = "!"
+			}
+			if x, err = unaryOp(op, x); err == nil {
+				err = m.push(x)
+			}
+		}
+
+	case OpAdd, OpSub, OpMul, OpDiv, OpMod, OpEq, OpNe, OpLt, OpLe, OpGt, OpGe:
+		var xy []interface{}
+		if xy, err = m.popN(2); err == nil {
+			var v interface{}
+			if v, err = binaryOp(binaryOpNames[in.Op], xy[0], xy[1]); err == nil {
+				err = m.push(v)
+			}
+		}
+
+	case OpBool:
+		if len(m.stack) == 0 {
+			return 0, false, fmt.Errorf("stack underflow")
+		}
+		if v := m.stack[len(m.stack)-1]; !isBool(v) {
+			err = fmt.Errorf("condition must be a boolean, got %s", typeName(v))
+		}
+
+	case OpJump:
+		next = in.A
+
+	case OpJumpIfFalse, OpJumpIfTrue:
+		var v interface{}
+		if v, err = m.pop(); err == nil {
+			b, ok := v.(bool)
+			if !ok {
+				return 0, false, fmt.Errorf("condition must be a boolean, got %s", typeName(v))
+			}
+			if b == (in.Op == OpJumpIfTrue) {
+				next = in.A
+			}
+		}
+
+	case OpCount:
+		if len(m.stack) == 0 {
+			return 0, false, fmt.Errorf("stack underflow")
+		}
+		v := m.stack[len(m.stack)-1]
+		count, ok := v.(int64)
+		switch limit := vm.maxLoopIterations(); {
+		case !ok:
+			err = fmt.Errorf("repeat count must be an integer, got %s", typeName(v))
+		case count < 0:
+			err = fmt.Errorf("repeat count cannot be negative (%d)", count)
+		case count > limit:
+			err = fmt.Errorf("repeat count %d exceeds the loop limit of %d", count, limit)
+		}
+
+	case OpLoopGuard:
+		max := m.prog.Constants[in.B].(int64)
+		if limit := vm.maxLoopIterations(); max <= 0 || max > limit {
+			max = limit
+		}
+		n, _ := m.locals[in.A].(int64)
+		if n >= max {
+			return 0, false, fmt.Errorf("while loop did not finish within %d iterations", max)
+		}
+		m.locals[in.A] = n + 1
+
+	case OpBuiltin:
+		name := m.name(in.A)
+		fn, ok := builtins[name]
+		if !ok {
+			return 0, false, fmt.Errorf("unknown function %q", name)
+		}
+		if err = fn.checkArity(in.B); err != nil {
+			return 0, false, fmt.Errorf("%s %w", name, err)
+		}
+		var args []interface{}
+		if args, err = m.popN(in.B); err == nil {
+			var v interface{}
+			if v, err = fn.Call(args); err != nil {
+				return 0, false, fmt.Errorf("%s: %w", name, err)
+			}
+			err = m.push(v)
+		}
+
+	case OpCall:
+		name := m.name(in.A)
+		f, ok := vm.Hosts.Lookup(name)
+		if !ok {
+			return 0, false, fmt.Errorf("unknown function %q", name)
+		}
+		if err = ctx.Gas.Consume(f.Gas, "call "+name); err != nil {
+			return 0, false, err
+		}
+		var args []interface{}
+		if args, err = m.popN(in.B); err == nil {
+			vm.emit(HostCalled{Function: name, Args: args})
+			var v interface{}
+			if v, err = f.invoke(vm, args); err == nil {
+				err = m.push(v)
+			}
+		}
+
+	case OpRequireProof:
+		ase := &AseAttr{ProofType: ProofType(m.name(in.A)), Witnesses: in.B}
+		if err := vm.validateAse(ase); err != nil {
+			return 0, false, &stageError{fmt.Errorf("❌ Àṣẹ validation failed: %w", err)}
+		}
+		vm.emit(AseSealed{})
+
+	case OpSeal:
+		if err := vm.runCapabilities(true); err != nil {
+			return 0, false, &stageError{fmt.Errorf("❌ Capability failed: %w", err)}
+		}
+		if err := vm.runAttributeHooks(); err != nil {
+			return 0, false, &stageError{fmt.Errorf("❌ Attribute check failed: %w", err)}
+		}
+
+	case OpPrecompile:
+		if err := vm.executeOrisa(m.name(in.A)); err != nil {
+			return 0, false, &stageError{fmt.Errorf("❌ Òrìṣà execution failed: %w", err)}
+		}
+
+	case OpEmitReceipt:
+		if len(m.stack) == 0 {
+			return 0, false, fmt.Errorf("stack underflow")
+		}
+		ctx.Receipt = m.receipt(m.stack[len(m.stack)-1])
+
+	case OpReturn:
+		var v interface{}
+		if v, err = m.pop(); err == nil {
+			ctx.Return = v
+			if in.A == 1 {
+				vm.emit(ValueReturned{Value: v})
+			}
+			done = true
+		}
+
+	default:
+		err = fmt.Errorf("unknown opcode %d", byte(in.Op))
+	}
+	return next, done, err
+}
+
+func isBool(v interface{}) bool {
+	_, ok := v.(bool)
+	return ok
+}
+
+// receipt commits to the program, the proof it was sealed with and the
+// result: the tagged hash of the program hash, proof receipt and encoded
+// result
+func (m *machine) receipt(result interface{}) string {
+	var proofReceipt string
+	if proof := m.vm.Context.Proof; proof != nil {
+		proofReceipt = proof.Receipt
+	}
+	return tagged.Hash(executionDomain, m.prog.Hash(), proofReceipt, string(encodeValue(result)))
+}
@@ -0,0 +1,66 @@
+// OSOVM Witness Mesh
+// Gathers witness attestations for proofs the engine captures itself
+
+package vm
+
+import (
+	"fmt"
+
+	"github.com/ase-lang/osovm/pkg/witness"
+)
+
+// WitnessMesh broadcasts a freshly captured proof and returns the witnesses
+// that echoed it. Used when a capability produces the proof during Execute.
+type WitnessMesh interface {
+	Broadcast(ritual string, proof *Proof, count int) ([]Witness, error)
+}
+
+// LocalMesh - Witness nodes simulated in-process. Nodes are created on first
+// use, enrolled in Registry, and reused across broadcasts.
+type LocalMesh struct {
+	Registry   *witness.Registry
+	Network    string
+	DeviceType string
+	nodes      []*witness.Node
+}
+
+// NewLocalMesh creates a mesh of phone witnesses on network
+func NewLocalMesh(registry *witness.Registry, network string) *LocalMesh {
+	return &LocalMesh{Registry: registry, Network: network, DeviceType: "phone"}
+}
+
+func (m *LocalMesh) Broadcast(ritual string, proof *Proof, count int) ([]Witness, error) {
+	for len(m.nodes) < count {
+		id := fmt.Sprintf("witness_%s_%d", m.Network, len(m.nodes)+1)
+		node, err := witness.CreateNode(id, m.DeviceType, m.Network)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := m.Registry.EnrollNode(node); err != nil {
+			return nil, err
+		}
+		m.nodes = append(m.nodes, node)
+	}
+
+	witnesses := make([]Witness, 0, count)
+	for _, node := range m.nodes[:count] {
+		att := witness.Attestation{
+			Receipt:   proof.Receipt,
+			Device:    proof.DeviceID,
+			Ritual:    ritual,
+			Timestamp: m.Registry.Now().Unix(),
+			Network:   node.Network,
+		}
+		signature, err := node.Sign(att)
+		if err != nil {
+			return nil, err
+		}
+		witnesses = append(witnesses, Witness{
+			DeviceID:  node.DeviceID,
+			Signature: signature,
+			Timestamp: att.Timestamp,
+			Network:   att.Network,
+		})
+	}
+	return witnesses, nil
+}
@@ -0,0 +1,99 @@
+// OSOVM Ọrúnmìlà Oracle
+// The orunmila_oracle precompile: asks the VM's oracle the ritual's
+// @oracle query, or casts a deterministic Ifá odù for @divination(seed)
+// when the oracle has no answer
+
+package vm
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ase-lang/osovm/internal/tagged"
+)
+
+// OracleAnswer - An answer to a query. Confidence is between 0 and 1.
+type OracleAnswer struct {
+	Value      interface{} `json:"value"`
+	Source     string      `json:"source,omitempty"`
+	Confidence float64     `json:"confidence"`
+}
+
+// Oracle answers queries. A nil answer means the oracle does not know.
+type Oracle interface {
+	Ask(query string) (*OracleAnswer, error)
+}
+
+// OracleTable - An oracle that answers from a fixed table
+type OracleTable map[string]OracleAnswer
+
+func (t OracleTable) Ask(query string) (*OracleAnswer, error) {
+	a, ok := t[query]
+	if !ok {
+		return nil, nil
+	}
+	if a.Source == "" {
+		a.Source = "table"
+	}
+	return &a, nil
+}
+
+// ========== Divination ==========
+
+// divinationDomain tags the hash an odù is cast from
+const divinationDomain = "osovm/ifa-divination/v1"
+
+// principalOdu are the sixteen principal odù, in their traditional order
+var principalOdu = [16]string{
+	"Ogbe", "Oyeku", "Iwori", "Odi", "Irosun", "Owonrin", "Obara", "Okanran",
+	"Ogunda", "Osa", "Ika", "Oturupon", "Otura", "Irete", "Ose", "Ofun",
+}
+
+// castOdu names one of the 256 odù for seed and query: the hash of both
+// picks the right and left legs. Equal legs are the odù's Meji.
+func castOdu(seed int64, query string) string {
+	sum := tagged.Sum(divinationDomain, strconv.FormatInt(seed, 10), query)
+	right, left := principalOdu[sum[0]%16], principalOdu[sum[1]%16]
+	if right == left {
+		return right + " Meji"
+	}
+	return right + " " + left
+}
+
+// Ọrúnmìlà - Oracle & Divination
+func (vm *VM) orunmilaOracle() error {
+	attrs := vm.Context.Ritual.Attributes
+	oracle, ok := attrs["oracle"].(*OracleAttr)
+	if !ok || oracle.Query == "" {
+		return fmt.Errorf("orunmila_oracle needs an @oracle(query)")
+	}
+
+	// 1. The VM's oracle answers first
+	var answer *OracleAnswer
+	if vm.Oracle != nil {
+		a, err := vm.Oracle.Ask(oracle.Query)
+		if err != nil {
+			return fmt.Errorf("orunmila_oracle: %w", err)
+		}
+		answer = a
+	}
+
+	// 2. Otherwise Ifá is cast, which carries no confidence
+	if answer == nil {
+		divination, ok := attrs["divination"].(*DivinationAttr)
+		if !ok {
+			return fmt.Errorf("orunmila_oracle: no oracle answers %q; add @divination(seed) to cast Ifá instead", oracle.Query)
+		}
+		answer = &OracleAnswer{Value: castOdu(divination.Seed, oracle.Query), Source: "ifa"}
+	}
+
+	// 3. @prediction sets the confidence the answer must reach
+	if prediction, ok := attrs["prediction"].(*PredictionAttr); ok && answer.Confidence < prediction.Confidence {
+		return fmt.Errorf("orunmila_oracle: %s answered %q with confidence %g, below the %g @prediction requires",
+			answer.Source, oracle.Query, answer.Confidence, prediction.Confidence)
+	}
+
+	vm.Context.Variables["oracle"] = answer.Value
+	vm.emit(OracleAnswered{Query: oracle.Query, Value: answer.Value, Source: answer.Source, Confidence: answer.Confidence})
+	return nil
+}
@@ -0,0 +1,140 @@
+// OSOVM Ọ̀yá Witness Orchestrator
+// The oya_witness precompile: makes sure @witness(count, network) witnesses
+// are counted, gathering the missing ones from the witness mesh. @review
+// approvals gather their witnesses the same way.
+
+package vm
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ase-lang/osovm/internal/tagged"
+)
+
+// onNetwork returns the witnesses on network; every witness when it is empty
+func onNetwork(witnesses []Witness, network string) []Witness {
+	if network == "" {
+		return witnesses
+	}
+	var matched []Witness
+	for _, w := range witnesses {
+		if w.Network == network {
+			matched = append(matched, w)
+		}
+	}
+	return matched
+}
+
+// Ọ̀yá - Witness Network
+func (vm *VM) oyaWitness() error {
+	ctx := vm.Context
+	want, ok := ctx.Ritual.Attributes["witness"].(*WitnessAttr)
+	if !ok || want.Count <= 0 {
+		return fmt.Errorf("oya_witness needs @witness(count) witnesses to orchestrate")
+	}
+	if ctx.Proof == nil {
+		return fmt.Errorf("oya_witness needs an Àṣẹ proof for witnesses to attest")
+	}
+	orchestrated := WitnessesOrchestrated{Network: want.Network, Required: want.Count}
+
+	// 1. Broadcast for the witnesses that are missing. Nodes that already
+	// attested echo again and are discarded as duplicates when counted.
+	had := len(onNetwork(ctx.Counted, want.Network))
+	if missing := want.Count - had; missing > 0 {
+		if vm.Mesh == nil {
+			return fmt.Errorf("oya_witness: %d of %d witnesses counted and no witness mesh to gather more", want.Count-missing, want.Count)
+		}
+		if err := ctx.Gas.Consume(GasPerWitness*int64(want.Count), "oya_witness broadcast"); err != nil {
+			return err
+		}
+		echoed, err := vm.Mesh.Broadcast(ctx.Ritual.Name, ctx.Proof, len(ctx.Counted)+missing)
+		if err != nil {
+			return fmt.Errorf("oya_witness: %w", err)
+		}
+		before := len(ctx.Counted)
+		report := vm.countWitnesses(ctx.Proof, append(append([]Witness(nil), ctx.Counted...), echoed...), want.Count)
+		ctx.Counted = report.Counted
+		ctx.Witnesses = append(ctx.Witnesses, ctx.Counted[before:]...)
+	}
+
+	// 2. Enough of them must be on the requested network
+	counted := onNetwork(ctx.Counted, want.Network)
+	orchestrated.Counted, orchestrated.Gathered = len(counted), len(counted)-had
+	for _, w := range counted {
+		orchestrated.Devices = append(orchestrated.Devices, w.DeviceID)
+	}
+	vm.emit(orchestrated)
+	if len(counted) < want.Count {
+		where := ""
+		if want.Network != "" {
+			where = " on " + want.Network
+		}
+		return fmt.Errorf("oya_witness: %d of %d witnesses counted%s", len(counted), want.Count, where)
+	}
+	return nil
+}
+
+// ========== Reviews ==========
+
+// reviewDomain tags the receipts reviewers attest for rituals without an
+// Àṣẹ proof, so one can't stand in for a real proof receipt
+const reviewDomain = "osovm/review/v1"
+
+// reviewSubject is what @review witnesses attest: the Àṣẹ proof, or for a
+// ritual without one, a receipt over the program and the time of review
+func (vm *VM) reviewSubject() *Proof {
+	ctx := vm.Context
+	if ctx.Proof != nil {
+		return ctx.Proof
+	}
+	now := vm.Now().Unix()
+	receipt := tagged.Hash(reviewDomain, ctx.Ritual.Name, ctx.Ritual.Program.Hash(), strconv.FormatInt(now, 10))
+	subject := &Proof{Receipt: receipt, Timestamp: now}
+	if vm.Device != nil {
+		subject.DeviceID = vm.Device.ID
+	}
+	return subject
+}
+
+// approveReview makes sure @review(witness) witnesses approve the ritual.
+// The witnesses counted toward the Àṣẹ approve it first; the rest are
+// gathered from the mesh, and only attestations that verify count.
+func (vm *VM) approveReview() error {
+	ctx := vm.Context
+	review, ok := ctx.Ritual.Attributes["review"].(*ReviewAttr)
+	if !ok {
+		return fmt.Errorf("ritual has no @review attribute")
+	}
+	subject := vm.reviewSubject()
+	approved := ReviewApproved{Subject: subject.Receipt, Required: review.Witness}
+
+	var counted []Witness
+	if ctx.Proof != nil {
+		counted = ctx.Counted
+	}
+	had := len(counted)
+	if missing := review.Witness - had; missing > 0 {
+		if vm.Mesh == nil {
+			return fmt.Errorf("review needs %d witnesses, %d counted and no witness mesh to gather more", review.Witness, had)
+		}
+		if err := ctx.Gas.Consume(GasPerWitness*int64(review.Witness), "review broadcast"); err != nil {
+			return err
+		}
+		echoed, err := vm.Mesh.Broadcast(ctx.Ritual.Name, subject, had+missing)
+		if err != nil {
+			return fmt.Errorf("review: %w", err)
+		}
+		counted = vm.countWitnesses(subject, append(append([]Witness(nil), counted...), echoed...), review.Witness).Counted
+	}
+	if len(counted) < review.Witness {
+		return fmt.Errorf("review needs %d witnesses, %d counted", review.Witness, len(counted))
+	}
+
+	approved.Counted, approved.Gathered = len(counted), len(counted)-had
+	for _, w := range counted {
+		approved.Devices = append(approved.Devices, w.DeviceID)
+	}
+	vm.emit(approved)
+	return nil
+}
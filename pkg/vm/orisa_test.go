@@ -0,0 +1,424 @@
+package vm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/witness"
+)
+
+func TestOyaWitnessGathersMissingWitnesses(t *testing.T) {
+	vm := NewVM()
+	vm.Sinks = nil
+	vm.Mesh = NewLocalMesh(vm.Witnesses, witness.NetworkLoRa)
+	if err := vm.LoadSource("oya.oso", []byte(`{
+		"name": "oya", "orisa": "oya_witness",
+		"ase": {"proof": "telemetry", "witnesses": 2},
+		"attributes": {"witness": {"count": 4, "network": "lora"}}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	proof := &Proof{Type: "telemetry", Receipt: strings.Repeat("ab", 32), Timestamp: time.Now().Unix(), DeviceID: "drone_001"}
+	witnesses, err := vm.Mesh.Broadcast("oya", proof, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := vm.Execute("oya", proof, witnesses)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	got := eventOf(res, EventWitnessesOrchestrated).(WitnessesOrchestrated)
+	if got.Counted != 4 || got.Gathered != 2 || len(res.Witnesses) != 4 {
+		t.Errorf("counted %d, gathered %d, result has %d witnesses; want 4, 2, 4", got.Counted, got.Gathered, len(res.Witnesses))
+	}
+
+	// No mesh node is on BLE, so gathering can't help
+	vm.Rituals["oya"].Attributes["witness"] = &WitnessAttr{Count: 2, Network: "ble"}
+	proof.Receipt = strings.Repeat("cd", 32)
+	if witnesses, err = vm.Mesh.Broadcast("oya", proof, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.Execute("oya", proof, witnesses); err == nil || !strings.Contains(err.Error(), "0 of 2 witnesses counted on ble") {
+		t.Errorf("BLE quorum: got %v", err)
+	}
+}
+
+func TestYemojaCacheRestoresStateUntilTTL(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	ritual := `{
+		"name": "memo", "orisa": "yemoja_cache",
+		"attributes": {"cache": {"ttl": 60}},
+		"args": {"visits": 0},
+		"statements": [
+			{"type": "assign", "data": {"variable": "visits", "expr": "visits + 1"}},
+			{"type": "call", "data": {"function": "cache_put", "args": ["seen", true]}},
+			{"type": "return", "data": {"expr": "visits"}}
+		]
+	}`
+	for want := int64(1); want <= 2; want++ {
+		res, err := run(t, vm, ritual)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Return != want {
+			t.Errorf("visit %d returned %v", want, res.Return)
+		}
+	}
+	memo := vm.Rituals["memo"].ID()
+	if e, ok := vm.Cache.Get(memo, "seen"); !ok || e.Value != true {
+		t.Errorf("cache_put entry: %v, %v", e, ok)
+	}
+
+	// Once the TTL passes the state is gone
+	now = now.Add(61 * time.Second)
+	res, err := run(t, vm, ritual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Return != int64(1) || eventOf(res, EventCacheLoaded).(CacheLoaded).Hit {
+		t.Errorf("after expiry returned %v", res.Return)
+	}
+
+	// A failed execution caches nothing
+	if _, err := run(t, vm, `{
+		"name": "fail", "orisa": "yemoja_cache",
+		"attributes": {"cache": {"ttl": 60}},
+		"statements": [{"type": "assign", "data": {"variable": "x", "expr": "1 / 0"}}]
+	}`); err == nil {
+		t.Fatal("division by zero succeeded")
+	}
+	if _, ok := vm.Cache.Get(vm.Rituals["fail"].ID(), "ritual:fail"); ok {
+		t.Error("failed execution was cached")
+	}
+}
+
+func TestYemojaCacheIsKeptPerRitual(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	writer := func(value string) string {
+		return `{
+			"name": "writer", "orisa": "yemoja_cache",
+			"attributes": {"cache": {"ttl": 60}},
+			"args": {"key": "shared", "owner": "writer"},
+			"statements": [{"type": "call", "data": {"function": "cache_put", "args": ["balance", ` + value + `]}}]
+		}`
+	}
+	if _, err := run(t, vm, writer("100")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another ritual asking for the same keys reads nothing
+	res, err := run(t, vm, `{
+		"name": "reader", "orisa": "yemoja_cache",
+		"attributes": {"cache": {"ttl": 60}},
+		"args": {"key": "shared"},
+		"statements": [
+			{"type": "assign", "data": {"variable": "balance", "expr": "cache_get(\"balance\")"}},
+			{"type": "return", "data": {"expr": "balance"}}
+		]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Return != nil || eventOf(res, EventCacheLoaded).(CacheLoaded).Hit || res.Variables["owner"] != nil {
+		t.Errorf("reader saw the writer's cache: returned %v, variables %v", res.Return, res.Variables)
+	}
+
+	// Nor does a changed program under the writer's name
+	old := vm.Rituals["writer"].ID()
+	res, err = run(t, vm, writer("200"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eventOf(res, EventCacheLoaded).(CacheLoaded).Hit {
+		t.Error("a changed program restored the old program's state")
+	}
+	if e, ok := vm.Cache.Get(old, "balance"); !ok || e.Value != int64(100) || e.Ritual != old {
+		t.Errorf("old entry %+v, %v", e, ok)
+	}
+	if _, ok := vm.Cache.Get("reader@"+strings.Split(old, "@")[1], "balance"); ok {
+		t.Error("entry served to a ritual that did not store it")
+	}
+}
+
+func TestYemojaCacheKeepsMaskedValuesOut(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	vm.Cache.Path = filepath.Join(t.TempDir(), "cache.json")
+	ritual := `{
+		"name": "vault_pin", "orisa": "yemoja_cache",
+		"attributes": {"cache": {"ttl": 60}, "mask": {"fields": "pin"}},
+		"args": {"pin": "` + secret + `"},
+		"statements": [
+			{"type": "assign", "data": {"variable": "copy", "expr": "pin"}},
+			{"type": "assign", "data": {"variable": "seen", "expr": "cache_get(pin)"}},
+			{"type": "call", "data": {"function": "cache_put", "args": ["` + secret + `", "` + secret + `"]}},
+			{"type": "return", "data": {"expr": "seen"}}
+		]
+	}`
+	if _, err := run(t, vm, ritual); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(vm.Cache.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Errorf("cache file holds the masked value:\n%s", data)
+	}
+
+	id := vm.Rituals["vault_pin"].ID()
+	state, ok := vm.Cache.Get(id, "ritual:vault_pin")
+	if !ok {
+		t.Fatal("no state cached")
+	}
+	if s := state.Value.(map[string]interface{}); s["pin"] != nil || s["copy"] != maskValue(secret) {
+		t.Errorf("cached state %v", s)
+	}
+
+	// The ritual still finds what it put under the masked key
+	res, err := run(t, vm, ritual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Return != maskValue(secret) {
+		t.Errorf("cache_get(pin) = %v", res.Return)
+	}
+}
+
+func TestFlushCacheReportsFailedWrites(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	vm.Context = &Context{
+		Ritual:    &Ritual{Name: "flush", Program: &Program{Ritual: "flush"}},
+		Variables: map[string]interface{}{},
+		cacheWrites: []cacheWrite{
+			{key: "", value: 1, ttl: time.Minute},
+			{key: "kept", value: 2, ttl: time.Minute},
+		},
+	}
+	vm.flushCache()
+
+	events := vm.Context.Events
+	if len(events) != 2 || events[0].Kind != EventCacheFailed || events[1].Kind != EventCacheStored {
+		t.Fatalf("events %+v", events)
+	}
+	if failed := events[0].Data.(CacheFailed); failed.Error != "cache key required" {
+		t.Errorf("failed write: %+v", failed)
+	}
+	if e, ok := vm.Cache.Get(vm.Context.Ritual.ID(), "kept"); !ok || e.Value != 2 {
+		t.Errorf("a failed write stopped the next one: %v, %v", e, ok)
+	}
+}
+
+func TestOgunForgeArtifact(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	forge := func(hardware string) (*ExecutionResult, error) {
+		return run(t, newTestVM(t, &now), `{
+			"name": "forge", "orisa": "ogun_forge",
+			"attributes": {
+				"sim": {"episode_id": 1, "steps": 1000},
+				"hardware": {"estop_check": true},
+				"compute": {"gpu": "h100", "hours": 2, "provider": "runpod"},
+				"safety": {"id": "s1", "items": [`+hardware+`], "signedByVisa": "council"}
+			},
+			"args": {"robot": "unitree_h1"},
+			"statements": [{"type": "return", "data": {"expr": "artifact"}}]
+		}`)
+	}
+
+	first, err := forge(`"estop"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := forge(`"estop"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := eventOf(first, EventArtifactForged).(ArtifactForged)
+	if first.Return != forged.Artifact || len(forged.Artifact) != 64 {
+		t.Errorf("returned %v, forged %s", first.Return, forged.Artifact)
+	}
+	if second.Return != first.Return {
+		t.Error("the same ritual forged different artifacts")
+	}
+	if len(forged.Stages) != 3 {
+		t.Errorf("stages: %+v", forged.Stages)
+	}
+
+	if _, err := forge(`"collision_detect"`); err == nil || !strings.Contains(err.Error(), "estop") {
+		t.Errorf("missing estop: got %v", err)
+	}
+}
+
+func TestOshunRiverStreams(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	if _, err := vm.Ledger.Deposit("alice", 1000, ""); err != nil {
+		t.Fatal(err)
+	}
+	res, err := proved(t, vm, "alice", `{
+		"name": "open", "orisa": "oshun_river",
+		"ase": {"proof": "telemetry", "witnesses": 1},
+		"attributes": {"duration": {"seconds": 100}},
+		"args": {"amount": 600, "from": "alice", "to": "bob"},
+		"statements": [{"type": "return", "data": {"expr": "stream"}}]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Return != int64(1) || vm.Ledger.Available("alice") != 400 {
+		t.Fatalf("stream %v, alice has %d available", res.Return, vm.Ledger.Available("alice"))
+	}
+
+	flow := `{"name": "flow", "orisa": "oshun_river", "args": {"stream": 1}}`
+	for _, step := range []struct {
+		after time.Duration
+		bob   int64
+	}{{25 * time.Second, 150}, {25 * time.Second, 300}, {time.Hour, 600}} {
+		now = now.Add(step.after)
+		if _, err := run(t, vm, flow); err != nil {
+			t.Fatal(err)
+		}
+		if got := vm.Ledger.Balance("bob"); got != step.bob {
+			t.Errorf("bob has %d, want %d", got, step.bob)
+		}
+	}
+	if len(vm.Ledger.Streams()) != 0 || len(vm.Ledger.Holds()) != 0 {
+		t.Error("a paid stream stayed open")
+	}
+	if _, err := run(t, vm, flow); err == nil {
+		t.Error("flowed a closed stream")
+	}
+}
+
+func TestOsanyinHerbLookup(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	herb := func(attrs string) error {
+		_, err := run(t, newTestVM(t, &now), `{"name": "herb", "orisa": "osanyin_herb", "attributes": {`+attrs+`}}`)
+		return err
+	}
+	for _, tc := range []struct {
+		attrs string
+		err   string
+	}{
+		{`"herb": {"key": "cerasee", "class": "ONCO"}, "regional": {"locale": "DO-Santiago"}`, ""},
+		{`"herb": {"name": "zingiber officinale", "potency": 0.5}`, ""},
+		{`"herb": {"key": "cerasee", "class": "FIRE"}`, "is class ONCO"},
+		{`"herb": {"key": "cerasee", "class": "ONCO"}, "regional": {"locale": "US-CA"}`, "not available in US-CA"},
+		{`"herb": {"key": "mandrake", "class": "ONCO"}`, "not in the herb registry"},
+	} {
+		err := herb(tc.attrs)
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%s: got %v, want %q", tc.attrs, err, tc.err)
+		}
+	}
+}
+
+func TestHerbLookupIsDeterministic(t *testing.T) {
+	// Two keys share a botanical name; the first key in order wins
+	herbs := HerbRegistry{}
+	for _, key := range []string{"k9", "k3", "k7", "k1", "k5"} {
+		herbs[key] = Herb{Key: key, Name: "Zingiber officinale"}
+	}
+	for i := 0; i < 50; i++ {
+		if h, ok := herbs.Lookup("zingiber OFFICINALE"); !ok || h.Key != "k1" {
+			t.Fatalf("lookup %d found %v", i, h)
+		}
+	}
+	if keys := herbs.Keys(); strings.Join(keys, ",") != "k1,k3,k5,k7,k9" {
+		t.Errorf("keys %v", keys)
+	}
+
+	_, err := NewHerbRegistry(Herb{Key: "a", Interactions: map[string]string{"b": "clash"}})
+	if err == nil || !strings.Contains(err.Error(), "herb a interacts with b, which is not in the registry") {
+		t.Errorf("unknown interaction: %v", err)
+	}
+}
+
+func TestCheckHerbInteractions(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	check := func(herb, args string) (*ExecutionResult, error) {
+		attrs := ""
+		if herb != "" {
+			attrs = `"herb": {"key": "` + herb + `"}`
+		}
+		if args != "" {
+			args = `\"` + args + `\"`
+		}
+		return run(t, newTestVM(t, &now), `{
+			"name": "mix", "orisa": "eshu_router",
+			"attributes": {`+attrs+`},
+			"statements": [{"type": "return", "data": {"expr": "check_herb_interactions(`+args+`)"}}]
+		}`)
+	}
+	for _, tc := range []struct {
+		herb, args string
+		checked    string
+		err        string
+	}{
+		{"cerasee", "", "cerasee", ""},
+		{"cerasee", "ginger, soursop", "cerasee,ginger,soursop", ""},
+		{"", "ginger,Annona muricata", "ginger,soursop", ""},
+		{"cerasee", "ginger, Moringa oleifera", "", "cerasee interacts with moringa: both lower blood sugar"},
+		{"moringa", "cerasee", "", "moringa interacts with cerasee: both lower blood sugar"},
+		{"cerasee", "mandrake", "", "mandrake is not in the herb registry"},
+		{"", "", "", "no herbs to check"},
+	} {
+		res, err := check(tc.herb, tc.args)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s with %s: got %v, want %q", tc.herb, tc.args, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || res.Return != true {
+			t.Errorf("%s with %s: returned %v: %v", tc.herb, tc.args, res.Return, err)
+			continue
+		}
+		if got := strings.Join(eventOf(res, EventHerbsChecked).(HerbsChecked).Herbs, ","); got != tc.checked {
+			t.Errorf("%s with %s: checked %s, want %s", tc.herb, tc.args, got, tc.checked)
+		}
+	}
+}
+
+func TestOrunmilaOracle(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	ritual := `{
+		"name": "ask", "orisa": "orunmila_oracle",
+		"attributes": {"oracle": {"query": "rain"}, "divination": {"seed": 369}},
+		"statements": [{"type": "return", "data": {"expr": "oracle"}}]
+	}`
+
+	// The oracle's table answers first
+	vm := newTestVM(t, &now)
+	vm.Oracle = OracleTable{"rain": {Value: "tomorrow", Confidence: 0.8}}
+	res, err := run(t, vm, ritual)
+	if err != nil || res.Return != "tomorrow" {
+		t.Fatalf("table answer: %v, %v", res.Return, err)
+	}
+
+	// Without one Ifá is cast, the same way every time
+	first, err := run(t, newTestVM(t, &now), ritual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := run(t, newTestVM(t, &now), ritual)
+	if first.Return != castOdu(369, "rain") || second.Return != first.Return {
+		t.Errorf("casts %v and %v", first.Return, second.Return)
+	}
+
+	// Divination carries no confidence, so @prediction rejects it
+	_, err = run(t, newTestVM(t, &now), strings.Replace(ritual, `"divination"`, `"prediction": {"model": "ifa", "confidence": 0.5}, "divination"`, 1))
+	if err == nil || !strings.Contains(err.Error(), "below the 0.5") {
+		t.Errorf("prediction: got %v", err)
+	}
+}
@@ -0,0 +1,603 @@
+// OSOVM - Ọ̀ṢỌ́ Virtual Machine
+// Phase 1: Proof-of-Ritual Core Runtime
+// Handles ritual execution, witness validation, and Àṣẹ flow
+
+package vm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/ast"
+	"github.com/ase-lang/osovm/pkg/cache"
+	"github.com/ase-lang/osovm/pkg/camera"
+	"github.com/ase-lang/osovm/pkg/diag"
+	"github.com/ase-lang/osovm/pkg/ledger"
+	"github.com/ase-lang/osovm/pkg/parser"
+	"github.com/ase-lang/osovm/pkg/proofkind"
+	"github.com/ase-lang/osovm/pkg/receipt"
+	"github.com/ase-lang/osovm/pkg/replay"
+	"github.com/ase-lang/osovm/pkg/witness"
+)
+
+// ========== Core Types ==========
+
+// ProofType - A proof kind name registered in pkg/proofkind. These three
+// are the Phase 1 kinds; any registered kind or alias is accepted.
+type ProofType string
+
+const (
+	ProofTelemetry ProofType = "telemetry"
+	ProofCognition ProofType = "cognition"
+	ProofHardware  ProofType = "hardware"
+)
+
+// Àṣẹ Attribute - The sacred seal. Rituals built in Go may set Ritual.Ase
+// directly; loaded rituals derive it from their @àṣẹ UniversalAse.
+type AseAttr struct {
+	ProofType ProofType `json:"proof"`
+	Witnesses int       `json:"witnesses"`
+}
+
+// Ritual - Sacred function invoking Òrìṣà
+type Ritual struct {
+	Name       string                 `json:"name"`
+	Orisa      string                 `json:"orisa"`
+	Ase        *AseAttr               `json:"ase,omitempty"`
+	Attributes map[string]Validator   `json:"attributes,omitempty"` // typed structs from attributes.go, keyed by name
+	Args       map[string]string      `json:"args"`
+	ArgValues  map[string]interface{} `json:"-"` // Args as typed values, for expressions
+	Body       []ast.Stmt             `json:"-"` // statements, compiled into Program
+	Program    *Program               `json:"-"` // bytecode run by Execute
+}
+
+// Universal returns the ritual's @àṣẹ attribute, or nil for rituals that
+// only carry the legacy AseAttr
+func (r *Ritual) Universal() *UniversalAse {
+	ase, _ := r.Attributes["àṣẹ"].(*UniversalAse)
+	return ase
+}
+
+// ID names the ritual together with the program that defines it, args
+// aside. Ledger state a ritual owns is keyed by ID rather than by name, so
+// another program declaring a ritual of the same name can't reach it.
+func (r *Ritual) ID() string {
+	return r.Name + "@" + r.Program.Identity()
+}
+
+// Proof - Real-world action verification. The raw data behind the receipt
+// may travel inline (Payload) or by reference (PayloadRef); either way the
+// VM recomputes the receipt from it.
+type Proof struct {
+	Type       ProofType       `json:"type"`
+	Receipt    string          `json:"receipt"` // Hash of telemetry/action
+	Timestamp  int64           `json:"timestamp"`
+	DeviceID   string          `json:"device_id"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	PayloadRef string          `json:"payload_ref,omitempty"`
+}
+
+// Witness - Network confirmation. Signature is a hex Ed25519 signature over
+// the witness.Attestation for the proof receipt, ritual, timestamp and network.
+type Witness struct {
+	DeviceID  string `json:"device_id"`
+	Signature string `json:"signature"`
+	Timestamp int64  `json:"timestamp"`
+	Network   string `json:"network"`
+}
+
+// Execution Context
+type Context struct {
+	Ritual    *Ritual
+	Proof     *Proof
+	Witnesses []Witness
+	Device    *DeviceAttr       // device the proof must come from, if bound
+	Payload   proofkind.Payload // decoded proof payload, once verified
+	Variables map[string]interface{}
+	Return    interface{}      // value of the executed return statement
+	Receipt   string           // execution receipt, once emitted
+	Gas       *GasMeter        // gas budget of this execution
+	Counted   []Witness        // witnesses counted toward the Àṣẹ quorum
+	Events    []Event          // events emitted so far
+	Sealed    *receipt.Receipt // signed receipt, once sealed
+	Guard     *GuardDecision   // obatala_guard's decision, once it has run
+	Claimed   bool             // the proof receipt was claimed in vm.Receipts
+	Masked    map[string]bool  // args and variables hidden by @mask
+
+	cacheWrites []cacheWrite           // applied once the execution completes
+	hidden      map[string]interface{} // values of masked fields by their JSON, redacted from everything published
+	replacer    *strings.Replacer      // replaces hidden strings, built from hidden when first needed
+}
+
+// ========== VM State ==========
+
+type VM struct {
+	Rituals   map[string]*Ritual
+	Context   *Context
+	Witnesses *witness.Registry // trusted witness keys
+	Quorum    QuorumPolicy
+	Freshness FreshnessPolicy
+	Receipts  replay.Store     // proof receipts already accepted, per ritual
+	Now       func() time.Time // clock used for every time check
+	Device    *DeviceAttr      // device the VM runs on, for auto_device
+	Scanner   camera.Scanner   // captures QR proofs when none is supplied
+	Mesh      WitnessMesh      // gathers witnesses for captured proofs
+	Hosts     *HostRegistry    // functions "call" statements may invoke
+	Sinks     []EventSink      // receive every execution event
+	Warnings  io.Writer        // receives load-time warnings; nil discards them
+
+	Operator   *receipt.Operator // signs execution receipts
+	ReceiptLog receipt.Log       // hash chain execution receipts are appended to
+
+	Ledger     *ledger.Ledger // accounts eshu_router and sango_vault move value between
+	RouteRules []RouteRule    // conditional tithe routing, first match wins
+	Shrines    ShrineShares   // accounts paid by @shrineSplit
+	Governance Governance     // dissent and vetoes obatala_guard weighs
+
+	Cache  *cache.Cache // state yemoja_cache keeps between executions
+	Herbs  HerbRegistry // herbs osanyin_herb prescribes
+	Oracle Oracle       // answers orunmila_oracle queries; nil leaves only divination
+
+	RequirePayload bool                             // reject proofs without a payload
+	ResolvePayload func(ref string) ([]byte, error) // fetches Proof.PayloadRef; nil disables references
+
+	MaxLoopIterations int64 // cap on any loop; 0 means DefaultMaxLoopIterations
+	GasLimit          int64 // cap on any execution's gas; 0 means DefaultGasLimit
+}
+
+// FreshnessPolicy - How old a proof may be and how far witness attestations
+// may drift from it. A zero duration disables that check.
+type FreshnessPolicy struct {
+	MaxProofAge    time.Duration // now - Proof.Timestamp
+	MaxWitnessSkew time.Duration // |Witness.Timestamp - Proof.Timestamp|
+	MaxClockDrift  time.Duration // how far in the future a proof may be dated
+}
+
+// DefaultFreshness is used by NewVM
+var DefaultFreshness = FreshnessPolicy{
+	MaxProofAge:    10 * time.Minute,
+	MaxWitnessSkew: 2 * time.Minute,
+	MaxClockDrift:  30 * time.Second,
+}
+
+func NewVM() *VM {
+	return &VM{
+		Rituals:    make(map[string]*Ritual),
+		Witnesses:  witness.NewRegistry(),
+		Freshness:  DefaultFreshness,
+		Receipts:   replay.NewMemoryStore(),
+		Now:        time.Now,
+		Hosts:      NewHostRegistry(),
+		Sinks:      []EventSink{NewConsoleSink(os.Stdout)},
+		Warnings:   os.Stderr,
+		Operator:   ephemeralOperator(),
+		ReceiptLog: receipt.NewMemoryLog(),
+		Ledger:     ledger.New(),
+		Shrines:    DefaultShrineShares,
+		Cache:      cache.New(),
+		Herbs:      DefaultHerbs,
+	}
+}
+
+// ephemeralOperator is the operator key of a VM not given one; its
+// receipts can't be attributed to anyone once the process exits
+func ephemeralOperator() *receipt.Operator {
+	op, err := receipt.GenerateOperator()
+	if err != nil {
+		panic(err)
+	}
+	return op
+}
+
+// ========== Loader ==========
+
+// LoadRitual loads a .oso file in either the JSON ritual format or the
+// textual ORISA syntax. Textual modules may declare several rituals. On
+// failure the error is a *diag.List describing every problem in the file.
+func (vm *VM) LoadRitual(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read ritual: %w", err)
+	}
+	return vm.LoadSource(path, data)
+}
+
+// LoadSource loads ritual source already in memory and compiles each
+// ritual to bytecode. name is used for diagnostics and as the module name
+// for JSON rituals without one. Encoded programs (.osob) load as they are.
+func (vm *VM) LoadSource(name string, data []byte) error {
+	if IsProgram(data) {
+		p, err := DecodeProgram(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return vm.LoadProgram(p)
+	}
+
+	diags := diag.NewList(name, data)
+	mod := parser.ParseFile(name, data, diags)
+	rituals := lowerModule(mod, vm.Hosts, diags)
+	if err := diags.Err(); err != nil {
+		return err
+	}
+	if vm.Warnings != nil {
+		for _, d := range diags.Diagnostics {
+			fmt.Fprintln(vm.Warnings, diags.Format(d))
+		}
+	}
+
+	for _, ritual := range rituals {
+		p, err := Compile(ritual)
+		if err != nil {
+			return err
+		}
+		ritual.Program = p
+		vm.Rituals[ritual.Name] = ritual
+	}
+	return nil
+}
+
+// LoadProgram registers a compiled program as a ritual with the attributes
+// encoded in it; its Àṣẹ requirement is the program's REQUIRE_PROOF.
+func (vm *VM) LoadProgram(p *Program) error {
+	if p.Version != BytecodeVersion {
+		return fmt.Errorf("bytecode version %d is not supported (want %d)", p.Version, BytecodeVersion)
+	}
+	if err := p.check(); err != nil {
+		return err
+	}
+	if err := resolveProgram(p, vm.Hosts); err != nil {
+		return err
+	}
+	ritual := &Ritual{
+		Name:       p.Ritual,
+		Orisa:      p.Orisa,
+		Attributes: p.Attributes,
+		Args:       make(map[string]string, len(p.Args)),
+		ArgValues:  p.Args,
+		Program:    p,
+	}
+	for k, v := range p.Args {
+		ritual.Args[k] = formatValue(v)
+	}
+	for _, in := range p.Code {
+		if in.Op == OpRequireProof {
+			ritual.Ase = &AseAttr{ProofType: ProofType(p.Constants[in.A].(string)), Witnesses: in.B}
+			break
+		}
+	}
+	vm.Rituals[ritual.Name] = ritual
+	return nil
+}
+
+// RitualName derives the ritual name from a .oso or .osob file name
+func RitualName(path string) string {
+	return strings.TrimSuffix(parser.ModuleName(path), ProgramExt)
+}
+
+// ========== Execution Engine ==========
+
+// Execute runs a ritual and returns its ExecutionResult. Once the ritual
+// is found a result is returned even when execution fails, with Status
+// StatusFailed and the error in Error.
+func (vm *VM) Execute(ritualName string, proof *Proof, witnesses []Witness) (*ExecutionResult, error) {
+	ritual, exists := vm.Rituals[ritualName]
+	if !exists {
+		return nil, fmt.Errorf("ritual '%s' not found", ritualName)
+	}
+
+	// Initialize context
+	vm.Context = &Context{
+		Ritual:    ritual,
+		Proof:     proof,
+		Witnesses: witnesses,
+		Variables: make(map[string]interface{}),
+		Masked:    maskedFields(ritual),
+	}
+	for field := range vm.Context.Masked {
+		if v, ok := ritual.ArgValues[field]; ok {
+			vm.Context.hide(v)
+		}
+	}
+	started := vm.Now()
+	// Ledger postings and holds, and the proof receipt's claim, stand or
+	// fall with the execution
+	checkpoint := vm.Ledger.Checkpoint()
+	err := vm.execute(ritual)
+	if err != nil {
+		if n := vm.Ledger.Rollback(checkpoint); n > 0 {
+			vm.emit(LedgerRolledBack{Entries: n})
+		}
+		if ctx := vm.Context; ctx.Claimed {
+			if rerr := vm.Receipts.Release(ritual.Name, ctx.Proof.Receipt); rerr != nil {
+				err = fmt.Errorf("%w (and the proof receipt stays claimed: %v)", err, rerr)
+			} else {
+				vm.emit(ProofReleased{Receipt: ctx.Proof.Receipt})
+			}
+		}
+	}
+	// Masked values don't leave in the error either
+	err = vm.Context.redactError(err)
+	return vm.result(started, err), err
+}
+
+func (vm *VM) execute(ritual *Ritual) error {
+	vm.emit(RitualInvoked{Orisa: ritual.Orisa})
+
+	// 1. Validate attributes against the canon
+	if err := vm.validateAttributes(); err != nil {
+		return fmt.Errorf("❌ Attribute validation failed: %w", err)
+	}
+
+	// 2. Run device capabilities (device, auto_device, QR)
+	if err := vm.runCapabilities(false); err != nil {
+		return fmt.Errorf("❌ Capability failed: %w", err)
+	}
+
+	// 3-6. Run the compiled program: Àṣẹ, sealed capabilities and
+	// attribute hooks, the Òrìṣà precompile, then the statements
+	if ritual.Program == nil {
+		p, err := Compile(ritual)
+		if err != nil {
+			return err
+		}
+		ritual.Program = p
+	}
+	if _, err := vm.run(ritual.Program); err != nil {
+		return err
+	}
+
+	// 7. Seal the execution receipt onto the operator's chain
+	if err := vm.sealReceipt(); err != nil {
+		return fmt.Errorf("❌ Receipt sealing failed: %w", err)
+	}
+
+	// 8. Keep what the ritual cached
+	vm.flushCache()
+	return nil
+}
+
+// ========== Attribute Validation ==========
+
+// AttributeFailure - One attribute that violated its canon constraints
+type AttributeFailure struct {
+	Attribute string
+	Category  string
+	Err       error
+}
+
+// ValidationError - Every attribute failure for a ritual, in registry order
+type ValidationError struct {
+	Ritual   string
+	Failures []AttributeFailure
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "ritual '%s' violates %d attribute constraint(s)", e.Ritual, len(e.Failures))
+	for _, f := range e.Failures {
+		fmt.Fprintf(&sb, "\n  @%s (%s): %v", f.Attribute, f.Category, f.Err)
+	}
+	return sb.String()
+}
+
+// validateAttributes runs the validator of every attached attribute and
+// returns a *ValidationError listing all failures
+func (vm *VM) validateAttributes() error {
+	ritual := vm.Context.Ritual
+	var failures []AttributeFailure
+	for _, spec := range attributeRegistry.All() {
+		value, attached := ritual.Attributes[spec.Name]
+		if !attached {
+			continue
+		}
+		if err := value.Validate(); err != nil {
+			failures = append(failures, AttributeFailure{
+				Attribute: spec.Name,
+				Category:  spec.Category,
+				Err:       err,
+			})
+		}
+	}
+	if len(failures) > 0 {
+		return &ValidationError{Ritual: ritual.Name, Failures: failures}
+	}
+	if len(ritual.Attributes) > 0 {
+		vm.emit(AttributesValidated{Count: len(ritual.Attributes)})
+	}
+	return nil
+}
+
+// runAttributeHooks runs the registered hook of every attached attribute,
+// in registration order
+func (vm *VM) runAttributeHooks() error {
+	for _, spec := range attributeRegistry.All() {
+		value, attached := vm.Context.Ritual.Attributes[spec.Name]
+		if !attached || spec.Hook == nil {
+			continue
+		}
+		if err := vm.Context.Gas.Consume(GasHook, "hook @"+spec.Name); err != nil {
+			return err
+		}
+		if err := spec.Hook(vm, value); err != nil {
+			return fmt.Errorf("@%s: %w", spec.Name, err)
+		}
+	}
+	return nil
+}
+
+// ========== Àṣẹ Validation ==========
+
+func (vm *VM) validateAse(ase *AseAttr) error {
+	proof := vm.Context.Proof
+	witnesses := vm.Context.Witnesses
+
+	// 1. Verify proof type matches
+	if proof == nil {
+		return fmt.Errorf("proof required but not provided")
+	}
+	if !proofkind.Known(string(proof.Type)) {
+		return fmt.Errorf("unknown proof kind: %s", proof.Type)
+	}
+	if !proofkind.Satisfies(string(proof.Type), string(ase.ProofType)) {
+		return fmt.Errorf("proof type mismatch: expected %s, got %s", ase.ProofType, proof.Type)
+	}
+
+	// 2. Verify the receipt against the kind's schema
+	if err := proofkind.VerifyReceipt(string(proof.Type), proof.Receipt); err != nil {
+		return err
+	}
+	if err := vm.verifyPayload(proof); err != nil {
+		return err
+	}
+	if device := vm.Context.Device; device != nil && proof.DeviceID != device.ID {
+		return fmt.Errorf("proof from %s, but the ritual is bound to device %s", proof.DeviceID, device.ID)
+	}
+
+	// 3. Check the proof is fresh
+	if err := vm.checkProofFreshness(proof); err != nil {
+		return err
+	}
+
+	// 4. Verify witnesses and check quorum
+	report := vm.countWitnesses(proof, witnesses, ase.Witnesses)
+	if !report.Met() {
+		return &QuorumError{Report: report}
+	}
+
+	// 5. Consume the receipt so it can't seal this ritual again. Execute
+	// releases it if the execution goes on to fail.
+	if err := vm.Receipts.Claim(vm.Context.Ritual.Name, proof.Receipt, proof.Timestamp); err != nil {
+		return err
+	}
+	vm.Context.Claimed = true
+
+	vm.Context.Counted = report.Counted
+	vm.emit(ProofValidated{ProofType: proof.Type, Receipt: proof.Receipt, Counted: len(report.Counted), Required: ase.Witnesses})
+	for _, d := range report.Discarded {
+		vm.emit(WitnessDiscarded{Index: d.Index, DeviceID: d.DeviceID, Reason: d.Reason})
+	}
+
+	return nil
+}
+
+// verifyPayload decodes the proof's payload for its kind and recomputes the
+// receipt from it
+func (vm *VM) verifyPayload(proof *Proof) error {
+	raw := []byte(proof.Payload)
+	switch {
+	case len(raw) > 0 && proof.PayloadRef != "":
+		return fmt.Errorf("proof has both an inline payload and a payload reference")
+	case proof.PayloadRef != "":
+		if vm.ResolvePayload == nil {
+			return fmt.Errorf("payload references are not enabled")
+		}
+		fetched, err := vm.ResolvePayload(proof.PayloadRef)
+		if err != nil {
+			return fmt.Errorf("failed to fetch payload %s: %w", proof.PayloadRef, err)
+		}
+		raw = fetched
+	case len(raw) == 0:
+		if vm.RequirePayload {
+			return fmt.Errorf("proof payload required")
+		}
+		return nil
+	}
+
+	payload, err := proofkind.VerifyPayload(string(proof.Type), proof.Receipt, raw)
+	if err != nil {
+		return err
+	}
+	vm.Context.Payload = payload
+	vm.emit(PayloadVerified{ProofType: proof.Type})
+	return nil
+}
+
+// checkProofFreshness rejects proofs older than MaxProofAge or dated further
+// in the future than MaxClockDrift
+func (vm *VM) checkProofFreshness(proof *Proof) error {
+	now := vm.Now()
+	proofTime := time.Unix(proof.Timestamp, 0)
+	if max := vm.Freshness.MaxProofAge; max > 0 {
+		if age := now.Sub(proofTime); age > max {
+			return fmt.Errorf("proof is stale: %s old (max %s)", age.Truncate(time.Second), max)
+		}
+		// Receipts older than any acceptable proof can be forgotten
+		if err := vm.Receipts.Prune(now.Add(-max).Unix()); err != nil {
+			return err
+		}
+	}
+	if max := vm.Freshness.MaxClockDrift; max > 0 {
+		if ahead := proofTime.Sub(now); ahead > max {
+			return fmt.Errorf("proof is dated %s in the future (max %s)", ahead.Truncate(time.Second), max)
+		}
+	}
+	return nil
+}
+
+// checkWitnessSkew rejects attestations too far from the proof's timestamp
+func (vm *VM) checkWitnessSkew(w *Witness, proof *Proof) error {
+	max := vm.Freshness.MaxWitnessSkew
+	if max <= 0 {
+		return nil
+	}
+	skew := time.Duration(w.Timestamp-proof.Timestamp) * time.Second
+	if skew > max {
+		return fmt.Errorf("attested %s after the proof (max %s)", skew, max)
+	}
+	if -skew > max {
+		return fmt.Errorf("attested %s before the proof (max %s)", -skew, max)
+	}
+	return nil
+}
+
+func (vm *VM) validateWitness(w *Witness, proof *Proof) error {
+	return vm.Witnesses.VerifyAttestation(w.DeviceID, witness.Attestation{
+		Receipt:   proof.Receipt,
+		Device:    proof.DeviceID,
+		Ritual:    vm.Context.Ritual.Name,
+		Timestamp: w.Timestamp,
+		Network:   w.Network,
+	}, w.Signature)
+}
+
+func isValidHash(hash string) bool {
+	if len(hash) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+// ========== Òrìṣà Precompiles ==========
+
+func (vm *VM) executeOrisa(orisa string) error {
+	vm.emit(OrisaInvoked{Orisa: orisa})
+	switch orisa {
+	case "eshu_router":
+		return vm.eshuRouter()
+	case "obatala_guard":
+		return vm.obatalaGuard()
+	case "sango_vault":
+		return vm.sangoVault()
+	case "oya_witness":
+		return vm.oyaWitness()
+	case "yemoja_cache":
+		return vm.yemojaCache()
+	case "ogun_forge":
+		return vm.ogunForge()
+	case "oshun_river":
+		return vm.oshunRiver()
+	case "osanyin_herb":
+		return vm.osanyinHerb()
+	case "orunmila_oracle":
+		return vm.orunmilaOracle()
+	default:
+		return fmt.Errorf("unknown Òrìṣà: %s", orisa)
+	}
+}
@@ -0,0 +1,117 @@
+package vm
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/replay"
+	"github.com/ase-lang/osovm/pkg/witness"
+)
+
+func TestFailedExecutionReleasesItsProof(t *testing.T) {
+	vm := NewVM()
+	vm.Sinks = nil
+	store, err := replay.OpenFileStore(filepath.Join(t.TempDir(), "receipts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.Receipts = store
+	if err := vm.LoadSource("veto.oso", []byte(`{
+		"name": "veto", "orisa": "obatala_guard",
+		"ase": {"proof": "telemetry", "witnesses": 2},
+		"attributes": {"veto": {"authority": "council"}}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+	proof := &Proof{Type: "telemetry", Receipt: strings.Repeat("e", 64), Timestamp: time.Now().Unix(), DeviceID: "drone_001"}
+	witnesses, err := NewLocalMesh(vm.Witnesses, witness.NetworkLoRa).Broadcast("veto", proof, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The guard denies the ritual after the proof was claimed
+	vm.Governance.Vetoes = map[string]string{"council": "paused"}
+	res, err := vm.Execute("veto", proof, witnesses)
+	var denied *GuardDeniedError
+	if !errors.As(err, &denied) {
+		t.Fatalf("vetoed: %v", err)
+	}
+	if eventOf(res, EventProofReleased) == nil {
+		t.Error("no proof.released event")
+	}
+
+	// So the same proof can be retried, and then never again
+	vm.Governance.Vetoes = nil
+	if _, err := vm.Execute("veto", proof, witnesses); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	res, err = vm.Execute("veto", proof, witnesses)
+	if !errors.Is(err, replay.ErrReplay) {
+		t.Fatalf("replay: %v", err)
+	}
+	if eventOf(res, EventProofReleased) != nil {
+		t.Error("a rejected replay released the proof")
+	}
+}
+
+func TestValidationReportsEveryFailureBeforeAse(t *testing.T) {
+	vm := NewVM()
+	vm.Sinks = nil
+	// Attributes in the reverse of registry order, each one invalid
+	if err := vm.LoadSource("invalid.oso", []byte(`{
+		"name": "invalid", "orisa": "eshu_router",
+		"ase": {"proof": "telemetry", "witnesses": 2},
+		"attributes": {
+			"gps": {"lat": 91, "lon": 0},
+			"tithe": {"rate": 100},
+			"vote": {"weight": 0.5}
+		}
+	}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ attr, msg string }{
+		{"vote", "vote weight must be exactly 1.0 (got 0.50)"},
+		{"tithe", "tithe rate must be exactly 369 (got 100)"},
+		{"gps", "latitude must be between -90 and 90"},
+	}
+	var first string
+	for i := 0; i < 10; i++ {
+		// No proof is given: Àṣẹ would fail, but validation comes first
+		res, err := vm.Execute("invalid", nil, nil)
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) {
+			t.Fatalf("got %v", err)
+		}
+		if len(invalid.Failures) != len(want) {
+			t.Fatalf("failures %+v", invalid.Failures)
+		}
+		for j, f := range invalid.Failures {
+			if f.Attribute != want[j].attr || f.Err.Error() != want[j].msg {
+				t.Errorf("failure %d: @%s %v, want @%s %s", j, f.Attribute, f.Err, want[j].attr, want[j].msg)
+			}
+		}
+		msg := err.Error()
+		if !strings.HasPrefix(msg, "❌ Attribute validation failed: ritual 'invalid' violates 3 attribute constraint(s)\n") {
+			t.Errorf("message %q", msg)
+		}
+		for _, w := range want {
+			if !strings.Contains(msg, "\n  @"+w.attr+" (") || !strings.Contains(msg, w.msg) {
+				t.Errorf("message lacks @%s: %q", w.attr, msg)
+			}
+		}
+		if i == 0 {
+			first = msg
+		} else if msg != first {
+			t.Fatalf("message changed between runs:\n%s\n%s", first, msg)
+		}
+		for _, e := range res.Events {
+			if e.Kind == EventAseSealed || e.Kind == EventProofValidated {
+				t.Errorf("%s emitted before validation passed", e.Kind)
+			}
+		}
+	}
+}
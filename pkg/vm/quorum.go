@@ -0,0 +1,134 @@
+// OSOVM Witness Quorum
+// Decides which submitted witnesses count toward an Àṣẹ quorum
+
+package vm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QuorumPolicy - Optional diversity requirements on counted witnesses
+type QuorumPolicy struct {
+	DistinctDeviceTypes bool // at most one counted witness per device type
+	DistinctNetworks    bool // at most one counted witness per network
+}
+
+// DiscardedWitness - A submitted witness that did not count, and why
+type DiscardedWitness struct {
+	Index    int
+	DeviceID string
+	Reason   string
+}
+
+// QuorumReport - Outcome of counting witnesses for one execution
+type QuorumReport struct {
+	Required  int
+	Counted   []Witness
+	Discarded []DiscardedWitness
+}
+
+// Met reports whether enough witnesses counted
+func (r *QuorumReport) Met() bool {
+	return len(r.Counted) >= r.Required
+}
+
+// QuorumError - The quorum was not met; lists every discarded witness
+type QuorumError struct {
+	Report *QuorumReport
+}
+
+func (e *QuorumError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "insufficient witnesses: need %d, got %d", e.Report.Required, len(e.Report.Counted))
+	for _, d := range e.Report.Discarded {
+		fmt.Fprintf(&sb, "\n  witness %d (%s) discarded: %s", d.Index, d.DeviceID, d.Reason)
+	}
+	return sb.String()
+}
+
+// countWitnesses verifies each witness and applies the quorum rules in
+// submission order: the first valid witness for a device ID, key, device
+// type or network counts and later ones are discarded. Keys, device types
+// and networks are the enrolled ones. The prover never counts, whether by
+// device ID or by sharing the prover's key.
+func (vm *VM) countWitnesses(proof *Proof, witnesses []Witness, required int) *QuorumReport {
+	report := &QuorumReport{Required: required}
+	discard := func(i int, w Witness, format string, args ...interface{}) {
+		report.Discarded = append(report.Discarded, DiscardedWitness{
+			Index:    i,
+			DeviceID: w.DeviceID,
+			Reason:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	var proverKey string
+	if prover, ok := vm.Witnesses.Lookup(proof.DeviceID); ok {
+		proverKey = prover.PublicKey
+	}
+
+	seenDevice := make(map[string]int)
+	seenKey := make(map[string]string)
+	seenType := make(map[string]string)
+	seenNetwork := make(map[string]string)
+
+	for i, w := range witnesses {
+		if w.DeviceID == proof.DeviceID {
+			discard(i, w, "is the proving device")
+			continue
+		}
+		if first, dup := seenDevice[w.DeviceID]; dup {
+			discard(i, w, "duplicate of witness %d", first)
+			continue
+		}
+
+		// Only a valid attestation claims its device, so a forged one can't
+		// shadow the device's real attestation later in the list
+		if err := vm.checkWitnessSkew(&w, proof); err != nil {
+			discard(i, w, "%v", err)
+			continue
+		}
+		if err := vm.validateWitness(&w, proof); err != nil {
+			discard(i, w, "%v", err)
+			continue
+		}
+		seenDevice[w.DeviceID] = i
+
+		// The network is the witness's own claim; it must be the one the
+		// device was enrolled on
+		enrollment, _ := vm.Witnesses.Lookup(w.DeviceID)
+		if w.Network != enrollment.Network {
+			discard(i, w, "attests from network %q but is enrolled on %q", w.Network, enrollment.Network)
+			continue
+		}
+
+		key := enrollment.PublicKey
+		if proverKey != "" && key == proverKey {
+			discard(i, w, "shares its key with the prover %s", proof.DeviceID)
+			continue
+		}
+		if other, dup := seenKey[key]; dup {
+			discard(i, w, "shares its key with %s", other)
+			continue
+		}
+
+		if vm.Quorum.DistinctDeviceTypes {
+			if other, dup := seenType[enrollment.DeviceType]; dup {
+				discard(i, w, "device type %q already counted for %s", enrollment.DeviceType, other)
+				continue
+			}
+		}
+		if vm.Quorum.DistinctNetworks {
+			if other, dup := seenNetwork[enrollment.Network]; dup {
+				discard(i, w, "network %q already counted for %s", enrollment.Network, other)
+				continue
+			}
+		}
+
+		seenKey[key] = w.DeviceID
+		seenType[enrollment.DeviceType] = w.DeviceID
+		seenNetwork[enrollment.Network] = w.DeviceID
+		report.Counted = append(report.Counted, w)
+	}
+	return report
+}
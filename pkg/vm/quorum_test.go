@@ -0,0 +1,104 @@
+package vm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ase-lang/osovm/pkg/witness"
+)
+
+// quorumVM loads a ritual that needs two witnesses and returns a proof
+// for it; each call's proof has a fresh receipt. The VM has no mesh, so
+// only the witnesses submitted count.
+func quorumVM(t *testing.T) (*VM, func() *Proof) {
+	t.Helper()
+	vm := NewVM()
+	vm.Sinks = nil
+	if err := vm.LoadSource("q.oso", []byte(`{"name": "q", "orisa": "eshu_router", "ase": {"proof": "telemetry", "witnesses": 2}}`)); err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	return vm, func() *Proof {
+		n++
+		return &Proof{Type: "telemetry", Receipt: strings.Repeat(string(rune('a'+n)), 64), Timestamp: time.Now().Unix(), DeviceID: "drone_001"}
+	}
+}
+
+func TestForgedAttestationDoesNotShadowTheDevice(t *testing.T) {
+	vm, newProof := quorumVM(t)
+	mesh := NewLocalMesh(vm.Witnesses, witness.NetworkLoRa)
+	proof := newProof()
+	honest, err := mesh.Broadcast("q", proof, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A forged packet for the first witness arrives before its real one
+	forged := honest[0]
+	forged.Signature = honest[1].Signature
+	res, err := vm.Execute("q", proof, []Witness{forged, honest[1], honest[0]})
+	if err != nil {
+		t.Fatalf("forged attestation knocked out an honest witness: %v", err)
+	}
+	if len(res.Witnesses) != 2 {
+		t.Errorf("%d witnesses counted", len(res.Witnesses))
+	}
+
+	// A second valid attestation from a device is still a duplicate
+	proof = newProof()
+	if honest, err = mesh.Broadcast("q", proof, 2); err != nil {
+		t.Fatal(err)
+	}
+	var qe *QuorumError
+	_, err = vm.Execute("q", proof, []Witness{honest[0], honest[0]})
+	if !errors.As(err, &qe) || len(qe.Report.Discarded) != 1 || qe.Report.Discarded[0].Reason != "duplicate of witness 0" {
+		t.Errorf("duplicate: %v", err)
+	}
+}
+
+func TestQuorumUsesEnrolledNetworks(t *testing.T) {
+	vm, newProof := quorumVM(t)
+	vm.Quorum.DistinctNetworks = true
+
+	// Two LoRa nodes; the second signs a claim to be on BLE
+	lora, err := witness.CreateNode("lora_a", "phone", witness.NetworkLoRa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	liar, err := witness.CreateNode("lora_b", "phone", witness.NetworkLoRa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []*witness.Node{lora, liar} {
+		if _, err := vm.Witnesses.EnrollNode(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	attest := func(n *witness.Node, proof *Proof, network string) Witness {
+		att := witness.Attestation{Receipt: proof.Receipt, Device: proof.DeviceID, Ritual: "q", Timestamp: time.Now().Unix(), Network: network}
+		sig, err := n.Sign(att)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Witness{DeviceID: n.DeviceID, Signature: sig, Timestamp: att.Timestamp, Network: network}
+	}
+
+	proof := newProof()
+	var qe *QuorumError
+	_, err = vm.Execute("q", proof, []Witness{attest(lora, proof, witness.NetworkLoRa), attest(liar, proof, witness.NetworkBLE)})
+	if !errors.As(err, &qe) || len(qe.Report.Counted) != 1 {
+		t.Fatalf("self-asserted network counted: %v", err)
+	}
+	if got := qe.Report.Discarded[0].Reason; got != `attests from network "ble" but is enrolled on "lora"` {
+		t.Errorf("discarded: %s", got)
+	}
+
+	// Telling the truth, the second node is on the same network as the first
+	proof = newProof()
+	_, err = vm.Execute("q", proof, []Witness{attest(lora, proof, witness.NetworkLoRa), attest(liar, proof, witness.NetworkLoRa)})
+	if !errors.As(err, &qe) || !strings.Contains(qe.Report.Discarded[0].Reason, `network "lora" already counted for lora_a`) {
+		t.Errorf("distinct networks: %v", err)
+	}
+}
@@ -0,0 +1,536 @@
+// OSOVM Attribute Registry
+// Every attribute is registered once: name, Go type, grammar rules,
+// category, validator and runtime hook
+
+package vm
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ase-lang/osovm/docs"
+	"github.com/ase-lang/osovm/grammar"
+	"github.com/ase-lang/osovm/pkg/bind"
+	"github.com/ase-lang/osovm/pkg/proofkind"
+)
+
+// AttributeHook runs during Execute for an attached attribute, after Àṣẹ
+// validation and before the Òrìṣà precompile
+type AttributeHook func(vm *VM, attr Validator) error
+
+// AttributeSpec - One registered attribute
+type AttributeSpec struct {
+	Name     string       // as written after '@'
+	Type     reflect.Type // struct type; its pointer implements Validator
+	Rules    []string     // ORISA_FULL.pest rules, empty for Phase 2 extensions
+	Category string       // docs/ATTRIBUTES_CANON.md section for canon attributes
+	Hook     AttributeHook
+}
+
+// New returns a zero value of the attribute's struct
+func (s *AttributeSpec) New() Validator {
+	return reflect.New(s.Type).Interface().(Validator)
+}
+
+// Canon reports whether the attribute is part of ORISA_FULL.pest
+func (s *AttributeSpec) Canon() bool {
+	return len(s.Rules) > 0
+}
+
+// AttributeRegistry - All attributes the VM understands, in registration order
+type AttributeRegistry struct {
+	specs  []*AttributeSpec
+	byName map[string]*AttributeSpec
+}
+
+func newAttributeRegistry(groups ...[]*AttributeSpec) *AttributeRegistry {
+	r := &AttributeRegistry{byName: make(map[string]*AttributeSpec)}
+	for _, group := range groups {
+		for _, spec := range group {
+			if _, dup := r.byName[spec.Name]; dup {
+				panic("attribute registered twice: " + spec.Name)
+			}
+			r.specs = append(r.specs, spec)
+			r.byName[spec.Name] = spec
+		}
+	}
+	return r
+}
+
+// Lookup returns the spec registered under name
+func (r *AttributeRegistry) Lookup(name string) (*AttributeSpec, bool) {
+	spec, ok := r.byName[name]
+	return spec, ok
+}
+
+// All returns every spec in registration order
+func (r *AttributeRegistry) All() []*AttributeSpec {
+	return r.specs
+}
+
+// Count returns the number of registered attributes
+func (r *AttributeRegistry) Count() int {
+	return len(r.specs)
+}
+
+// CanonCount returns the number of registered attributes in the canon
+func (r *AttributeRegistry) CanonCount() int {
+	n := 0
+	for _, spec := range r.specs {
+		if spec.Canon() {
+			n++
+		}
+	}
+	return n
+}
+
+func attr(name string, proto Validator, rules ...string) *AttributeSpec {
+	return &AttributeSpec{Name: name, Type: reflect.TypeOf(proto).Elem(), Rules: rules}
+}
+
+func (s *AttributeSpec) withHook(hook AttributeHook) *AttributeSpec {
+	s.Hook = hook
+	return s
+}
+
+func category(name string, specs ...*AttributeSpec) []*AttributeSpec {
+	for _, s := range specs {
+		s.Category = name
+	}
+	return specs
+}
+
+// ========== Registered Attributes ==========
+
+var attributeRegistry = newAttributeRegistry(
+	// Full canon (grammar/ORISA_FULL.pest, docs/ATTRIBUTES_CANON.md)
+	category("Core Proof & Witness",
+		attr("proof", &ProofAttr{}, "proof_attr"),
+		attr("witness", &WitnessAttr{}, "witness_attr"),
+		attr("receipt", &ReceiptAttr{}, "receipt_attr"),
+		attr("àṣẹ", &UniversalAse{}, "ase_attr"),
+		attr("telemetry", &TelemetryAttr{}, "telemetry_attr"),
+	),
+	category("SimaaS & AIO Jobs",
+		attr("sim", &SimAttr{}, "sim_attr"),
+		attr("job", &JobAttr{}, "job_attr"),
+		attr("hardware", &HardwareAttr{}, "hardware_attr"),
+		attr("collaboration", &CollaborationAttr{}, "collaboration_attr"),
+		attr("accountability", &AccountabilityAttr{}, "accountability_attr"),
+		attr("offering", &OfferingAttr{}, "offering_attr"),
+		attr("sim_fund", &SimFundAttr{}, "sim_fund_attr"),
+		attr("circuit_breaker", &CircuitBreakerAttr{}, "circuit_breaker_attr"),
+	),
+	category("Herb & Regional",
+		attr("herb", &HerbAttr{}, "herb_attr"),
+		attr("regional", &RegionalAttr{}, "regional_attr"),
+	),
+	category("Governance & Safety",
+		attr("vote", &VoteAttr{}, "vote_attr"),
+		attr("dispute", &DisputeAttr{}, "dispute_attr"),
+		attr("slash", &SlashAttr{}, "slash_attr"),
+		attr("wallet", &WalletAttr{}, "wallet_attr"),
+		attr("identity", &IdentityAttr{}, "identity_attr"),
+		attr("swarm", &SwarmAttr{}, "swarm_attr"),
+		attr("peer", &PeerAttr{}, "peer_attr"),
+		attr("flow_validate", &FlowValidateAttr{}, "flow_validate_attr"),
+	),
+	category("Native VM Extensions",
+		attr("memory", &MemoryAttr{}, "memory_attr"),
+		attr("learn", &LearnAttr{}, "learn_attr"),
+		attr("compute", &ComputeAttr{}, "compute_attr"),
+		attr("verifier", &VerifierAttr{}, "verifier_attr"),
+		attr("multisig", &MultisigAttr{}, "multisig_attr"),
+		attr("policy", &PolicyAttr{}, "policy_attr"),
+		attr("council", &CouncilAttr{}, "council_attr"),
+		attr("beats", &BeatsAttr{}, "beats_attr", "beats_aligned_attr"),
+		attr("maintenance", &MaintenanceAttr{}, "maintenance_attr"),
+		attr("cog", &CogAttr{}, "cog_attr"),
+		attr("npc", &NPCAttr{}, "npc_attr"),
+	),
+	category("Entertainment Sector",
+		attr("project", &ProjectAttr{}, "project_attr"),
+		attr("milestone", &MilestoneAttr{}, "milestone_attr"),
+		attr("casting", &CastingAttr{}, "casting_attr"),
+		attr("callsheet", &CallsheetAttr{}, "callsheet_attr"),
+		attr("release", &ReleaseAttr{}, "release_attr"),
+		attr("license", &LicenseAttr{}, "license_attr"),
+		attr("royalty", &RoyaltyAttr{}, "royalty_attr"),
+		attr("safety", &SafetyAttr{}, "safety_attr"),
+		attr("permit", &PermitAttr{}, "permit_attr"),
+		attr("delivery", &DeliveryAttr{}, "delivery_attr"),
+		attr("compliance", &ComplianceAttr{}, "compliance_attr"),
+		attr("asset_rental", &AssetRentalAttr{}, "asset_rental_attr"),
+		attr("asset_lineage", &AssetLineageAttr{}, "asset_lineage_attr"),
+	),
+	category("Flow/Workflow",
+		attr("flow", &FlowAttr{}, "flow_attr"),
+		attr("retry", &RetryAttr{}, "retry_attr"),
+		attr("saga", &SagaAttr{}, "saga_attr"),
+		attr("ai", &AIAttr{}, "ai_attr"),
+		attr("review", &ReviewAttr{}, "review_attr"),
+		attr("oracle", &OracleAttr{}, "oracle_attr"),
+		attr("presence", &PresenceAttr{}, "presence_attr"),
+	),
+	category("Interop/Anchoring",
+		attr("import", &ImportAttr{}, "import_attr"),
+		attr("abi", &ABIAttr{}, "abi_attr"),
+		attr("bridge", &BridgeAttr{}, "bridge_attr"),
+	),
+	category("Astral-Telluric Grid",
+		attr("astro", &AstroAttr{}, "astro_attr"),
+		attr("ley", &LeyAttr{}, "ley_attr"),
+		attr("nexus", &NexusAttr{}, "nexus_attr"),
+		attr("geofence", &GeofenceAttr{}, "geofence_attr"),
+		attr("boost", &BoostAttr{}, "boost_attr"),
+	),
+	category("SatKey/Ordinals",
+		attr("ordinal_guard", &OrdinalGuardAttr{}, "ordinal_guard_attr"),
+		attr("inscription", &InscriptionAttr{}, "inscription_attr"),
+		attr("satname", &SatnameAttr{}, "satname_attr"),
+		attr("rights", &RightsAttr{}, "rights_attr"),
+		attr("playback", &PlaybackAttr{}, "playback_attr"),
+		attr("psbt_policy", &PSBTPolicyAttr{}, "psbt_policy_attr"),
+		attr("bundle", &BundleAttr{}, "bundle_attr"),
+		attr("manifest", &ManifestAttr{}, "manifest_attr"),
+	),
+	category("ZK & Numerology",
+		attr("zk", &ZKAttr{}, "zk_attr"),
+		attr("zkbind", &ZKBindAttr{}, "zkbind_attr"),
+		attr("veil", &VeilAttr{}, "veil_attr"),
+	),
+	category("Economic/Treasury",
+		attr("tithe", &TitheAttr{}, "tithe_attr"),
+		attr("treasury_split", &TreasurySplitAttr{}, "treasury_split_attr"),
+		attr("shrineSplit", &ShrineSplitAttr{}, "shrine_split_attr"),
+		attr("fixed_math", &FixedMathAttr{}, "fixed_math_attr"),
+		attr("resource", &ResourceAttr{}, "resource_attr"),
+		attr("burst", &BurstAttr{}, "burst_attr"),
+		attr("toc", &TOCAttr{}, "toc_attr"),
+	),
+	category("Governance Extensions",
+		attr("nonreentrant", &NonReentrantAttr{}, "nonreentrant_attr"),
+		attr("requires", &RequiresAttr{}, "requires_attr"),
+		attr("ensures", &EnsuresAttr{}, "ensures_attr"),
+		attr("audit", &AuditAttr{}, "audit_attr"),
+		attr("sabbath", &SabbathAttr{}, "sabbath_attr"),
+		attr("limits", &LimitsAttr{}, "limits_attr"),
+		attr("align", &AlignAttr{}, "align_attr"),
+		attr("temporal", &TemporalAttr{}, "temporal_attr"),
+		attr("trusted_caller", &TrustedCallerAttr{}, "trusted_caller_attr"),
+		attr("sync_guard", &SyncGuardAttr{}, "sync_guard_attr"),
+		attr("gas_limit", &GasLimitAttr{}, "gas_limit_attr"),
+		attr("whitegate", &WhitegateAttr{}, "whitegate_attr"),
+		attr("oracle_bond", &OracleBondAttr{}, "oracle_bond_attr"),
+		attr("sector", &SectorAttr{}, "sector_attr"),
+	),
+
+	// Phase 2 extensions (grammar/ORISA_PHASE2.pest only)
+	category("Governance",
+		attr("quorum", &QuorumAttr{}),
+		attr("consensus", &ConsensusAttr{}),
+		attr("veto", &VetoAttr{}),
+	),
+	category("Economic",
+		attr("fee", &FeeAttr{}),
+		attr("vault", &VaultAttr{}),
+		attr("split", &SplitAttr{}),
+	),
+	category("Device/Hardware",
+		attr("device", &DeviceAttr{}),
+		attr("gps", &GPSAttr{}),
+		attr("imu", &IMUAttr{}),
+		attr("battery", &BatteryAttr{}),
+		attr("sensor", &SensorAttr{}),
+	),
+	category("QR/Scanning",
+		attr("qr", &QRAttr{}),
+		attr("nfc", &NFCAttr{}),
+		attr("rfid", &RFIDAttr{}),
+		attr("barcode", &BarcodeAttr{}),
+	),
+	category("Network/Mesh",
+		attr("lora", &LoRaAttr{}),
+		attr("ble", &BLEAttr{}),
+		attr("mesh", &MeshAttr{}),
+		attr("p2p", &P2PAttr{}),
+	),
+	category("Temporal",
+		attr("timestamp", &TimestampAttr{}),
+		attr("duration", &DurationAttr{}),
+		attr("deadline", &DeadlineAttr{}).withHook(enforceDeadline),
+		attr("schedule", &ScheduleAttr{}),
+	),
+	category("Security",
+		attr("signature", &SignatureAttr{}),
+		attr("encryption", &EncryptionAttr{}),
+		attr("access", &AccessAttr{}),
+		attr("mask", &MaskAttr{}),
+	),
+	category("Environmental",
+		attr("temp", &TempAttr{}),
+		attr("humidity", &HumidityAttr{}),
+		attr("pressure", &PressureAttr{}),
+		attr("light", &LightAttr{}),
+	),
+	category("Media",
+		attr("video", &VideoAttr{}),
+		attr("audio", &AudioAttr{}),
+		attr("image", &ImageAttr{}),
+		attr("stream", &StreamAttr{}),
+	),
+	category("Storage",
+		attr("ipfs", &IPFSAttr{}),
+		attr("arweave", &ArweaveAttr{}),
+		attr("cache", &CacheAttr{}),
+		attr("backup", &BackupAttr{}),
+	),
+	category("Blockchain",
+		attr("bitcoin", &BitcoinAttr{}),
+		attr("ethereum", &EthereumAttr{}),
+		attr("solana", &SolanaAttr{}),
+		attr("sui", &SuiAttr{}),
+	),
+	category("Herb/Resource",
+		attr("inventory", &InventoryAttr{}),
+	),
+	category("Oracle/Divination",
+		attr("divination", &DivinationAttr{}),
+		attr("prediction", &PredictionAttr{}),
+	),
+	category("AI/ML",
+		attr("model", &ModelAttr{}),
+		attr("inference", &InferenceAttr{}),
+		attr("training", &TrainingAttr{}),
+	),
+	category("Logistics",
+		attr("route", &RouteAttr{}),
+		attr("tracking", &TrackingAttr{}),
+		attr("checkpoint", &CheckpointAttr{}),
+	),
+	category("Manufacturing",
+		attr("assembly", &AssemblyAttr{}),
+		attr("quality", &QualityAttr{}),
+		attr("batch", &BatchAttr{}),
+	),
+	category("Identity",
+		attr("did", &DIDAttr{}),
+		attr("credential", &CredentialAttr{}),
+		attr("biometric", &BiometricAttr{}),
+	),
+)
+
+// GetAttributeCount returns the number of registered attributes, the
+// canon and Phase 2 extensions together
+func GetAttributeCount() int {
+	return attributeRegistry.Count()
+}
+
+// GetCanonAttributeCount returns the number of attributes in the canon
+func GetCanonAttributeCount() int {
+	return attributeRegistry.CanonCount()
+}
+
+// ========== Runtime Hooks ==========
+
+func enforceDeadline(vm *VM, attr Validator) error {
+	deadline := attr.(*DeadlineAttr)
+	if deadline.Unix > 0 && vm.Now().Unix() > deadline.Unix {
+		return fmt.Errorf("deadline passed at %s", time.Unix(deadline.Unix, 0).UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// ========== Canon Self-Check ==========
+
+var (
+	pestAttrRule  = regexp.MustCompile(`(?m)^(\w+_attr)\s*=\s*\{\s*"@([^"]+)"(.*)$`)
+	pestParamKey  = regexp.MustCompile(`"(\w+)"\s*~\s*":"`)
+	canonHeading  = regexp.MustCompile(`^###\s+(?:[\d+-]+:\s*)?(.*?)(?:\s*\(.*\))?\s*$`)
+	canonListItem = regexp.MustCompile("^- `@([^\\s(`]+)")
+	pestProofKind = regexp.MustCompile(`(?s)\bproof_(?:kw|kind)\s*=\s*\{(.*?)\}`)
+	pestString    = regexp.MustCompile(`"([^"]+)"`)
+	pestCanonSize = regexp.MustCompile(`FULL CANON - (\d+) Attributes`)
+	docCanonSize  = regexp.MustCompile(`Attribute Canon - (\d+) Sacred Attributes`)
+)
+
+// pestRule - An attribute rule found in a .pest grammar
+type pestRule struct {
+	Attr   string
+	Params []string
+}
+
+func parsePestRules(src string) map[string]pestRule {
+	rules := make(map[string]pestRule)
+	for _, m := range pestAttrRule.FindAllStringSubmatch(src, -1) {
+		rule := pestRule{Attr: m[2]}
+		for _, p := range pestParamKey.FindAllStringSubmatch(m[3], -1) {
+			rule.Params = append(rule.Params, p[1])
+		}
+		rules[m[1]] = rule
+	}
+	return rules
+}
+
+// parseCanonDoc maps each attribute listed in ATTRIBUTES_CANON.md to the
+// sections that list it
+func parseCanonDoc(src string) map[string][]string {
+	listed := make(map[string][]string)
+	section := ""
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if m := canonHeading.FindStringSubmatch(line); m != nil {
+			section = m[1]
+		} else if m := canonListItem.FindStringSubmatch(line); m != nil {
+			listed[m[1]] = append(listed[m[1]], section)
+		}
+	}
+	return listed
+}
+
+// CheckAttributeCanon verifies that the registry, grammar/ORISA_FULL.pest,
+// grammar/ORISA_PHASE2.pest and docs/ATTRIBUTES_CANON.md agree on the
+// attribute set, categories, parameter names and canon size. It reports
+// every mismatch.
+func CheckAttributeCanon() error {
+	full := parsePestRules(grammar.Full)
+	phase2 := parsePestRules(grammar.Phase2)
+	doc := parseCanonDoc(docs.AttributesCanon)
+
+	var problems []string
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	phase2Names := make(map[string]pestRule)
+	for _, rule := range phase2 {
+		phase2Names[rule.Attr] = rule
+	}
+
+	for _, spec := range attributeRegistry.All() {
+		fields := make(map[string]bool)
+		for _, f := range bind.Fields(spec.Type) {
+			fields[f] = true
+		}
+		checkParams := func(source string, rule pestRule) {
+			for _, p := range rule.Params {
+				if !fields[p] {
+					report("@%s: %s parameter %q has no field in %s", spec.Name, source, p, spec.Type.Name())
+				}
+			}
+		}
+
+		if !spec.Canon() {
+			rule, ok := phase2Names[spec.Name]
+			if !ok {
+				report("@%s is registered but defined in neither grammar", spec.Name)
+				continue
+			}
+			checkParams("ORISA_PHASE2.pest", rule)
+			if _, listed := doc[spec.Name]; listed {
+				report("@%s is listed in ATTRIBUTES_CANON.md but has no ORISA_FULL.pest rule", spec.Name)
+			}
+			continue
+		}
+
+		for _, name := range spec.Rules {
+			rule, ok := full[name]
+			switch {
+			case !ok:
+				report("@%s: rule %s not found in ORISA_FULL.pest", spec.Name, name)
+			case rule.Attr != spec.Name:
+				report("@%s: rule %s defines @%s", spec.Name, name, rule.Attr)
+			default:
+				checkParams("ORISA_FULL.pest", rule)
+			}
+		}
+
+		sections, listed := doc[spec.Name]
+		if !listed {
+			report("@%s is not listed in ATTRIBUTES_CANON.md", spec.Name)
+		} else if !containsString(sections, spec.Category) {
+			report("@%s is registered under %q but ATTRIBUTES_CANON.md lists it under %s",
+				spec.Name, spec.Category, strings.Join(sections, ", "))
+		}
+	}
+
+	for _, name := range sortedRuleNames(full) {
+		spec, ok := attributeRegistry.Lookup(full[name].Attr)
+		if !ok {
+			report("ORISA_FULL.pest rule %s (@%s) is not registered", name, full[name].Attr)
+		} else if !containsString(spec.Rules, name) {
+			report("ORISA_FULL.pest rule %s is not registered for @%s", name, spec.Name)
+		}
+	}
+
+	for name := range doc {
+		if _, ok := attributeRegistry.Lookup(name); !ok {
+			report("ATTRIBUTES_CANON.md lists @%s, which is not registered", name)
+		}
+	}
+
+	canon := attributeRegistry.CanonCount()
+	for _, stated := range []struct {
+		source, src string
+		size        *regexp.Regexp
+	}{
+		{"ORISA_FULL.pest", grammar.Full, pestCanonSize},
+		{"ATTRIBUTES_CANON.md", docs.AttributesCanon, docCanonSize},
+	} {
+		if m := stated.size.FindStringSubmatch(stated.src); m == nil {
+			report("%s does not state the canon size", stated.source)
+		} else if m[1] != strconv.Itoa(canon) {
+			report("%s states a canon of %s attributes, but %d are registered", stated.source, m[1], canon)
+		}
+	}
+
+	for source, src := range map[string]string{"ORISA_FULL.pest": grammar.Full, "ORISA_PHASE2.pest": grammar.Phase2} {
+		for _, kind := range parsePestProofKinds(src) {
+			if !proofkind.Known(kind) {
+				report("%s proof kind %q is not in the proof kind registry", source, kind)
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("attribute canon mismatch (%d):\n  %s", len(problems), strings.Join(problems, "\n  "))
+}
+
+// parsePestProofKinds returns the alternatives of a grammar's proof kind rule
+func parsePestProofKinds(src string) []string {
+	m := pestProofKind.FindStringSubmatch(src)
+	if m == nil {
+		return nil
+	}
+	var kinds []string
+	for _, s := range pestString.FindAllStringSubmatch(m[1], -1) {
+		kinds = append(kinds, s[1])
+	}
+	return kinds
+}
+
+func sortedRuleNames(rules map[string]pestRule) []string {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
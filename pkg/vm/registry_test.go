@@ -0,0 +1,63 @@
+package vm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ase-lang/osovm/docs"
+	"github.com/ase-lang/osovm/grammar"
+)
+
+func TestAttributeCanonSelfCheck(t *testing.T) {
+	if err := CheckAttributeCanon(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAttributeCounts(t *testing.T) {
+	if n := GetCanonAttributeCount(); n != 94 {
+		t.Errorf("canon has %d attributes, want 94", n)
+	}
+	if n := GetAttributeCount(); n != 152 {
+		t.Errorf("%d attributes registered, want 152", n)
+	}
+
+	// The canon is exactly what ORISA_FULL.pest defines and the docs list
+	inGrammar := make(map[string]bool)
+	for _, rule := range parsePestRules(grammar.Full) {
+		inGrammar[rule.Attr] = true
+	}
+	if len(inGrammar) != GetCanonAttributeCount() {
+		t.Errorf("ORISA_FULL.pest defines %d attributes", len(inGrammar))
+	}
+	if listed := parseCanonDoc(docs.AttributesCanon); len(listed) != GetCanonAttributeCount() {
+		t.Errorf("ATTRIBUTES_CANON.md lists %d attributes", len(listed))
+	}
+}
+
+func TestEveryAttributeIsRegisteredOnce(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, spec := range attributeRegistry.All() {
+		if seen[spec.Name] {
+			t.Errorf("@%s registered twice", spec.Name)
+		}
+		seen[spec.Name] = true
+
+		if got, ok := attributeRegistry.Lookup(spec.Name); !ok || got != spec {
+			t.Errorf("@%s: lookup returned %v", spec.Name, got)
+		}
+		if v := spec.New(); reflect.TypeOf(v).Elem() != spec.Type {
+			t.Errorf("@%s: New returned %T", spec.Name, v)
+		}
+		if spec.Canon() && spec.Category == "" {
+			t.Errorf("@%s is in the canon without a category", spec.Name)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering an attribute twice did not panic")
+		}
+	}()
+	newAttributeRegistry([]*AttributeSpec{attr("dup", &WitnessAttr{}), attr("dup", &WitnessAttr{})})
+}
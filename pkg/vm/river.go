@@ -0,0 +1,59 @@
+// OSOVM Ọ̀ṣun River
+// The oshun_river precompile: opens payment streams that flow from one
+// account to another over @duration(seconds), and pays out what streams
+// have accrued
+
+package vm
+
+import "fmt"
+
+// Ọ̀ṣun - Flow & Abundance
+func (vm *VM) oshunRiver() error {
+	if id, ok := vm.Context.Ritual.ArgValues["stream"]; ok {
+		n, err := wholeAmount(id)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("oshun_river: stream %s is not a stream ID", formatValue(id))
+		}
+		return vm.flowStream(uint64(n))
+	}
+	return vm.openStream()
+}
+
+// openStream holds the ritual's transfer in its from account and starts
+// paying it to its to account
+func (vm *VM) openStream() error {
+	ctx := vm.Context
+	t, err := vm.ritualTransfer()
+	if err != nil {
+		return fmt.Errorf("oshun_river: %w", err)
+	}
+	if t == nil || t.To == "" {
+		return fmt.Errorf("oshun_river needs an amount, from and to to stream, or the stream arg of an open stream")
+	}
+	duration, ok := ctx.Ritual.Attributes["duration"].(*DurationAttr)
+	if !ok || duration.Seconds <= 0 {
+		return fmt.Errorf("oshun_river needs @duration(seconds) to stream over")
+	}
+
+	s, err := vm.Ledger.OpenStream(ctx.Ritual.Name, t.From, t.To, t.Amount, int64(duration.Seconds))
+	if err != nil {
+		return fmt.Errorf("oshun_river: %w", err)
+	}
+	ctx.Variables["stream"] = int64(s.ID)
+	vm.emit(StreamOpened{Stream: s.ID, From: s.From, To: s.To, Amount: s.Amount, Duration: s.Duration, Hold: s.Hold})
+	return nil
+}
+
+// flowStream pays what stream id has accrued
+func (vm *VM) flowStream(id uint64) error {
+	s, entry, err := vm.Ledger.Flow(vm.Context.Ritual.Name, id)
+	if err != nil {
+		return fmt.Errorf("oshun_river: %w", err)
+	}
+	flowed := StreamFlowed{Stream: s.ID, From: s.From, To: s.To, Paid: s.Paid, Amount: s.Amount, Closed: s.Paid == s.Amount}
+	if entry != nil {
+		flowed.Entry, flowed.Flowed = entry.Seq, entry.Postings[0].Amount
+	}
+	vm.emit(flowed)
+	return nil
+}
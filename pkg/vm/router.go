@@ -0,0 +1,219 @@
+// OSOVM Èṣù Router
+// The eshu_router precompile: routes a ritual's transfer through the
+// ledger and levies the 3.69% tithe to the @tithe wallet
+
+package vm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ase-lang/osovm/pkg/ledger"
+)
+
+// Tithe rate in basis points of BasisPoints
+const (
+	TitheRate   = 369
+	BasisPoints = 10_000
+)
+
+// Tithe returns the tithe owed on amount, rounded up so no positive
+// transfer escapes it. It splits amount at BasisPoints so the product
+// can't overflow: the tithe is exact for every int64 amount.
+func Tithe(amount int64) int64 {
+	if amount <= 0 {
+		return 0
+	}
+	whole, rest := amount/BasisPoints, amount%BasisPoints
+	return whole*TitheRate + (rest*TitheRate+BasisPoints-1)/BasisPoints
+}
+
+// RouteRule - A conditional routing rule. The first rule whose conditions
+// all hold decides where the tithe goes: to Wallet instead of the @tithe
+// wallet, or nowhere if Exempt.
+type RouteRule struct {
+	Name      string            `json:"name"`
+	When      map[string]string `json:"when,omitempty"`       // ritual args that must have these values
+	MinAmount int64             `json:"min_amount,omitempty"` // smallest transfer matched
+	MaxAmount int64             `json:"max_amount,omitempty"` // largest transfer matched; 0 for no limit
+	Wallet    string            `json:"wallet,omitempty"`
+	Exempt    bool              `json:"exempt,omitempty"`
+}
+
+// Check rejects rules that could never route anything
+func (r *RouteRule) Check() error {
+	switch {
+	case r.Name == "":
+		return fmt.Errorf("route rule has no name")
+	case r.Exempt && r.Wallet != "":
+		return fmt.Errorf("route rule %s: an exempt rule routes no tithe, so it takes no wallet", r.Name)
+	case r.MaxAmount != 0 && r.MaxAmount < r.MinAmount:
+		return fmt.Errorf("route rule %s: max_amount %d is below min_amount %d", r.Name, r.MaxAmount, r.MinAmount)
+	}
+	return nil
+}
+
+func (r *RouteRule) matches(t *Transfer, args map[string]string) bool {
+	if t.Amount < r.MinAmount || (r.MaxAmount != 0 && t.Amount > r.MaxAmount) {
+		return false
+	}
+	for name, want := range r.When {
+		got, ok := args[name]
+		if !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Transfer - The value a ritual moves: Amount from the From account to
+// the To account, less the tithe. Without To only the tithe is paid.
+// From is always the proving device's account.
+type Transfer struct {
+	Amount int64
+	From   string
+	To     string
+}
+
+// ritualAmount is the value the ritual moves: its amount arg, or its
+// @offering amount when there is no amount arg. 0 means nothing moves.
+func (vm *VM) ritualAmount() (int64, error) {
+	ritual := vm.Context.Ritual
+	var amount int64
+	if v, ok := ritual.ArgValues["amount"]; ok {
+		n, err := wholeAmount(v)
+		if err != nil {
+			return 0, fmt.Errorf("amount: %w", err)
+		}
+		amount = n
+	} else if offering, ok := ritual.Attributes["offering"].(*OfferingAttr); ok {
+		amount = int64(offering.Amount)
+	}
+	if amount < 0 {
+		return 0, fmt.Errorf("amount must be positive (got %d)", amount)
+	}
+	return amount, nil
+}
+
+// ritualTransfer reads the transfer from the ritual's amount and to args,
+// paid by the proving device. It returns nil for rituals that move nothing.
+func (vm *VM) ritualTransfer() (*Transfer, error) {
+	amount, err := vm.ritualAmount()
+	if err != nil || amount == 0 {
+		return nil, err
+	}
+	from, err := vm.payer()
+	if err != nil {
+		return nil, fmt.Errorf("a transfer of %d: %w", amount, err)
+	}
+	to, _ := vm.Context.Ritual.ArgValues["to"].(string)
+	return &Transfer{Amount: amount, From: from, To: to}, nil
+}
+
+// payer is the account a ritual spends from: that of the device whose
+// proof it validated. The proof's device ID is only the caller's claim
+// until witnesses sign it, so at least one counted witness must have
+// attested that device. Args are unauthenticated, so a from arg may only
+// repeat that account, never name another.
+func (vm *VM) payer() (string, error) {
+	ctx := vm.Context
+	if !ctx.Claimed || ctx.Proof.DeviceID == "" {
+		return "", fmt.Errorf("spending needs a validated Àṣẹ proof from the paying device")
+	}
+	device := ctx.Proof.DeviceID
+	if len(ctx.Counted) == 0 {
+		return "", fmt.Errorf("no witness attested that %s proved this ritual, so it can't pay", device)
+	}
+	if vm.isSystemAccount(device) {
+		return "", fmt.Errorf("%s is a system account, not a device, and can't pay", device)
+	}
+	if v, ok := ctx.Ritual.ArgValues["from"]; ok && v != device {
+		return "", fmt.Errorf("from %s is not the proving device %s", formatValue(v), device)
+	}
+	return device, nil
+}
+
+// isSystemAccount reports whether account is one the VM keeps for itself
+// rather than a device's: equity, which may go negative so paying from it
+// mints, any ritual's vault, and the slash and shrine split accounts.
+func (vm *VM) isSystemAccount(account string) bool {
+	switch account {
+	case ledger.Equity, SlashAccount, vm.Shrines.Shrine, vm.Shrines.Robots, vm.Shrines.Witnesses, vm.Shrines.Treasury:
+		return true
+	}
+	return strings.HasPrefix(account, vaultPrefix)
+}
+
+// wholeAmount converts an arg to a whole number of ledger units
+func wholeAmount(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+			return int64(n), nil
+		}
+	case string:
+		if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%s is not a whole number", formatValue(v))
+}
+
+// Èṣù - Router & Gateway
+func (vm *VM) eshuRouter() error {
+	t, err := vm.ritualTransfer()
+	if err != nil {
+		return fmt.Errorf("eshu_router: %w", err)
+	}
+	if t == nil {
+		return nil
+	}
+	ritual := vm.Context.Ritual
+
+	routed := TitheRouted{Amount: t.Amount, From: t.From, To: t.To, Tithe: Tithe(t.Amount)}
+	if tithe, ok := ritual.Attributes["tithe"].(*TitheAttr); ok {
+		routed.Wallet = tithe.Wallet
+	}
+	for i := range vm.RouteRules {
+		rule := &vm.RouteRules[i]
+		if !rule.matches(t, ritual.Args) {
+			continue
+		}
+		routed.Rule = rule.Name
+		if rule.Exempt {
+			routed.Tithe, routed.Wallet = 0, ""
+		} else if rule.Wallet != "" {
+			routed.Wallet = rule.Wallet
+		}
+		break
+	}
+	if routed.Tithe > 0 && routed.Wallet == "" {
+		return fmt.Errorf("eshu_router: a transfer of %d owes a tithe of %d but names no wallet; add @tithe(rate:%d, wallet:...)",
+			t.Amount, routed.Tithe, TitheRate)
+	}
+
+	var postings []ledger.Posting
+	if routed.Tithe > 0 {
+		postings = append(postings, ledger.Posting{From: t.From, To: routed.Wallet, Amount: routed.Tithe, Memo: "tithe"})
+	}
+	if net := t.Amount - routed.Tithe; t.To != "" && net > 0 {
+		postings = append(postings, ledger.Posting{From: t.From, To: t.To, Amount: net, Memo: "transfer"})
+	}
+	// An exempt transfer with no payee moves nothing
+	if len(postings) > 0 {
+		entry, err := vm.Ledger.Post(ritual.Name, postings...)
+		if err != nil {
+			if routed.Tithe > 0 {
+				return fmt.Errorf("eshu_router: tithe of %d to %s unpaid: %w", routed.Tithe, routed.Wallet, err)
+			}
+			return fmt.Errorf("eshu_router: %w", err)
+		}
+		routed.Entry = entry.Seq
+	}
+	vm.emit(routed)
+	return nil
+}
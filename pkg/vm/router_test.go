@@ -0,0 +1,143 @@
+package vm
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTitheRoundsUp(t *testing.T) {
+	for _, tc := range []struct {
+		amount, tithe int64
+	}{
+		{-5, 0},
+		{0, 0},
+		{1, 1},   // 0.0369
+		{27, 1},  // 0.9963
+		{28, 2},  // 1.0332
+		{100, 4}, // 3.69
+		{10000, 369},
+		{10001, 370},
+		{20000, 738},
+	} {
+		if got := Tithe(tc.amount); got != tc.tithe {
+			t.Errorf("Tithe(%d) = %d, want %d", tc.amount, got, tc.tithe)
+		}
+	}
+
+	// Past amount*TitheRate's range the tithe is still exact
+	for _, amount := range []int64{math.MaxInt64 / TitheRate, math.MaxInt64/TitheRate + 1, 2_500_000_000_000_001, math.MaxInt64 - 1, math.MaxInt64} {
+		want := new(big.Int).Mul(big.NewInt(amount), big.NewInt(TitheRate))
+		want.Add(want, big.NewInt(BasisPoints-1))
+		want.Quo(want, big.NewInt(BasisPoints))
+		if got := Tithe(amount); got != want.Int64() {
+			t.Errorf("Tithe(%d) = %d, want %s", amount, got, want)
+		}
+	}
+}
+
+const payRitual = `{
+	"name": "pay", "orisa": "eshu_router",
+	"ase": {"proof": "telemetry", "witnesses": 1},
+	"attributes": {"tithe": {"rate": 369, "wallet": "treasury"}},
+	"args": {%s}
+}`
+
+func TestEshuRouterPaysFromTheProvingDevice(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	if _, err := vm.Ledger.Deposit("drone_001", 1000, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.Ledger.Deposit("alice", 1000, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := proved(t, vm, "drone_001", strings.Replace(payRitual, "%s", `"amount": 28, "to": "bob"`, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	routed := eventOf(res, EventTitheRouted).(TitheRouted)
+	if routed.From != "drone_001" || routed.Tithe != 2 || vm.Ledger.Balance("treasury") != 2 || vm.Ledger.Balance("bob") != 26 {
+		t.Errorf("routed %+v; balances %v", routed, vm.Ledger.Balances())
+	}
+
+	// A from arg can't spend another account, and no proof spends nothing
+	_, err = proved(t, vm, "drone_001", strings.Replace(payRitual, "%s", `"amount": 28, "from": "alice", "to": "bob"`, 1))
+	if err == nil || !strings.Contains(err.Error(), `from "alice" is not the proving device drone_001`) {
+		t.Errorf("spent alice's funds: %v", err)
+	}
+	_, err = run(t, vm, `{"name": "free", "orisa": "eshu_router", "args": {"amount": 28, "from": "drone_001"}}`)
+	if err == nil || !strings.Contains(err.Error(), "needs a validated Àṣẹ proof") {
+		t.Errorf("spent without a proof: %v", err)
+	}
+	if vm.Ledger.Balance("alice") != 1000 || vm.Ledger.Balance("drone_001") != 972 || len(vm.Ledger.Journal()) != 3 {
+		t.Errorf("balances %v", vm.Ledger.Balances())
+	}
+
+	// The payer covers the whole transfer or none of it is posted
+	_, err = proved(t, vm, "drone_001", strings.Replace(payRitual, "%s", `"amount": 10000, "to": "bob"`, 1))
+	if err == nil || !strings.Contains(err.Error(), "tithe of 369 to treasury unpaid") {
+		t.Errorf("overdrew drone_001: %v", err)
+	}
+	var sum int64
+	for _, b := range vm.Ledger.Balances() {
+		sum += b
+	}
+	if sum != 0 || vm.Ledger.Balance("drone_001") != 972 {
+		t.Errorf("balances %v", vm.Ledger.Balances())
+	}
+}
+
+func TestEshuRouterRefusesUnattestedPayers(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	if _, err := vm.Ledger.Deposit("drone_001", 1000, ""); err != nil {
+		t.Fatal(err)
+	}
+	before, journal := vm.Ledger.Balances(), len(vm.Ledger.Journal())
+	unchanged := func(what string) {
+		t.Helper()
+		after := vm.Ledger.Balances()
+		if len(after) != len(before) || after["drone_001"] != 1000 || after["equity"] != -1000 || len(vm.Ledger.Journal()) != journal {
+			t.Errorf("%s changed the ledger: %v", what, after)
+		}
+	}
+
+	// Witnesses willing to sign for "equity" still can't make it pay
+	res, err := proved(t, vm, "equity", strings.Replace(payRitual, "%s", `"amount": 1000000, "to": "attacker"`, 1))
+	if err == nil || !strings.Contains(err.Error(), "equity is a system account") || res.Status != StatusFailed {
+		t.Errorf("equity paid: %v", err)
+	}
+	unchanged("paying from equity")
+
+	// Attestations for one device don't let another spend
+	if err := vm.LoadSource("pay.oso", []byte(strings.Replace(payRitual, "%s", `"amount": 28, "to": "attacker"`, 1))); err != nil {
+		t.Fatal(err)
+	}
+	proof := &Proof{Type: "telemetry", Receipt: strings.Repeat("cd", 32), Timestamp: now.Unix(), DeviceID: "drone_002"}
+	witnesses, err := vm.Mesh.Broadcast("pay", proof, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stolen := *proof
+	stolen.DeviceID = "drone_001"
+	if _, err := vm.Execute("pay", &stolen, witnesses); err == nil || !strings.Contains(err.Error(), "insufficient witnesses") {
+		t.Errorf("reused another device's witnesses: %v", err)
+	}
+	unchanged("reusing witnesses")
+
+	// Nor can a proof nobody witnessed
+	_, err = proved(t, vm, "drone_001", `{
+		"name": "alone", "orisa": "eshu_router",
+		"ase": {"proof": "telemetry", "witnesses": 0},
+		"attributes": {"tithe": {"rate": 369, "wallet": "treasury"}},
+		"args": {"amount": 28, "to": "attacker"}
+	}`)
+	if err == nil || !strings.Contains(err.Error(), "no witness attested that drone_001 proved this ritual") {
+		t.Errorf("unwitnessed device paid: %v", err)
+	}
+	unchanged("an unwitnessed proof")
+}
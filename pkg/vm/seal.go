@@ -0,0 +1,64 @@
+// OSOVM Receipt Sealing
+// Turns a successful execution into a signed receipt on the operator's
+// hash chain
+
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ase-lang/osovm/pkg/receipt"
+)
+
+// sealReceipt builds the execution's receipt, signs it with the operator
+// key and appends it to the receipt log
+func (vm *VM) sealReceipt() error {
+	if vm.Operator == nil || vm.ReceiptLog == nil {
+		return fmt.Errorf("no operator key or receipt log configured")
+	}
+	ctx := vm.Context
+	p := ctx.Ritual.Program
+
+	inputs, err := json.Marshal(ctx.redact(p.Args))
+	if err != nil {
+		return fmt.Errorf("encode inputs: %w", err)
+	}
+	result, err := json.Marshal(ctx.redact(ctx.Return))
+	if err != nil {
+		return fmt.Errorf("encode result: %w", err)
+	}
+
+	seq, prev := vm.ReceiptLog.Head()
+	r := &receipt.Receipt{
+		Version:          receipt.Version,
+		Seq:              seq + 1,
+		Ritual:           ctx.Ritual.Name,
+		ProgramHash:      p.Hash(),
+		Inputs:           inputs,
+		Witnesses:        make([]receipt.WitnessRef, len(ctx.Counted)),
+		Result:           result,
+		ExecutionReceipt: ctx.Receipt,
+		GasUsed:          ctx.Gas.Used,
+		Kind:             "simple",
+		Timestamp:        vm.Now().Unix(),
+		Prev:             prev,
+	}
+	if ctx.Proof != nil {
+		r.ProofType, r.ProofReceipt, r.Payer = string(ctx.Proof.Type), ctx.Proof.Receipt, ctx.Proof.DeviceID
+	}
+	for i, w := range ctx.Counted {
+		r.Witnesses[i] = receipt.WitnessRef{DeviceID: w.DeviceID, Timestamp: w.Timestamp, Network: w.Network, Signature: w.Signature}
+	}
+	if attr, ok := ctx.Ritual.Attributes["receipt"].(*ReceiptAttr); ok {
+		r.Kind, r.Anchor, r.Pointer, r.Verifiers = attr.Kind, attr.Hash, attr.Pointer, attr.Verifiers
+	}
+
+	r.Sign(vm.Operator)
+	if err := vm.ReceiptLog.Append(r); err != nil {
+		return err
+	}
+	ctx.Sealed = r
+	vm.emit(ReceiptSealed{Seq: r.Seq, Hash: r.Hash, Prev: r.Prev, Operator: r.Operator})
+	return nil
+}
@@ -0,0 +1,218 @@
+package vm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// execute runs a JSON ritual with the given args and statements in a
+// fresh VM that caps loops at 10 iterations
+func execute(t *testing.T, args, statements string) (*ExecutionResult, error) {
+	t.Helper()
+	vm := NewVM()
+	vm.Sinks = nil
+	vm.MaxLoopIterations = 10
+	source := `{"name": "stmts", "orisa": "eshu_router", "args": {` + args + `}, "statements": [` + statements + `]}`
+	if err := vm.LoadSource("stmts.oso", []byte(source)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return vm.Execute("stmts", nil, nil)
+}
+
+func TestIfElse(t *testing.T) {
+	// An if in the else branch makes an else-if chain
+	const classify = `{"type": "if", "data": {
+		"cond": "n > 100",
+		"then": [{"type": "return", "data": {"value": "big"}}],
+		"else": [{"type": "if", "data": {
+			"cond": "n > 10",
+			"then": [{"type": "return", "data": {"value": "medium"}}],
+			"else": [{"type": "assign", "data": {"variable": "kind", "value": "small"}}]
+		}}]
+	}},
+	{"type": "return", "data": {"expr": "kind + \"!\""}}`
+
+	for _, tc := range []struct {
+		n, want string
+	}{
+		{"500", "big"},
+		{"101", "big"},
+		{"100", "medium"},
+		{"11", "medium"},
+		{"10", "small!"},
+		{"-3", "small!"},
+	} {
+		res, err := execute(t, `"n": `+tc.n, classify)
+		if err != nil {
+			t.Fatalf("n=%s: %v", tc.n, err)
+		}
+		if res.Return != tc.want {
+			t.Errorf("n=%s returned %v, want %s", tc.n, res.Return, tc.want)
+		}
+	}
+
+	// An if without else does nothing when its condition is false
+	res, err := execute(t, `"n": 1`, `
+		{"type": "assign", "data": {"variable": "x", "value": 1}},
+		{"type": "if", "data": {"cond": "n > 1", "then": [{"type": "assign", "data": {"variable": "x", "value": 2}}]}},
+		{"type": "return", "data": {"expr": "x"}}`)
+	if err != nil || res.Return != int64(1) {
+		t.Errorf("if without else: %v, %v", res.Return, err)
+	}
+
+	// Conditions must be booleans
+	if _, err := execute(t, `"n": 1`, `{"type": "if", "data": {"cond": "n", "then": []}}`); err == nil || !strings.Contains(err.Error(), "must be a boolean, got int") {
+		t.Errorf("integer condition: %v", err)
+	}
+}
+
+func TestLoops(t *testing.T) {
+	for _, tc := range []struct {
+		name, args, statements string
+		want                   interface{}
+		err                    string
+	}{
+		{
+			name: "repeat with index",
+			args: `"n": 5`,
+			statements: `{"type": "assign", "data": {"variable": "sum", "value": 0}},
+				{"type": "repeat", "data": {"times": "n", "var": "i", "body": [
+					{"type": "assign", "data": {"variable": "sum", "expr": "sum + i"}}
+				]}},
+				{"type": "return", "data": {"expr": "sum"}}`,
+			want: int64(10),
+		},
+		{
+			name: "repeat zero times",
+			statements: `{"type": "assign", "data": {"variable": "ran", "value": false}},
+				{"type": "repeat", "data": {"times": "0", "body": [{"type": "assign", "data": {"variable": "ran", "value": true}}]}},
+				{"type": "return", "data": {"expr": "ran"}}`,
+			want: false,
+		},
+		{
+			name: "repeat at the limit",
+			statements: `{"type": "assign", "data": {"variable": "count", "value": 0}},
+				{"type": "repeat", "data": {"times": "10", "body": [{"type": "assign", "data": {"variable": "count", "expr": "count + 1"}}]}},
+				{"type": "return", "data": {"expr": "count"}}`,
+			want: int64(10),
+		},
+		{
+			name:       "repeat over the limit",
+			statements: `{"type": "repeat", "data": {"times": "11", "body": []}}`,
+			err:        "repeat count 11 exceeds the loop limit of 10",
+		},
+		{
+			name:       "negative repeat",
+			args:       `"n": -1`,
+			statements: `{"type": "repeat", "data": {"times": "n", "body": []}}`,
+			err:        "repeat count cannot be negative (-1)",
+		},
+		{
+			name:       "repeat count not an integer",
+			statements: `{"type": "repeat", "data": {"times": "\"3\"", "body": []}}`,
+			err:        "repeat count must be an integer, got string",
+		},
+		{
+			name: "nested repeat",
+			statements: `{"type": "assign", "data": {"variable": "cells", "value": 0}},
+				{"type": "repeat", "data": {"times": "3", "body": [
+					{"type": "repeat", "data": {"times": "4", "body": [{"type": "assign", "data": {"variable": "cells", "expr": "cells + 1"}}]}}
+				]}},
+				{"type": "return", "data": {"expr": "cells"}}`,
+			want: int64(12),
+		},
+		{
+			name: "while",
+			statements: `{"type": "assign", "data": {"variable": "x", "value": 1}},
+				{"type": "while", "data": {"cond": "x < 100", "max": 10, "body": [{"type": "assign", "data": {"variable": "x", "expr": "x * 3"}}]}},
+				{"type": "return", "data": {"expr": "x"}}`,
+			want: int64(243),
+		},
+		{
+			name: "while finishing on its last iteration",
+			statements: `{"type": "assign", "data": {"variable": "x", "value": 0}},
+				{"type": "while", "data": {"cond": "x < 3", "max": 3, "body": [{"type": "assign", "data": {"variable": "x", "expr": "x + 1"}}]}},
+				{"type": "return", "data": {"expr": "x"}}`,
+			want: int64(3),
+		},
+		{
+			name: "while over its max",
+			statements: `{"type": "assign", "data": {"variable": "x", "value": 0}},
+				{"type": "while", "data": {"cond": "x < 4", "max": 3, "body": [{"type": "assign", "data": {"variable": "x", "expr": "x + 1"}}]}}`,
+			err: "while loop did not finish within 3 iterations",
+		},
+		{
+			name:       "endless while",
+			statements: `{"type": "while", "data": {"cond": "true", "max": 5, "body": []}}`,
+			err:        "while loop did not finish within 5 iterations",
+		},
+	} {
+		res, err := execute(t, tc.args, tc.statements)
+		if tc.err != "" {
+			var rt *RuntimeError
+			if err == nil || !strings.Contains(err.Error(), tc.err) || !errors.As(err, &rt) {
+				t.Errorf("%s: got %v, want %q", tc.name, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		} else if res.Return != tc.want {
+			t.Errorf("%s: returned %v (%T), want %v", tc.name, res.Return, res.Return, tc.want)
+		}
+	}
+}
+
+func TestReturn(t *testing.T) {
+	// Returning from inside a loop ends the ritual there
+	res, err := execute(t, "", `
+		{"type": "assign", "data": {"variable": "i", "value": 0}},
+		{"type": "while", "data": {"cond": "true", "max": 10, "body": [
+			{"type": "assign", "data": {"variable": "i", "expr": "i + 1"}},
+			{"type": "if", "data": {"cond": "i == 4", "then": [{"type": "return", "data": {"expr": "i * 10"}}]}}
+		]}},
+		{"type": "assign", "data": {"variable": "after", "value": true}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Return != int64(40) || res.Variables["after"] != nil || res.Variables["i"] != int64(4) {
+		t.Errorf("returned %v with variables %v", res.Return, res.Variables)
+	}
+
+	for _, tc := range []struct {
+		name, statements string
+		want             interface{}
+	}{
+		{"nothing", `{"type": "assign", "data": {"variable": "x", "value": 1}}`, nil},
+		{"integer", `{"type": "return", "data": {"value": 7}}`, int64(7)},
+		{"float", `{"type": "return", "data": {"expr": "7 / 2.0"}}`, 3.5},
+		{"integer division", `{"type": "return", "data": {"expr": "7 / 2"}}`, int64(3)},
+		{"string", `{"type": "return", "data": {"expr": "upper(name) + \"-\" + str(len(name))"}}`, "ADE-3"},
+		{"boolean", `{"type": "return", "data": {"expr": "starts_with(name, \"a\") && !contains(name, \"z\")"}}`, true},
+		{"first of two", `{"type": "return", "data": {"value": "first"}}, {"type": "return", "data": {"value": "second"}}`, "first"},
+	} {
+		res, err := execute(t, `"name": "ade"`, tc.statements)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		} else if res.Return != tc.want {
+			t.Errorf("%s: returned %v (%T), want %v", tc.name, res.Return, res.Return, tc.want)
+		}
+	}
+
+	// Runtime errors fail the ritual instead of panicking
+	for _, tc := range []struct {
+		expr, err string
+	}{
+		{"1 / 0", "division by zero"},
+		{"missing + 1", `undefined variable "missing"`},
+		{"name - 1", "cannot apply - to string and int"},
+		{"9223372036854775807 + 1", "integer overflow"},
+	} {
+		_, err := execute(t, `"name": "ade"`, `{"type": "return", "data": {"expr": "`+tc.expr+`"}}`)
+		var rt *RuntimeError
+		if !errors.As(err, &rt) || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", tc.expr, err, tc.err)
+		}
+	}
+}
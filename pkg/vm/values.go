@@ -0,0 +1,380 @@
+// OSOVM Runtime Values
+// Values are int64, float64, string, bool, nil, []interface{} or
+// map[string]interface{}. Operators and builtins over them are shared by
+// the compiler's checks and the stack machine.
+
+package vm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ========== Operators ==========
+
+func unaryOp(op string, x interface{}) (interface{}, error) {
+	switch op {
+	case "!":
+		if b, ok := x.(bool); ok {
+			return !b, nil
+		}
+	case "-":
+		switch n := x.(type) {
+		case int64:
+			if n == math.MinInt64 {
+				return nil, fmt.Errorf("integer overflow")
+			}
+			return -n, nil
+		case float64:
+			return -n, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot apply %s to %s", op, typeName(x))
+}
+
+func binaryOp(op string, x, y interface{}) (interface{}, error) {
+	switch op {
+	case "==":
+		return valuesEqual(x, y), nil
+	case "!=":
+		return !valuesEqual(x, y), nil
+	}
+
+	// String concatenation and ordering
+	if xs, ok := x.(string); ok {
+		if ys, ok := y.(string); ok {
+			switch op {
+			case "+":
+				return xs + ys, nil
+			case "<":
+				return xs < ys, nil
+			case "<=":
+				return xs <= ys, nil
+			case ">":
+				return xs > ys, nil
+			case ">=":
+				return xs >= ys, nil
+			}
+		}
+		return nil, fmt.Errorf("cannot apply %s to %s and %s", op, typeName(x), typeName(y))
+	}
+
+	// Integer arithmetic stays integral; anything with a float is float
+	xi, xInt := x.(int64)
+	yi, yInt := y.(int64)
+	if xInt && yInt {
+		return intOp(op, xi, yi)
+	}
+	xf, xNum := toFloat(x)
+	yf, yNum := toFloat(y)
+	if !xNum || !yNum {
+		return nil, fmt.Errorf("cannot apply %s to %s and %s", op, typeName(x), typeName(y))
+	}
+	return floatOp(op, xf, yf)
+}
+
+func intOp(op string, x, y int64) (interface{}, error) {
+	switch op {
+	case "+":
+		if r := x + y; (r > x) == (y > 0) {
+			return r, nil
+		}
+		return nil, fmt.Errorf("integer overflow")
+	case "-":
+		if r := x - y; (r < x) == (y > 0) {
+			return r, nil
+		}
+		return nil, fmt.Errorf("integer overflow")
+	case "*":
+		if x == 0 || y == 0 {
+			return int64(0), nil
+		}
+		r := x * y
+		if r/y != x || (x == -1 && y == math.MinInt64) || (y == -1 && x == math.MinInt64) {
+			return nil, fmt.Errorf("integer overflow")
+		}
+		return r, nil
+	case "/", "%":
+		if y == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		if x == math.MinInt64 && y == -1 {
+			return nil, fmt.Errorf("integer overflow")
+		}
+		if op == "/" {
+			return x / y, nil
+		}
+		return x % y, nil
+	case "<":
+		return x < y, nil
+	case "<=":
+		return x <= y, nil
+	case ">":
+		return x > y, nil
+	case ">=":
+		return x >= y, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+func floatOp(op string, x, y float64) (interface{}, error) {
+	switch op {
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	case "/":
+		if y == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return x / y, nil
+	case "%":
+		if y == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(x, y), nil
+	case "<":
+		return x < y, nil
+	case "<=":
+		return x <= y, nil
+	case ">":
+		return x > y, nil
+	case ">=":
+		return x >= y, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// valuesEqual compares by value; integers and floats compare numerically
+func valuesEqual(x, y interface{}) bool {
+	if xf, ok := toFloat(x); ok {
+		yf, ok := toFloat(y)
+		return ok && xf == yf
+	}
+	switch xv := x.(type) {
+	case []interface{}:
+		yv, ok := y.([]interface{})
+		if !ok || len(xv) != len(yv) {
+			return false
+		}
+		for i := range xv {
+			if !valuesEqual(xv[i], yv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		yv, ok := y.(map[string]interface{})
+		if !ok || len(xv) != len(yv) {
+			return false
+		}
+		for k, v := range xv {
+			if w, ok := yv[k]; !ok || !valuesEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return x == y
+}
+
+// typeName names a runtime value's type in error messages
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case int64:
+		return "integer"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// formatValue renders a value for console output; strings are quoted
+func formatValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(x)
+	case []interface{}:
+		parts := make([]string, len(x))
+		for i, item := range x {
+			parts[i] = formatValue(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = strconv.Quote(k) + ": " + formatValue(x[k])
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	}
+	return fmt.Sprint(v)
+}
+
+// ========== Builtins ==========
+
+// builtin - A pure function callable from expressions. Max < 0 means
+// variadic.
+type builtin struct {
+	Min, Max int
+	Call     func(args []interface{}) (interface{}, error)
+}
+
+func (b builtin) checkArity(n int) error {
+	switch {
+	case b.Min == b.Max && n != b.Min:
+		return fmt.Errorf("takes %d argument(s), got %d", b.Min, n)
+	case n < b.Min:
+		return fmt.Errorf("takes at least %d argument(s), got %d", b.Min, n)
+	case b.Max >= 0 && n > b.Max:
+		return fmt.Errorf("takes at most %d argument(s), got %d", b.Max, n)
+	}
+	return nil
+}
+
+var builtins = map[string]builtin{
+	"len":         {1, 1, builtinLen},
+	"upper":       {1, 1, stringFunc(strings.ToUpper)},
+	"lower":       {1, 1, stringFunc(strings.ToLower)},
+	"trim":        {1, 1, stringFunc(strings.TrimSpace)},
+	"contains":    {2, 2, stringPredicate(strings.Contains)},
+	"starts_with": {2, 2, stringPredicate(strings.HasPrefix)},
+	"ends_with":   {2, 2, stringPredicate(strings.HasSuffix)},
+	"str":         {1, 1, builtinStr},
+	"int":         {1, 1, builtinInt},
+	"float":       {1, 1, builtinFloat},
+	"min":         {1, -1, extremum(func(a, b float64) bool { return a < b })},
+	"max":         {1, -1, extremum(func(a, b float64) bool { return a > b })},
+}
+
+func stringFunc(f func(string) string) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("expects a string, got %s", typeName(args[0]))
+		}
+		return f(s), nil
+	}
+}
+
+func stringPredicate(f func(s, sub string) bool) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, ok1 := args[0].(string)
+		sub, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("expects two strings, got %s and %s", typeName(args[0]), typeName(args[1]))
+		}
+		return f(s, sub), nil
+	}
+}
+
+func builtinLen(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case string:
+		return int64(len([]rune(v))), nil
+	case []interface{}:
+		return int64(len(v)), nil
+	case map[string]interface{}:
+		return int64(len(v)), nil
+	}
+	return nil, fmt.Errorf("expects a string, list or object, got %s", typeName(args[0]))
+}
+
+func builtinStr(args []interface{}) (interface{}, error) {
+	if s, ok := args[0].(string); ok {
+		return s, nil
+	}
+	return formatValue(args[0]), nil
+}
+
+func builtinInt(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64:
+		return v, nil
+	case float64:
+		if math.IsNaN(v) || v >= math.MaxInt64 || v < math.MinInt64 {
+			return nil, fmt.Errorf("%v is out of integer range", v)
+		}
+		return int64(v), nil
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", v)
+		}
+		return n, nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to integer", typeName(args[0]))
+}
+
+func builtinFloat(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", v)
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to float", typeName(args[0]))
+}
+
+// extremum picks the argument that wins better, keeping its type
+func extremum(better func(a, b float64) bool) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		best := args[0]
+		bestF, ok := toFloat(best)
+		if !ok {
+			return nil, fmt.Errorf("expects numbers, got %s", typeName(best))
+		}
+		for _, a := range args[1:] {
+			f, ok := toFloat(a)
+			if !ok {
+				return nil, fmt.Errorf("expects numbers, got %s", typeName(a))
+			}
+			if better(f, bestF) {
+				best, bestF = a, f
+			}
+		}
+		return best, nil
+	}
+}
@@ -0,0 +1,167 @@
+// OSOVM Ṣàngó Vault
+// The sango_vault precompile: funds the ritual's vault from the proving
+// device, applies @slash penalties and pays the TechGnØŞ 50/25/15/10
+// shrine split
+
+package vm
+
+import (
+	"fmt"
+
+	"github.com/ase-lang/osovm/pkg/ledger"
+)
+
+// SlashAccount receives @slash penalties
+const SlashAccount = "slashed"
+
+// ShrineShares - The accounts paid each part of the 50/25/15/10 split
+type ShrineShares struct {
+	Shrine    string // 50%, unless @offering names the shrine
+	Robots    string // 25%, funding the Òrìṣà ROBOTS
+	Witnesses string // 15%, for the witnesses that confirm rituals
+	Treasury  string // 10%
+}
+
+// DefaultShrineShares is used by NewVM
+var DefaultShrineShares = ShrineShares{
+	Shrine:    "shrine",
+	Robots:    "orisa_robots",
+	Witnesses: "witness_pool",
+	Treasury:  "tech_gnosis_treasury",
+}
+
+// shrinePercents are the shares of the split, in ShrineShares order
+var shrinePercents = [4]int64{50, 25, 15, 10}
+
+// shrineSplit divides amount 50/25/15/10. Each share is taken from the
+// hundreds and the rest separately so no product overflows. Rounding
+// remainders go to the first share so the parts sum to amount.
+func shrineSplit(amount int64) [4]int64 {
+	var shares [4]int64
+	var paid int64
+	for i, p := range shrinePercents {
+		shares[i] = amount/100*p + amount%100*p/100
+		paid += shares[i]
+	}
+	shares[0] += amount - paid
+	return shares
+}
+
+// vaultPrefix starts every vault account
+const vaultPrefix = "vault:"
+
+// vaultAccount is the ritual's vault. It is named after the ritual's ID,
+// so no other ritual, nor a ritual of the same name in another program,
+// can spend from it.
+func (vm *VM) vaultAccount() string {
+	return vaultPrefix + vm.Context.Ritual.ID()
+}
+
+// Ṣàngó - Vault & Penalties
+func (vm *VM) sangoVault() error {
+	ritual := vm.Context.Ritual
+	vault := vm.vaultAccount()
+	settled := VaultSettled{Vault: vault}
+
+	// 1. A new vault opens with its @vault balance, paid in by the proving
+	// device: a vault is never funded out of thin air
+	if attr, ok := ritual.Attributes["vault"].(*VaultAttr); ok && attr.Balance > 0 && !vm.Ledger.Opened(vault) {
+		balance, err := attr.Units()
+		if err != nil {
+			return fmt.Errorf("sango_vault: %w", err)
+		}
+		payer, err := vm.payer()
+		if err != nil {
+			return fmt.Errorf("sango_vault: opening %s with %d: %w", vault, balance, err)
+		}
+		opening := ledger.Posting{From: payer, To: vault, Amount: balance, Memo: "opening balance"}
+		if _, err := vm.Ledger.Post(ritual.Name, opening); err != nil {
+			return fmt.Errorf("sango_vault: %w", err)
+		}
+		settled.Opened, settled.Funder = opening.Amount, payer
+	}
+
+	// 2. Penalties and the split are paid by the proving device when the
+	// ritual names it as from, or else by the vault
+	source := vault
+	if _, ok := ritual.ArgValues["from"]; ok {
+		payer, err := vm.payer()
+		if err != nil {
+			return fmt.Errorf("sango_vault: %w", err)
+		}
+		source = payer
+	}
+	var postings []ledger.Posting
+	if slash, ok := ritual.Attributes["slash"].(*SlashAttr); ok {
+		postings = append(postings, ledger.Posting{From: source, To: SlashAccount, Amount: int64(slash.Amount), Memo: "slash: " + slash.Reason})
+		settled.Slashed, settled.Reason = int64(slash.Amount), slash.Reason
+	}
+	if _, ok := ritual.Attributes["shrineSplit"]; ok {
+		total, err := vm.ritualAmount()
+		if err != nil {
+			return fmt.Errorf("sango_vault: %w", err)
+		}
+		if total == 0 {
+			return fmt.Errorf("sango_vault: @shrineSplit needs an amount arg or an @offering")
+		}
+		shares := vm.Shrines
+		if offering, ok := ritual.Attributes["offering"].(*OfferingAttr); ok && offering.Shrine != "" {
+			shares.Shrine = offering.Shrine
+		}
+		accounts := [4]string{shares.Shrine, shares.Robots, shares.Witnesses, shares.Treasury}
+		for i, amount := range shrineSplit(total) {
+			settled.Split = append(settled.Split, Share{Account: accounts[i], Amount: amount})
+			if amount > 0 {
+				postings = append(postings, ledger.Posting{From: source, To: accounts[i], Amount: amount, Memo: "shrine split"})
+			}
+		}
+	}
+	settled.Source = source
+
+	// 3. All of it is one journal entry
+	if len(postings) > 0 {
+		entry, err := vm.Ledger.Post(ritual.Name, postings...)
+		if err != nil {
+			return fmt.Errorf("sango_vault: %w", err)
+		}
+		settled.Entry = entry.Seq
+	}
+	settled.Balance = vm.Ledger.Balance(vault)
+	vm.emit(settled)
+	return nil
+}
+
+// ========== Holds ==========
+
+// holdFunds sets amount aside in account (default: the ritual's vault).
+// A ritual may only hold its own vault or the proving device's account.
+func (vm *VM) holdFunds(amount int64, account string) (uint64, error) {
+	if account == "" {
+		account = vm.vaultAccount()
+	}
+	if account != vm.vaultAccount() {
+		payer, err := vm.payer()
+		if err != nil {
+			return 0, fmt.Errorf("holding funds in %s: %w", account, err)
+		}
+		if account != payer {
+			return 0, fmt.Errorf("cannot hold funds in %s: it is neither this ritual's vault nor the proving device's account", account)
+		}
+	}
+	h, err := vm.Ledger.Hold(vm.Context.Ritual.ID(), account, amount, "held by "+vm.Context.Ritual.Name)
+	if err != nil {
+		return 0, err
+	}
+	vm.emit(FundsHeld{Hold: h.ID, Account: h.Account, Amount: h.Amount})
+	return h.ID, nil
+}
+
+// releaseFunds frees a hold the executing ritual placed, in this program
+func (vm *VM) releaseFunds(id uint64) error {
+	h, err := vm.Ledger.ReleaseFor(vm.Context.Ritual.ID(), id)
+	if err != nil {
+		return err
+	}
+	vm.emit(FundsReleased{Hold: h.ID, Account: h.Account, Amount: h.Amount})
+	return nil
+}
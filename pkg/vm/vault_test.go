@@ -0,0 +1,247 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShrineSplitRemainders(t *testing.T) {
+	for _, tc := range []struct {
+		amount int64
+		shares [4]int64
+	}{
+		{1, [4]int64{1, 0, 0, 0}},
+		{3, [4]int64{3, 0, 0, 0}},
+		{7, [4]int64{5, 1, 1, 0}},
+		{99, [4]int64{52, 24, 14, 9}},
+		{100, [4]int64{50, 25, 15, 10}},
+		{369, [4]int64{186, 92, 55, 36}},
+	} {
+		if got := shrineSplit(tc.amount); got != tc.shares {
+			t.Errorf("shrineSplit(%d) = %v, want %v", tc.amount, got, tc.shares)
+		}
+	}
+
+	// Amounts whose product with a percent overflows split exactly too
+	for _, amount := range []int64{math.MaxInt64 / 50, math.MaxInt64/50 + 1, math.MaxInt64 - 1, math.MaxInt64} {
+		got := shrineSplit(amount)
+		rest := amount
+		for i := 1; i < 4; i++ {
+			want := new(big.Int).Mul(big.NewInt(amount), big.NewInt(shrinePercents[i]))
+			want.Quo(want, big.NewInt(100))
+			if got[i] != want.Int64() {
+				t.Errorf("shrineSplit(%d)[%d] = %d, want %s", amount, i, got[i], want)
+			}
+			rest -= got[i]
+		}
+		if got[0] != rest || got[0] < amount/2 {
+			t.Errorf("shrineSplit(%d)[0] = %d, want %d", amount, got[0], rest)
+		}
+	}
+}
+
+const vaultRitual = `{
+	"name": "%s", "orisa": "sango_vault",
+	"ase": {"proof": "telemetry", "witnesses": 1},
+	"attributes": {%s},
+	"args": {%s},
+	"statements": [%s]
+}`
+
+func vaultSource(name, attrs, args, statements string) string {
+	return fmt.Sprintf(vaultRitual, name, attrs, args, statements)
+}
+
+func TestSangoVaultIsFundedByTheProvingDevice(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+
+	// Without a proof @vault can't open the vault, let alone mint it
+	_, err := run(t, vm, `{"name": "mint", "orisa": "sango_vault", "attributes": {"vault": {"balance": 500}}}`)
+	if err == nil || !strings.Contains(err.Error(), "needs a validated Àṣẹ proof") {
+		t.Errorf("opened without a proof: %v", err)
+	}
+	if len(vm.Ledger.Journal()) != 0 {
+		t.Fatalf("minted %v", vm.Ledger.Balances())
+	}
+
+	// With one the device pays the balance in, once
+	if _, err := vm.Ledger.Deposit("drone_001", 1000, ""); err != nil {
+		t.Fatal(err)
+	}
+	v := func(device, args string) (*ExecutionResult, error) {
+		return proved(t, vm, device, vaultSource("v", `"vault": {"balance": 500}, "slash": {"amount": 40, "reason": "late"}`, args, ""))
+	}
+	var vault string
+	for i := 0; i < 2; i++ {
+		res, err := v("drone_001", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		settled := eventOf(res, EventVaultSettled).(VaultSettled)
+		if i == 0 && (settled.Opened != 500 || settled.Funder != "drone_001") || i == 1 && settled.Opened != 0 {
+			t.Errorf("run %d: %+v", i+1, settled)
+		}
+		vault = settled.Vault
+	}
+	if !strings.HasPrefix(vault, "vault:v@") || vm.Ledger.Balance(vault) != 420 || vm.Ledger.Balance("drone_001") != 500 || vm.Ledger.Balance("equity") != -1000 {
+		t.Errorf("balances %v", vm.Ledger.Balances())
+	}
+
+	// Penalties come out of the vault, or the device's account named as
+	// from, never anyone else's
+	if _, err := vm.Ledger.Deposit("alice", 1000, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = v("drone_001", `"from": "alice"`); err == nil || !strings.Contains(err.Error(), `from "alice" is not the proving device drone_001`) {
+		t.Errorf("slashed alice: %v", err)
+	}
+	if _, err := v("drone_001", `"from": "drone_001"`); err != nil {
+		t.Fatal(err)
+	}
+	if vm.Ledger.Balance(vault) != 420 || vm.Ledger.Balance("drone_001") != 460 || vm.Ledger.Balance("alice") != 1000 {
+		t.Errorf("balances %v", vm.Ledger.Balances())
+	}
+
+	// A vault arg doesn't point the ritual at another vault
+	if _, err := vm.Ledger.Deposit("vault:other", 100, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v("drone_001", `"vault": "vault:other"`); err != nil {
+		t.Fatal(err)
+	}
+	if vm.Ledger.Balance("vault:other") != 100 || vm.Ledger.Balance(vault) != 380 {
+		t.Errorf("balances %v", vm.Ledger.Balances())
+	}
+
+	// Nor does another program's ritual of the same name share it
+	res, err := proved(t, vm, "drone_001", vaultSource("v", `"vault": {"balance": 100}, "slash": {"amount": 40, "reason": "late"}`, "", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other := eventOf(res, EventVaultSettled).(VaultSettled); other.Vault == vault || other.Opened != 100 || vm.Ledger.Balance(vault) != 380 {
+		t.Errorf("second program's vault %+v; balances %v", other, vm.Ledger.Balances())
+	}
+
+	// System accounts never fund or pay from a vault, even when witnesses
+	// sign for them
+	for _, device := range []string{"equity", vault, SlashAccount, "shrine"} {
+		_, err := proved(t, vm, device, vaultSource("w", `"vault": {"balance": 500}`, "", ""))
+		if err == nil || !strings.Contains(err.Error(), "is a system account") {
+			t.Errorf("%s funded a vault: %v", device, err)
+		}
+	}
+	if vm.Ledger.Balance("equity") != -2100 || vm.Ledger.Balance(vault) != 380 {
+		t.Errorf("balances %v", vm.Ledger.Balances())
+	}
+}
+
+func TestVaultBalanceMustBeWhole(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	for _, balance := range []string{"2.5", "-1", "1e30", "9007199254740993"} {
+		_, err := run(t, newTestVM(t, &now), `{"name": "v", "orisa": "sango_vault", "attributes": {"vault": {"balance": `+balance+`}}}`)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || !strings.Contains(err.Error(), "vault balance must be a whole, non-negative amount") {
+			t.Errorf("balance %s: %v", balance, err)
+		}
+	}
+	if units, err := (&VaultAttr{Balance: 1 << 52}).Units(); err != nil || units != 1<<52 {
+		t.Errorf("2^52: %d, %v", units, err)
+	}
+}
+
+// holdRitual holds amount in account, or with op "release" releases hold 1
+const holdRitual = `{
+	"name": "%s", "orisa": "sango_vault",
+	"ase": {"proof": "telemetry", "witnesses": 1},
+	"attributes": {%s},
+	"args": {"op": "%s", "amount": %d, "account": "%s"},
+	"statements": [{"type": "if", "data": {
+		"cond": "op == \"hold\"",
+		"then": [{"type": "assign", "data": {"variable": "held", "expr": "vault_hold(amount, account)"}}],
+		"else": [{"type": "call", "data": {"function": "vault_release", "args": [1]}}]
+	}}]
+}`
+
+func TestVaultHoldsBelongToTheirRitual(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	if _, err := vm.Ledger.Deposit("drone_001", 100, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.Ledger.Deposit("alice", 100, ""); err != nil {
+		t.Fatal(err)
+	}
+	// Ritual a's vault opens with 100 from drone_001's first 100
+	if _, err := proved(t, vm, "drone_001", fmt.Sprintf(holdRitual, "a", `"vault": {"balance": 100}`, "hold", 30, "")); err != nil {
+		t.Fatal(err)
+	}
+	vaultA := vaultPrefix + vm.Rituals["a"].ID()
+	if _, err := vm.Ledger.Deposit("drone_001", 100, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		ritual, attrs, op, account string
+		amount                     int64
+		err                        string
+	}{
+		{"a", `"vault": {"balance": 100}`, "hold", "drone_001", 20, ""},
+		{"a", `"vault": {"balance": 100}`, "hold", "alice", 20, "cannot hold funds in alice"},
+		{"b", "", "hold", vaultA, 20, "cannot hold funds in " + vaultA},
+		{"b", "", "release", "", 0, "hold 1 belongs to a@"},
+		// Same name, different program
+		{"a", "", "release", "", 0, "hold 1 belongs to a@"},
+		{"a", `"vault": {"balance": 100}`, "release", "", 0, ""},
+		{"a", `"vault": {"balance": 100}`, "release", "", 0, "no hold 1"},
+	} {
+		_, err := proved(t, vm, "drone_001", fmt.Sprintf(holdRitual, tc.ritual, tc.attrs, tc.op, tc.amount, tc.account))
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%s (%s): %s %d %s: got %v, want %q", tc.ritual, tc.attrs, tc.op, tc.amount, tc.account, err, tc.err)
+		}
+	}
+	if holds := vm.Ledger.Holds(); len(holds) != 1 || holds[0].Account != "drone_001" || holds[0].Ritual != vm.Rituals["a"].ID() || vm.Ledger.Available(vaultA) != 100 {
+		t.Errorf("holds %+v", holds)
+	}
+}
+
+func TestSangoVaultRollsBackOnFailure(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	vm := newTestVM(t, &now)
+	if _, err := vm.Ledger.Deposit("drone_001", 1000, ""); err != nil {
+		t.Fatal(err)
+	}
+	before := vm.Ledger.Balances()
+
+	// The vault opens, is slashed and split, and a hold is placed before
+	// the release of a hold that doesn't exist fails the ritual
+	res, err := proved(t, vm, "drone_001", vaultSource("v",
+		`"vault": {"balance": 600}, "slash": {"amount": 50, "reason": "late"}, "shrineSplit": {"distribution": "50/25/15/10"}`,
+		`"amount": 369`,
+		`{"type": "call", "data": {"function": "vault_hold", "args": [100]}},
+		 {"type": "call", "data": {"function": "vault_release", "args": [99]}}`))
+	if err == nil || !strings.Contains(err.Error(), "no hold 99") {
+		t.Fatalf("got %v", err)
+	}
+	settled := eventOf(res, EventVaultSettled).(VaultSettled)
+	var split int64
+	for _, s := range settled.Split {
+		split += s.Amount
+	}
+	if settled.Opened != 600 || settled.Slashed != 50 || split != 369 || eventOf(res, EventFundsHeld) == nil {
+		t.Errorf("settled %+v", settled)
+	}
+
+	if rolled, ok := eventOf(res, EventLedgerRolledBack).(LedgerRolledBack); !ok || rolled.Entries != 2 {
+		t.Errorf("rolled back %+v", rolled)
+	}
+	after := vm.Ledger.Balances()
+	if len(after) != len(before) || after["drone_001"] != 1000 || len(vm.Ledger.Holds()) != 0 || len(vm.Ledger.Journal()) != 1 {
+		t.Errorf("balances %v, holds %v", after, vm.Ledger.Holds())
+	}
+}
@@ -0,0 +1,54 @@
+// OSOVM Witness Key Files
+// Lets a witness device keep its Ed25519 keypair between runs
+
+package witness
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// keyFile is the on-disk format. Only the 32-byte seed is stored.
+type keyFile struct {
+	DeviceID   string `json:"device_id"`
+	DeviceType string `json:"device_type"`
+	Network    string `json:"network"`
+	Seed       string `json:"seed"`
+}
+
+// SaveKey writes the node's identity and private key seed to path (mode 0600)
+func (n *Node) SaveKey(path string) error {
+	if n.privateKey == nil {
+		return fmt.Errorf("node %s has no private key", n.DeviceID)
+	}
+	data, err := json.MarshalIndent(keyFile{
+		DeviceID:   n.DeviceID,
+		DeviceType: n.DeviceType,
+		Network:    n.Network,
+		Seed:       hex.EncodeToString(n.privateKey.Seed()),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// LoadNode restores a node from a key file written by SaveKey
+func LoadNode(path string) (*Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read witness key: %w", err)
+	}
+	var kf keyFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("failed to parse witness key %s: %w", path, err)
+	}
+	seed, err := hex.DecodeString(kf.Seed)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("witness key %s: invalid seed", path)
+	}
+	return NewNode(kf.DeviceID, kf.DeviceType, kf.Network, ed25519.NewKeyFromSeed(seed)), nil
+}
@@ -4,10 +4,14 @@
 package witness
 
 import (
-	"crypto/sha256"
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strconv"
 	"time"
+
+	"github.com/ase-lang/osovm/internal/tagged"
 )
 
 // Node represents a witness node in the mesh network
@@ -15,8 +19,10 @@ type Node struct {
 	DeviceID   string `json:"device_id"`
 	DeviceType string `json:"device_type"` // phone, drone, av, robot, sensor
 	Network    string `json:"network"`     // lora, ble, wifi, mesh
-	PublicKey  string `json:"public_key"`
+	PublicKey  string `json:"public_key"`  // hex Ed25519 public key
 	Online     bool   `json:"online"`
+
+	privateKey ed25519.PrivateKey
 }
 
 // WitnessSignature is proof that a node witnessed an action
@@ -27,46 +33,118 @@ type WitnessSignature struct {
 	Network   string `json:"network"`
 }
 
-// CreateNode initializes a new witness node
-func CreateNode(deviceID, deviceType, network string) *Node {
-	// Generate mock public key (production: real Ed25519 keypair)
-	pubKey := generateMockPublicKey(deviceID)
+// Attestation is the statement a witness signs: it saw Device perform the
+// action behind Receipt, for Ritual, at Timestamp, over Network
+type Attestation struct {
+	Receipt   string `json:"receipt"`
+	Device    string `json:"device"` // the proving device
+	Ritual    string `json:"ritual"`
+	Timestamp int64  `json:"timestamp"`
+	Network   string `json:"network"`
+}
 
+// attestationDomain tags the messages witness keys sign, so a witness
+// signature can't be passed off as a signature over anything else
+const attestationDomain = "osovm/witness-attestation/v1"
+
+// Message returns the canonical bytes that are signed: the domain tag and
+// each field, every one prefixed with its big-endian uint32 length
+func (a Attestation) Message() []byte {
+	return tagged.Message(attestationDomain,
+		a.Receipt,
+		a.Device,
+		a.Ritual,
+		strconv.FormatInt(a.Timestamp, 10),
+		a.Network,
+	)
+}
+
+// CreateNode initializes a new witness node with a fresh Ed25519 keypair
+func CreateNode(deviceID, deviceType, network string) (*Node, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate key for %s: %w", deviceID, err)
+	}
+	return NewNode(deviceID, deviceType, network, priv), nil
+}
+
+// NewNode initializes a witness node from an existing private key
+func NewNode(deviceID, deviceType, network string, priv ed25519.PrivateKey) *Node {
 	return &Node{
 		DeviceID:   deviceID,
 		DeviceType: deviceType,
 		Network:    network,
-		PublicKey:  pubKey,
+		PublicKey:  hex.EncodeToString(priv.Public().(ed25519.PublicKey)),
 		Online:     true,
+		privateKey: priv,
+	}
+}
+
+// Sign signs the attestation's canonical message and returns the hex signature
+func (n *Node) Sign(att Attestation) (string, error) {
+	if n.privateKey == nil {
+		return "", fmt.Errorf("node %s has no private key", n.DeviceID)
 	}
+	return hex.EncodeToString(ed25519.Sign(n.privateKey, att.Message())), nil
 }
 
-// WitnessAction signs a proof hash
-func (n *Node) WitnessAction(proofHash string) (*WitnessSignature, error) {
+// WitnessAction attests that device produced a proof receipt for a ritual
+func (n *Node) WitnessAction(proofReceipt, device, ritual string) (*WitnessSignature, error) {
 	if !n.Online {
 		return nil, fmt.Errorf("node %s is offline", n.DeviceID)
 	}
 
-	// Production: Ed25519 signature over LoRa/BLE
-	// Phase 2: SHA256(deviceID + proofHash)
-	signature := computeSignature(n.DeviceID, proofHash)
-
-	fmt.Printf("👁️  Witness %s (%s) signed via %s\n", n.DeviceID, n.DeviceType, n.Network)
+	att := Attestation{
+		Receipt:   proofReceipt,
+		Device:    device,
+		Ritual:    ritual,
+		Timestamp: time.Now().Unix(),
+		Network:   n.Network,
+	}
+	signature, err := n.Sign(att)
+	if err != nil {
+		return nil, err
+	}
 
 	return &WitnessSignature{
 		DeviceID:  n.DeviceID,
 		Signature: signature,
-		Timestamp: time.Now().Unix(),
+		Timestamp: att.Timestamp,
 		Network:   n.Network,
 	}, nil
 }
 
-// BroadcastProof sends proof to witness mesh
-func BroadcastProof(proofHash string, requiredWitnesses int, network string) ([]*WitnessSignature, error) {
-	fmt.Printf("📡 Broadcasting proof to mesh network (%s)...\n", network)
+// Verify checks a hex Ed25519 signature over att against a public key
+func Verify(pub ed25519.PublicKey, att Attestation, signature string) error {
+	if len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key length %d", len(pub))
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("malformed signature")
+	}
+	if !ed25519.Verify(pub, att.Message(), sig) {
+		return fmt.Errorf("signature does not match attestation")
+	}
+	return nil
+}
+
+// ParsePublicKey decodes a hex Ed25519 public key
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(s)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key %q", s)
+	}
+	return ed25519.PublicKey(key), nil
+}
 
+// BroadcastProof sends device's proof to witness mesh
+func BroadcastProof(proofReceipt, device, ritual string, requiredWitnesses int, network string) ([]*WitnessSignature, error) {
 	// Auto-discover nearby witness nodes
-	nodes := discoverWitnessNodes(network, requiredWitnesses)
+	nodes, err := discoverWitnessNodes(network, requiredWitnesses)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(nodes) < requiredWitnesses {
 		return nil, fmt.Errorf("insufficient witness nodes: found %d, need %d", len(nodes), requiredWitnesses)
@@ -76,20 +154,19 @@ func BroadcastProof(proofHash string, requiredWitnesses int, network string) ([]
 	signatures := make([]*WitnessSignature, 0, requiredWitnesses)
 
 	for i := 0; i < requiredWitnesses; i++ {
-		sig, err := nodes[i].WitnessAction(proofHash)
+		sig, err := nodes[i].WitnessAction(proofReceipt, device, ritual)
 		if err != nil {
 			return nil, fmt.Errorf("witness %s failed: %w", nodes[i].DeviceID, err)
 		}
 		signatures = append(signatures, sig)
 	}
 
-	fmt.Printf("✅ Collected %d/%d witness signatures\n", len(signatures), requiredWitnesses)
 	return signatures, nil
 }
 
 // ========== Helper Functions ==========
 
-func discoverWitnessNodes(network string, count int) []*Node {
+func discoverWitnessNodes(network string, count int) ([]*Node, error) {
 	// Production: actual LoRa/BLE discovery
 	// Phase 2: create mock nodes
 	nodes := make([]*Node, count)
@@ -98,25 +175,18 @@ func discoverWitnessNodes(network string, count int) []*Node {
 
 	for i := 0; i < count; i++ {
 		deviceType := deviceTypes[i%len(deviceTypes)]
-		nodes[i] = CreateNode(
+		node, err := CreateNode(
 			fmt.Sprintf("witness_%s_%d", network, i+1),
 			deviceType,
 			network,
 		)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = node
 	}
 
-	return nodes
-}
-
-func computeSignature(deviceID, proofHash string) string {
-	data := deviceID + proofHash
-	hash := sha256.Sum256([]byte(data))
-	return hex.EncodeToString(hash[:])
-}
-
-func generateMockPublicKey(deviceID string) string {
-	hash := sha256.Sum256([]byte(deviceID + "_pubkey"))
-	return hex.EncodeToString(hash[:])
+	return nodes, nil
 }
 
 // ========== Network Types ==========
@@ -0,0 +1,118 @@
+package witness
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// rfc8032Node is a witness holding the key of RFC 8032 section 7.1, test 1
+func rfc8032Node(t *testing.T) *Node {
+	t.Helper()
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	n := NewNode("witness_001", "phone", NetworkLoRa, ed25519.NewKeyFromSeed(seed))
+	if n.PublicKey != "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a" {
+		t.Fatalf("public key %s", n.PublicKey)
+	}
+	return n
+}
+
+var testAttestation = Attestation{
+	Receipt:   strings.Repeat("ab", 32),
+	Device:    "drone_001",
+	Ritual:    "drone_delivery",
+	Timestamp: 1700000000,
+	Network:   NetworkLoRa,
+}
+
+func TestAttestationMessage(t *testing.T) {
+	a := testAttestation
+	want := "0000001c" + hex.EncodeToString([]byte("osovm/witness-attestation/v1")) +
+		"00000040" + hex.EncodeToString([]byte(a.Receipt)) +
+		"00000009" + hex.EncodeToString([]byte("drone_001")) +
+		"0000000e" + hex.EncodeToString([]byte("drone_delivery")) +
+		"0000000a" + hex.EncodeToString([]byte("1700000000")) +
+		"00000004" + hex.EncodeToString([]byte("lora"))
+	if got := hex.EncodeToString(a.Message()); got != want {
+		t.Errorf("message\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	n := rfc8032Node(t)
+	pub, err := ParsePublicKey(n.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := n.Sign(testAttestation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(pub, testAttestation, sig); err != nil {
+		t.Fatalf("own signature: %v", err)
+	}
+
+	// Every field is covered by the signature
+	for _, tc := range []struct {
+		field  string
+		mutate func(a *Attestation)
+	}{
+		{"receipt", func(a *Attestation) { a.Receipt = strings.Repeat("cd", 32) }},
+		{"device", func(a *Attestation) { a.Device = "drone_002" }},
+		{"ritual", func(a *Attestation) { a.Ritual = "drone_return" }},
+		{"timestamp", func(a *Attestation) { a.Timestamp++ }},
+		{"network", func(a *Attestation) { a.Network = NetworkBLE }},
+		// Shifting bytes between fields is a different message too
+		{"boundary", func(a *Attestation) { a.Device, a.Ritual = "drone_001d", "rone_delivery" }},
+	} {
+		a := testAttestation
+		tc.mutate(&a)
+		if err := Verify(pub, a, sig); err == nil || !strings.Contains(err.Error(), "does not match") {
+			t.Errorf("tampered %s: got %v", tc.field, err)
+		}
+	}
+
+	other, err := CreateNode("witness_002", "drone", NetworkLoRa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _ := ParsePublicKey(other.PublicKey)
+	if err := Verify(otherPub, testAttestation, sig); err == nil {
+		t.Error("verified under the wrong key")
+	}
+	for _, bad := range []string{"", "zz", sig[:126], sig + "00"} {
+		if err := Verify(pub, testAttestation, bad); err == nil || !strings.Contains(err.Error(), "malformed") {
+			t.Errorf("signature %q: got %v", bad, err)
+		}
+	}
+	if err := Verify(pub[:31], testAttestation, sig); err == nil {
+		t.Error("verified under a short key")
+	}
+	if _, err := (&Node{DeviceID: "bare"}).Sign(testAttestation); err == nil {
+		t.Error("signed without a private key")
+	}
+}
+
+func TestWitnessActionSignsForTheProvingDevice(t *testing.T) {
+	n := rfc8032Node(t)
+	pub, _ := ParsePublicKey(n.PublicKey)
+	ws, err := n.WitnessAction(testAttestation.Receipt, "drone_001", "drone_delivery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	att := Attestation{Receipt: testAttestation.Receipt, Device: "drone_001", Ritual: "drone_delivery", Timestamp: ws.Timestamp, Network: ws.Network}
+	if err := Verify(pub, att, ws.Signature); err != nil {
+		t.Errorf("witness action: %v", err)
+	}
+	// The same signature says nothing about another device's proof
+	att.Device = "equity"
+	if err := Verify(pub, att, ws.Signature); err == nil {
+		t.Error("signature reused for another device")
+	}
+
+	n.Online = false
+	if _, err := n.WitnessAction(testAttestation.Receipt, "drone_001", "drone_delivery"); err == nil {
+		t.Error("offline node witnessed")
+	}
+}
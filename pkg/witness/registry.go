@@ -0,0 +1,256 @@
+// OSOVM Witness Registry
+// Persistent record of which witness keys the VM trusts, and since when
+
+package witness
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ase-lang/osovm/internal/atomicfile"
+)
+
+// Status of an enrolled witness
+type Status string
+
+const (
+	StatusActive  Status = "active"
+	StatusRevoked Status = "revoked"
+)
+
+// KeyRecord - One public key a witness has held. ValidUntil is zero for
+// the current key and the rotation time for keys rotated out.
+type KeyRecord struct {
+	PublicKey  string `json:"public_key"`
+	ValidFrom  int64  `json:"valid_from"`
+	ValidUntil int64  `json:"valid_until,omitempty"`
+}
+
+// Enrollment - A registered witness device
+type Enrollment struct {
+	DeviceID   string      `json:"device_id"`
+	DeviceType string      `json:"device_type"`
+	Network    string      `json:"network"`
+	PublicKey  string      `json:"public_key"` // current key
+	EnrolledAt int64       `json:"enrolled_at"`
+	Status     Status      `json:"status"`
+	RevokedAt  int64       `json:"revoked_at,omitempty"`
+	Reason     string      `json:"reason,omitempty"`
+	Keys       []KeyRecord `json:"keys"` // every key held, oldest first
+}
+
+// Registry - Enrolled witnesses by device ID. Enroll, Rotate and Revoke
+// only change the registry in memory; the CLI calls Save after each
+// command so OpenRegistry sees the change next run.
+type Registry struct {
+	Path      string
+	Witnesses map[string]*Enrollment
+	Now       func() time.Time
+}
+
+// registryFile is the on-disk format
+type registryFile struct {
+	Version   int           `json:"version"`
+	Witnesses []*Enrollment `json:"witnesses"`
+}
+
+const registryVersion = 1
+
+// NewRegistry creates an empty in-memory registry
+func NewRegistry() *Registry {
+	return &Registry{
+		Witnesses: make(map[string]*Enrollment),
+		Now:       time.Now,
+	}
+}
+
+// OpenRegistry loads the registry at path. A missing file is an empty registry.
+func OpenRegistry(path string) (*Registry, error) {
+	r := NewRegistry()
+	r.Path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read witness registry: %w", err)
+	}
+
+	var file registryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse witness registry %s: %w", path, err)
+	}
+	if file.Version != registryVersion {
+		return nil, fmt.Errorf("witness registry %s: unsupported version %d", path, file.Version)
+	}
+	for _, e := range file.Witnesses {
+		if _, dup := r.Witnesses[e.DeviceID]; dup {
+			return nil, fmt.Errorf("witness registry %s: duplicate device %s", path, e.DeviceID)
+		}
+		r.Witnesses[e.DeviceID] = e
+	}
+	return r, nil
+}
+
+// Save writes the registry back to its path atomically
+func (r *Registry) Save() error {
+	if r.Path == "" {
+		return fmt.Errorf("witness registry has no path")
+	}
+
+	data, err := json.MarshalIndent(registryFile{Version: registryVersion, Witnesses: r.List()}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := atomicfile.Write(r.Path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to save witness registry: %w", err)
+	}
+	return nil
+}
+
+// List returns every enrollment sorted by device ID
+func (r *Registry) List() []*Enrollment {
+	list := make([]*Enrollment, 0, len(r.Witnesses))
+	for _, e := range r.Witnesses {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].DeviceID < list[j].DeviceID })
+	return list
+}
+
+// Lookup returns the enrollment for deviceID
+func (r *Registry) Lookup(deviceID string) (*Enrollment, bool) {
+	e, ok := r.Witnesses[deviceID]
+	return e, ok
+}
+
+// ========== Enrollment Lifecycle ==========
+
+// Enroll registers a witness device with its current public key
+func (r *Registry) Enroll(deviceID, deviceType, network, publicKey string) (*Enrollment, error) {
+	if deviceID == "" {
+		return nil, fmt.Errorf("device ID cannot be empty")
+	}
+	if !ValidateNetwork(network) {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+	if _, err := ParsePublicKey(publicKey); err != nil {
+		return nil, err
+	}
+	if _, exists := r.Witnesses[deviceID]; exists {
+		return nil, fmt.Errorf("witness %s is already enrolled", deviceID)
+	}
+
+	now := r.Now().Unix()
+	e := &Enrollment{
+		DeviceID:   deviceID,
+		DeviceType: deviceType,
+		Network:    network,
+		PublicKey:  publicKey,
+		EnrolledAt: now,
+		Status:     StatusActive,
+		Keys:       []KeyRecord{{PublicKey: publicKey, ValidFrom: now}},
+	}
+	r.Witnesses[deviceID] = e
+	return e, nil
+}
+
+// EnrollNode registers a node under its own device type, network and key
+func (r *Registry) EnrollNode(n *Node) (*Enrollment, error) {
+	return r.Enroll(n.DeviceID, n.DeviceType, n.Network, n.PublicKey)
+}
+
+// Rotate replaces a witness's key. From then on only the new key is
+// trusted, whatever timestamp an attestation claims.
+func (r *Registry) Rotate(deviceID, publicKey string) (*Enrollment, error) {
+	e, err := r.active(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParsePublicKey(publicKey); err != nil {
+		return nil, err
+	}
+	for _, k := range e.Keys {
+		if k.PublicKey == publicKey {
+			return nil, fmt.Errorf("witness %s has already used this key", deviceID)
+		}
+	}
+
+	now := r.Now().Unix()
+	e.Keys[len(e.Keys)-1].ValidUntil = now
+	e.Keys = append(e.Keys, KeyRecord{PublicKey: publicKey, ValidFrom: now})
+	e.PublicKey = publicKey
+	return e, nil
+}
+
+// Revoke stops trusting a witness entirely
+func (r *Registry) Revoke(deviceID, reason string) (*Enrollment, error) {
+	e, err := r.active(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	e.Status = StatusRevoked
+	e.RevokedAt = r.Now().Unix()
+	e.Reason = reason
+	return e, nil
+}
+
+func (r *Registry) active(deviceID string) (*Enrollment, error) {
+	e, ok := r.Witnesses[deviceID]
+	if !ok {
+		return nil, fmt.Errorf("witness %s is not enrolled", deviceID)
+	}
+	if e.Status == StatusRevoked {
+		return nil, fmt.Errorf("witness %s was revoked", deviceID)
+	}
+	return e, nil
+}
+
+// ========== Verification ==========
+
+// VerifyAttestation checks that deviceID is enrolled and not revoked, and
+// that signature verifies under the key it holds now. The attestation's
+// timestamp is the witness's own claim, so it never selects the key: a
+// key rotated out can't sign attestations dated before its rotation.
+func (r *Registry) VerifyAttestation(deviceID string, att Attestation, signature string) error {
+	e, ok := r.Witnesses[deviceID]
+	if !ok {
+		return fmt.Errorf("unknown witness")
+	}
+	if e.Status == StatusRevoked {
+		return fmt.Errorf("witness revoked at %s", formatUnix(e.RevokedAt))
+	}
+	if att.Timestamp < e.EnrolledAt {
+		return fmt.Errorf("attestation at %s predates enrollment", formatUnix(att.Timestamp))
+	}
+
+	pub, err := ParsePublicKey(e.PublicKey)
+	if err != nil {
+		return err
+	}
+	if err := Verify(pub, att, signature); err != nil {
+		// Name the rotated key if that is what signed it
+		for _, k := range e.Keys {
+			if k.ValidUntil != 0 && verifiesWith(k.PublicKey, att, signature) {
+				return fmt.Errorf("signed with a key rotated out at %s", formatUnix(k.ValidUntil))
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+func verifiesWith(publicKey string, att Attestation, signature string) bool {
+	pub, err := ParsePublicKey(publicKey)
+	return err == nil && Verify(pub, att, signature) == nil
+}
+
+func formatUnix(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,222 @@
+package witness
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRegistry returns an in-memory registry whose clock is *now
+func testRegistry(now *time.Time) *Registry {
+	r := NewRegistry()
+	r.Now = func() time.Time { return *now }
+	return r
+}
+
+// attestBy signs an attestation dated ts with n's key
+func attestBy(t *testing.T, n *Node, ts int64) (Attestation, string) {
+	t.Helper()
+	att := testAttestation
+	att.Timestamp = ts
+	sig, err := n.Sign(att)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return att, sig
+}
+
+func TestEnroll(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	r := testRegistry(&now)
+	n := rfc8032Node(t)
+
+	e, err := r.EnrollNode(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Status != StatusActive || e.EnrolledAt != now.Unix() || len(e.Keys) != 1 || e.Keys[0] != (KeyRecord{PublicKey: n.PublicKey, ValidFrom: now.Unix()}) {
+		t.Errorf("enrollment %+v", e)
+	}
+	att, sig := attestBy(t, n, now.Unix())
+	if err := r.VerifyAttestation(n.DeviceID, att, sig); err != nil {
+		t.Errorf("enrolled witness: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name                     string
+		id, network, key, errMsg string
+	}{
+		{"duplicate", n.DeviceID, NetworkLoRa, n.PublicKey, "already enrolled"},
+		{"duplicate with a new key", n.DeviceID, NetworkBLE, strings.Repeat("11", 32), "already enrolled"},
+		{"no ID", "", NetworkLoRa, n.PublicKey, "device ID cannot be empty"},
+		{"network", "w2", "carrier-pigeon", n.PublicKey, "unsupported network"},
+		{"key", "w2", NetworkLoRa, "abcd", "invalid Ed25519 public key"},
+	} {
+		if _, err := r.Enroll(tc.id, "phone", tc.network, tc.key); err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.errMsg)
+		}
+	}
+	// A failed re-enrollment leaves the original untouched
+	if e, _ := r.Lookup(n.DeviceID); e.PublicKey != n.PublicKey || e.Network != NetworkLoRa || len(r.List()) != 1 {
+		t.Errorf("enrollment changed: %+v", e)
+	}
+
+	if err := r.VerifyAttestation("witness_404", att, sig); err == nil || !strings.Contains(err.Error(), "unknown witness") {
+		t.Errorf("unknown witness: %v", err)
+	}
+	early, sig := attestBy(t, n, now.Unix()-1)
+	if err := r.VerifyAttestation(n.DeviceID, early, sig); err == nil || !strings.Contains(err.Error(), "predates enrollment") {
+		t.Errorf("attestation before enrollment: %v", err)
+	}
+}
+
+func TestRotateRetiresTheOldKey(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	r := testRegistry(&now)
+	old := rfc8032Node(t)
+	if _, err := r.EnrollNode(old); err != nil {
+		t.Fatal(err)
+	}
+	// Signed while the old key was current, verified after the rotation
+	signedEarly, earlySig := attestBy(t, old, now.Unix()+10)
+
+	now = now.Add(time.Hour)
+	replacement, err := CreateNode(old.DeviceID, old.DeviceType, old.Network)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := r.Rotate(old.DeviceID, replacement.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Keys) != 2 || e.Keys[0].ValidUntil != now.Unix() || e.PublicKey != replacement.PublicKey {
+		t.Errorf("keys %+v", e.Keys)
+	}
+
+	att, sig := attestBy(t, replacement, now.Unix())
+	if err := r.VerifyAttestation(old.DeviceID, att, sig); err != nil {
+		t.Errorf("new key: %v", err)
+	}
+	// The old key is refused however the attestation is dated, backdated
+	// into its validity window included
+	backdated, backdatedSig := attestBy(t, old, now.Unix()-1800)
+	for name, tc := range map[string]struct {
+		att Attestation
+		sig string
+	}{
+		"current":   {att, mustSign(t, old, att)},
+		"backdated": {backdated, backdatedSig},
+		"early":     {signedEarly, earlySig},
+	} {
+		if err := r.VerifyAttestation(old.DeviceID, tc.att, tc.sig); err == nil || !strings.Contains(err.Error(), "rotated out") {
+			t.Errorf("%s attestation by the old key: %v", name, err)
+		}
+	}
+
+	if _, err := r.Rotate(old.DeviceID, old.PublicKey); err == nil || !strings.Contains(err.Error(), "already used this key") {
+		t.Errorf("rotated back to the old key: %v", err)
+	}
+	if _, err := r.Rotate("witness_404", old.PublicKey); err == nil || !strings.Contains(err.Error(), "not enrolled") {
+		t.Errorf("rotated an unknown witness: %v", err)
+	}
+}
+
+func mustSign(t *testing.T, n *Node, att Attestation) string {
+	t.Helper()
+	sig, err := n.Sign(att)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func TestRevoke(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	r := testRegistry(&now)
+	n := rfc8032Node(t)
+	if _, err := r.EnrollNode(n); err != nil {
+		t.Fatal(err)
+	}
+	att, sig := attestBy(t, n, now.Unix())
+
+	now = now.Add(time.Minute)
+	e, err := r.Revoke(n.DeviceID, "device lost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Status != StatusRevoked || e.RevokedAt != now.Unix() || e.Reason != "device lost" {
+		t.Errorf("revocation %+v", e)
+	}
+	if err := r.VerifyAttestation(n.DeviceID, att, sig); err == nil || !strings.Contains(err.Error(), "witness revoked at 2023-11-14T22:14:20Z") {
+		t.Errorf("revoked witness: %v", err)
+	}
+	for name, err := range map[string]error{
+		"revoke": func() error { _, err := r.Revoke(n.DeviceID, ""); return err }(),
+		"rotate": func() error { _, err := r.Rotate(n.DeviceID, strings.Repeat("11", 32)); return err }(),
+		"enroll": func() error { _, err := r.EnrollNode(n); return err }(),
+	} {
+		if err == nil {
+			t.Errorf("%s succeeded on a revoked witness", name)
+		}
+	}
+}
+
+func TestRegistrySaveAndOpen(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	path := filepath.Join(t.TempDir(), "witnesses.json")
+	r, err := OpenRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Now = func() time.Time { return now }
+	if err := NewRegistry().Save(); err == nil {
+		t.Error("saved an in-memory registry")
+	}
+
+	a, b := rfc8032Node(t), mustCreate(t, "witness_002")
+	for _, n := range []*Node{b, a} {
+		if _, err := r.EnrollNode(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := r.Revoke(b.DeviceID, "lost"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := reopened.List()
+	if len(list) != 2 || list[0].DeviceID != a.DeviceID || list[1].Status != StatusRevoked || list[1].Reason != "lost" {
+		t.Errorf("reopened %+v", list)
+	}
+
+	for name, data := range map[string]string{
+		"version":   `{"version": 2, "witnesses": []}`,
+		"duplicate": `{"version": 1, "witnesses": [{"device_id": "w"}, {"device_id": "w"}]}`,
+		"json":      `{"version": 1,`,
+	} {
+		bad := filepath.Join(t.TempDir(), name+".json")
+		if err := os.WriteFile(bad, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := OpenRegistry(bad); err == nil {
+			t.Errorf("opened a registry with a bad %s", name)
+		}
+	}
+}
+
+func mustCreate(t *testing.T, id string) *Node {
+	t.Helper()
+	n, err := CreateNode(id, "drone", NetworkBLE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}